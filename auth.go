@@ -0,0 +1,359 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Authentication errors.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+
+	// ErrInternalTokenRequired is returned when authentication is enabled
+	// without a token for requests between nodes.
+	ErrInternalTokenRequired = errors.New("internal token required when authentication is enabled")
+)
+
+// AllIndexes is the permission key which applies to every index.
+const AllIndexes = "*"
+
+// Permission represents a level of access to an index.
+// Each permission includes all lower permissions.
+type Permission int
+
+// Permission levels.
+const (
+	PermissionNone Permission = iota
+	PermissionRead
+	PermissionWrite
+	PermissionAdmin
+)
+
+// String returns the string representation of the permission.
+func (p Permission) String() string {
+	switch p {
+	case PermissionRead:
+		return "read"
+	case PermissionWrite:
+		return "write"
+	case PermissionAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// ParsePermission parses s into a permission.
+func ParsePermission(s string) (Permission, error) {
+	switch s {
+	case "read":
+		return PermissionRead, nil
+	case "write":
+		return PermissionWrite, nil
+	case "admin":
+		return PermissionAdmin, nil
+	default:
+		return PermissionNone, fmt.Errorf("invalid permission: %q", s)
+	}
+}
+
+// ParsePermissions parses a map of index names to permission strings.
+func ParsePermissions(m map[string]string) (map[string]Permission, error) {
+	other := make(map[string]Permission, len(m))
+	for index, s := range m {
+		p, err := ParsePermission(s)
+		if err != nil {
+			return nil, err
+		}
+		other[index] = p
+	}
+	return other, nil
+}
+
+// Principal represents an authenticated identity and its permissions.
+type Principal struct {
+	Name string
+
+	// Permissions by index name. AllIndexes applies to every index.
+	Permissions map[string]Permission
+}
+
+// Allowed returns true if the principal has at least perm on index.
+// An empty index requires the permission on AllIndexes.
+func (p *Principal) Allowed(index string, perm Permission) bool {
+	if perm == PermissionNone {
+		return true
+	} else if p.Permissions[AllIndexes] >= perm {
+		return true
+	}
+	return index != "" && p.Permissions[index] >= perm
+}
+
+// Authenticator represents an object that authenticates HTTP requests.
+type Authenticator interface {
+	// Returns the principal making the request.
+	// Returns ErrUnauthorized if the request has no valid credentials.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// bearerToken returns the token from the request's Authorization header.
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	if s := r.Header.Get("Authorization"); strings.HasPrefix(s, prefix) {
+		return strings.TrimSpace(s[len(prefix):])
+	}
+	return ""
+}
+
+// MultiAuthenticator authenticates a request with the first authenticator
+// that accepts it.
+type MultiAuthenticator []Authenticator
+
+// Authenticate implements Authenticator.
+func (a MultiAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	for _, auth := range a {
+		p, err := auth.Authenticate(r)
+		if err == ErrUnauthorized {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	return nil, ErrUnauthorized
+}
+
+// TokenAuthenticator authenticates requests using static API tokens passed
+// as a bearer token in the Authorization header.
+type TokenAuthenticator struct {
+	// Principals keyed by the SHA-256 hash of their token so that lookups
+	// do not leak the token through timing.
+	principals map[[sha256.Size]byte]*Principal
+}
+
+// NewTokenAuthenticator returns a new instance of TokenAuthenticator.
+func NewTokenAuthenticator() *TokenAuthenticator {
+	return &TokenAuthenticator{
+		principals: make(map[[sha256.Size]byte]*Principal),
+	}
+}
+
+// AddToken grants the permissions of p to requests using token.
+func (a *TokenAuthenticator) AddToken(token string, p *Principal) error {
+	if token == "" {
+		return errors.New("token required")
+	}
+	a.principals[sha256.Sum256([]byte(token))] = p
+	return nil
+}
+
+// Authenticate implements Authenticator.
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, ErrUnauthorized
+	}
+
+	p := a.principals[sha256.Sum256([]byte(token))]
+	if p == nil {
+		return nil, ErrUnauthorized
+	}
+	return p, nil
+}
+
+// JWTAuthenticator authenticates requests using signed JSON Web Tokens
+// passed as a bearer token in the Authorization header.
+//
+// Tokens signed with HS256 are verified against a shared secret and tokens
+// signed with RS256 are verified against an RSA public key. The "sub" claim
+// is used as the principal name and the "permissions" claim maps index names
+// to "read", "write" or "admin". The "exp" and "nbf" claims are enforced.
+type JWTAuthenticator struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+
+	// Returns the current time. Used for testing.
+	Now func() time.Time
+}
+
+// NewJWTAuthenticator returns a JWTAuthenticator using the key at path.
+// A PEM encoded public key enables RS256; any other content is used as an
+// HS256 shared secret.
+func NewJWTAuthenticator(path string) (*JWTAuthenticator, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &JWTAuthenticator{Now: time.Now}
+	if block, _ := pem.Decode(buf); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("jwt key must be an rsa public key")
+		}
+		a.publicKey = rsaKey
+		return a, nil
+	}
+
+	a.secret = bytes.TrimSpace(buf)
+	if len(a.secret) == 0 {
+		return nil, errors.New("jwt secret required")
+	}
+	return a, nil
+}
+
+// jwtHeader represents the header section of a JSON Web Token.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+// jwtClaims represents the claims section of a JSON Web Token.
+type jwtClaims struct {
+	Subject     string            `json:"sub"`
+	ExpiresAt   int64             `json:"exp"`
+	NotBefore   int64             `json:"nbf"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// Authenticate implements Authenticator.
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if strings.Count(token, ".") != 2 {
+		return nil, ErrUnauthorized
+	}
+	segs := strings.Split(token, ".")
+
+	// Decode header and verify signature before trusting any claims.
+	var header jwtHeader
+	if err := decodeJWTSegment(segs[0], &header); err != nil {
+		return nil, ErrUnauthorized
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(segs[2])
+	if err != nil {
+		return nil, ErrUnauthorized
+	} else if !a.verify(header.Alg, segs[0]+"."+segs[1], sig) {
+		return nil, ErrUnauthorized
+	}
+
+	// Decode & validate claims.
+	var claims jwtClaims
+	if err := decodeJWTSegment(segs[1], &claims); err != nil {
+		return nil, ErrUnauthorized
+	}
+	now := a.Now().Unix()
+	if claims.ExpiresAt != 0 && now >= claims.ExpiresAt {
+		return nil, ErrUnauthorized
+	} else if claims.NotBefore != 0 && now < claims.NotBefore {
+		return nil, ErrUnauthorized
+	}
+
+	perms, err := ParsePermissions(claims.Permissions)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return &Principal{Name: claims.Subject, Permissions: perms}, nil
+}
+
+// verify returns true if sig is a valid signature of data using alg.
+func (a *JWTAuthenticator) verify(alg, data string, sig []byte) bool {
+	switch alg {
+	case "HS256":
+		if a.secret == nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, a.secret)
+		mac.Write([]byte(data))
+		return hmac.Equal(sig, mac.Sum(nil))
+	case "RS256":
+		if a.publicKey == nil {
+			return false
+		}
+		sum := sha256.Sum256([]byte(data))
+		return rsa.VerifyPKCS1v15(a.publicKey, crypto.SHA256, sum[:], sig) == nil
+	default:
+		return false
+	}
+}
+
+// decodeJWTSegment decodes a base64url encoded JSON segment into v.
+func decodeJWTSegment(seg string, v interface{}) error {
+	buf, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
+
+// NewAuthHTTPClient returns a client which sends token as a bearer token
+// on every request made through client.
+func NewAuthHTTPClient(client *http.Client, token string) *http.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	other := *client
+	other.Transport = &tokenTransport{token: token, transport: transport}
+	return &other
+}
+
+// tokenTransport adds a bearer token to requests.
+type tokenTransport struct {
+	token     string
+	transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	other := new(http.Request)
+	*other = *req
+	other.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		other.Header[k] = v
+	}
+	other.Header.Set("Authorization", "Bearer "+t.token)
+	return t.transport.RoundTrip(other)
+}
+
+// principalKey is the context key for the authenticated principal.
+type principalKey struct{}
+
+// PrincipalFromContext returns the authenticated principal for a request context.
+// Returns nil if authentication is disabled.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,259 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilosa/pilosa"
+	"github.com/pilosa/pilosa/pql"
+)
+
+// Ensure principals are only allowed their granted permissions.
+func TestPrincipal_Allowed(t *testing.T) {
+	p := &pilosa.Principal{Permissions: map[string]pilosa.Permission{"i0": pilosa.PermissionWrite}}
+	if !p.Allowed("i0", pilosa.PermissionRead) {
+		t.Fatal("expected read on i0")
+	} else if !p.Allowed("i0", pilosa.PermissionWrite) {
+		t.Fatal("expected write on i0")
+	} else if p.Allowed("i0", pilosa.PermissionAdmin) {
+		t.Fatal("unexpected admin on i0")
+	} else if p.Allowed("i1", pilosa.PermissionRead) {
+		t.Fatal("unexpected read on i1")
+	} else if p.Allowed("", pilosa.PermissionRead) {
+		t.Fatal("unexpected read on all indexes")
+	}
+
+	admin := &pilosa.Principal{Permissions: map[string]pilosa.Permission{pilosa.AllIndexes: pilosa.PermissionAdmin}}
+	if !admin.Allowed("i1", pilosa.PermissionAdmin) {
+		t.Fatal("expected admin on i1")
+	} else if !admin.Allowed("", pilosa.PermissionAdmin) {
+		t.Fatal("expected admin on all indexes")
+	}
+}
+
+// Ensure static tokens can be authenticated.
+func TestTokenAuthenticator(t *testing.T) {
+	a := pilosa.NewTokenAuthenticator()
+	if err := a.AddToken("secret", &pilosa.Principal{Name: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err := a.Authenticate(MustNewAuthRequest("secret")); err != nil {
+		t.Fatal(err)
+	} else if p.Name != "alice" {
+		t.Fatalf("unexpected principal: %s", p.Name)
+	}
+
+	if _, err := a.Authenticate(MustNewAuthRequest("invalid")); err != pilosa.ErrUnauthorized {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := a.Authenticate(MustNewAuthRequest("")); err != pilosa.ErrUnauthorized {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure HS256 signed JWTs can be authenticated.
+func TestJWTAuthenticator_HS256(t *testing.T) {
+	path := MustWriteTempFile("jwtsecret")
+	defer os.Remove(path)
+
+	a, err := pilosa.NewJWTAuthenticator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000, 0)
+	a.Now = func() time.Time { return now }
+
+	t.Run("OK", func(t *testing.T) {
+		token := MustSignJWT("jwtsecret", map[string]interface{}{
+			"sub":         "bob",
+			"exp":         2000,
+			"permissions": map[string]string{"i0": "read"},
+		})
+		if p, err := a.Authenticate(MustNewAuthRequest(token)); err != nil {
+			t.Fatal(err)
+		} else if p.Name != "bob" {
+			t.Fatalf("unexpected principal: %s", p.Name)
+		} else if !p.Allowed("i0", pilosa.PermissionRead) || p.Allowed("i0", pilosa.PermissionWrite) {
+			t.Fatalf("unexpected permissions: %v", p.Permissions)
+		}
+	})
+
+	t.Run("ErrExpired", func(t *testing.T) {
+		token := MustSignJWT("jwtsecret", map[string]interface{}{"sub": "bob", "exp": 500})
+		if _, err := a.Authenticate(MustNewAuthRequest(token)); err != pilosa.ErrUnauthorized {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrNotBefore", func(t *testing.T) {
+		token := MustSignJWT("jwtsecret", map[string]interface{}{"sub": "bob", "nbf": 1500})
+		if _, err := a.Authenticate(MustNewAuthRequest(token)); err != pilosa.ErrUnauthorized {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrSignature", func(t *testing.T) {
+		token := MustSignJWT("othersecret", map[string]interface{}{"sub": "bob"})
+		if _, err := a.Authenticate(MustNewAuthRequest(token)); err != pilosa.ErrUnauthorized {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// Ensure a server with authentication cannot open without an internal token.
+func TestServer_Open_ErrInternalTokenRequired(t *testing.T) {
+	s := pilosa.NewServer()
+	s.Host = "localhost:0"
+	s.Authenticator = pilosa.NewTokenAuthenticator()
+	if err := s.Open(); err != pilosa.ErrInternalTokenRequired {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure the handler rejects unauthenticated and unauthorized requests.
+func TestHandler_Auth(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateIndexIfNotExists("i0", pilosa.IndexOptions{})
+	hldr.MustCreateIndexIfNotExists("i1", pilosa.IndexOptions{})
+
+	a := pilosa.NewTokenAuthenticator()
+	a.AddToken("reader", &pilosa.Principal{Name: "reader", Permissions: map[string]pilosa.Permission{"i0": pilosa.PermissionRead}})
+
+	h := NewHandler()
+	h.Holder = hldr.Holder
+	h.Handler.Authenticator = a
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(1)}, nil
+	}
+
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i0/query", strings.NewReader("Count(Bitmap(id=1))")))
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		} else if w.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Fatalf("unexpected authenticate header: %q", w.Header().Get("WWW-Authenticate"))
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := MustNewHTTPRequest("POST", "/index/i0/query", strings.NewReader("Count(Bitmap(id=1))"))
+		r.Header.Set("Authorization", "Bearer reader")
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+	})
+
+	t.Run("ErrForbidden_Index", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := MustNewHTTPRequest("POST", "/index/i1/query", strings.NewReader("Count(Bitmap(id=1))"))
+		r.Header.Set("Authorization", "Bearer reader")
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+	})
+
+	t.Run("ErrForbidden_Write", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := MustNewHTTPRequest("POST", "/index/i0/query", strings.NewReader("SetBit(frame=f, rowID=1, columnID=1)"))
+		r.Header.Set("Authorization", "Bearer reader")
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+	})
+
+	t.Run("ErrForbidden_Admin", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := MustNewHTTPRequest("DELETE", "/index/i0", nil)
+		r.Header.Set("Authorization", "Bearer reader")
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+	})
+
+	t.Run("Schema", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := MustNewHTTPRequest("GET", "/schema", nil)
+		r.Header.Set("Authorization", "Bearer reader")
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		} else if body := w.Body.String(); body != `{"indexes":[{"name":"i0","sliceWidth":1048576,"frames":null}]}`+"\n" {
+			t.Fatalf("unexpected body: %s", body)
+		}
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewHTTPRequest("GET", "/version", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: %d", w.Code)
+		}
+	})
+}
+
+// MustNewAuthRequest returns a request with token as its bearer token.
+func MustNewAuthRequest(token string) *http.Request {
+	r := MustNewHTTPRequest("GET", "/schema", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+// MustSignJWT returns an HS256 signed JWT containing claims.
+func MustSignJWT(secret string, claims map[string]interface{}) string {
+	buf, err := json.Marshal(claims)
+	if err != nil {
+		panic(err)
+	}
+	data := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString(buf)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return data + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// MustWriteTempFile writes s to a temporary file and returns its path.
+func MustWriteTempFile(s string) string {
+	f, err := ioutil.TempFile("", "pilosa-")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(s); err != nil {
+		panic(err)
+	}
+	return f.Name()
+}
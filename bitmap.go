@@ -41,6 +41,36 @@ func NewBitmap(bits ...uint64) *Bitmap {
 	return bm
 }
 
+// newRangeBitmap returns a read-only bitmap referencing data, which holds
+// the column IDs of a fragment row starting at offset and spanning width
+// columns. Rows wider than SliceWidth are split into one segment per
+// SliceWidth columns so that bitmaps from any index can be combined.
+func newRangeBitmap(data *roaring.Bitmap, offset, width uint64) *Bitmap {
+	bm := &Bitmap{}
+	if width <= SliceWidth {
+		bm.segments = []BitmapSegment{{
+			data:  *data,
+			slice: offset / SliceWidth,
+		}}
+		bm.InvalidateCount()
+		return bm
+	}
+
+	// Only visit segments containing set bits.
+	itr := data.Iterator()
+	for v, eof := itr.Next(); !eof; v, eof = itr.Next() {
+		slice := v / SliceWidth
+		start := slice * SliceWidth
+		bm.segments = append(bm.segments, BitmapSegment{
+			data:  *data.OffsetRange(start, start, start+SliceWidth),
+			slice: slice,
+		})
+		itr.Seek(start + SliceWidth)
+	}
+	bm.InvalidateCount()
+	return bm
+}
+
 // Merge merges data from other into b.
 func (b *Bitmap) Merge(other *Bitmap) {
 	var segments []BitmapSegment
@@ -216,6 +246,15 @@ func (b *Bitmap) Count() uint64 {
 	return n
 }
 
+// Size returns the approximate number of bytes used by the bitmap's data.
+func (b *Bitmap) Size() int {
+	var n int
+	for i := range b.segments {
+		n += b.segments[i].data.Size()
+	}
+	return n
+}
+
 // MarshalJSON returns a JSON-encoded byte slice of b.
 func (b *Bitmap) MarshalJSON() ([]byte, error) {
 	var o struct {
@@ -278,7 +317,7 @@ func Union(bitmaps []*Bitmap) *Bitmap {
 
 // BitmapSegment holds a subset of a bitmap.
 // This could point to a mmapped roaring bitmap or an in-memory bitmap. The
-// width of the segment will always match the slice width.
+// width of the segment will always match the default slice width.
 type BitmapSegment struct {
 	// Slice this segment belongs to
 	slice uint64
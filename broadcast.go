@@ -15,8 +15,16 @@
 package pilosa
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pilosa/pilosa/internal"
@@ -31,29 +39,230 @@ type NodeSet interface {
 	Open() error
 }
 
-// StaticNodeSet represents a basic NodeSet for testing.
+// Default health check settings for StaticNodeSet.
+const (
+	DefaultHealthCheckInterval = 10 * time.Second
+	DefaultHealthCheckTimeout  = 2 * time.Second
+
+	// DefaultHealthCheckFailureN is the number of consecutive failed probes
+	// before a node is marked as DOWN.
+	DefaultHealthCheckFailureN = 3
+
+	// DefaultHealthCheckSuccessN is the number of consecutive successful
+	// probes before a DOWN node is marked as UP again.
+	DefaultHealthCheckSuccessN = 2
+)
+
+// StaticNodeSet represents a NodeSet with a fixed list of nodes.
+//
+// If HealthCheckInterval is set then each node is periodically probed over
+// HTTP and only nodes considered UP are returned from Nodes(). A node must
+// fail FailureN consecutive probes to be marked DOWN and must pass SuccessN
+// consecutive probes to be marked UP again.
 type StaticNodeSet struct {
-	nodes []*Node
+	mu     sync.RWMutex
+	nodes  []*Node
+	health map[string]*nodeHealth
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+
+	// Health check configuration.
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+	FailureN            int
+	SuccessN            int
+
+	// Scheme & client used for health check requests.
+	Scheme     string
+	HTTPClient *http.Client
+
+	LogOutput io.Writer
+}
+
+// nodeHealth tracks the probe history of a single node.
+type nodeHealth struct {
+	state     string
+	failureN  int // consecutive failed probes
+	successN  int // consecutive successful probes
+	lastError error
 }
 
 // NewStaticNodeSet creates a statically defined NodeSet.
 func NewStaticNodeSet() *StaticNodeSet {
-	return &StaticNodeSet{}
+	return &StaticNodeSet{
+		health:  make(map[string]*nodeHealth),
+		closing: make(chan struct{}),
+
+		HealthCheckTimeout: DefaultHealthCheckTimeout,
+		FailureN:           DefaultHealthCheckFailureN,
+		SuccessN:           DefaultHealthCheckSuccessN,
+
+		Scheme:     "http",
+		HTTPClient: http.DefaultClient,
+		LogOutput:  ioutil.Discard,
+	}
 }
 
-// Nodes implements the NodeSet interface and returns a list of nodes in the cluster.
+// Nodes implements the NodeSet interface and returns a list of nodes in the cluster
+// which are not known to be down.
 func (s *StaticNodeSet) Nodes() []*Node {
-	return s.nodes
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a := make([]*Node, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		if h := s.health[n.Host]; h != nil && h.state == NodeStateDown {
+			continue
+		}
+		a = append(a, n)
+	}
+	return a
+}
+
+// NodeState returns the health check state of the node with the given host.
+// Nodes which have not been probed are considered UP.
+func (s *StaticNodeSet) NodeState(host string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if h := s.health[host]; h != nil {
+		return h.state
+	}
+	return NodeStateUp
 }
 
-// Open implements the NodeSet interface to start network activity, but for a static NodeSet it does nothing.
+// Open implements the NodeSet interface and starts the health check monitor
+// if a health check interval is set.
 func (s *StaticNodeSet) Open() error {
+	s.mu.RLock()
+	n := len(s.nodes)
+	s.mu.RUnlock()
+
+	// Ignore if there are no other nodes to check.
+	if s.HealthCheckInterval <= 0 || n <= 1 {
+		return nil
+	}
+
+	s.wg.Add(1)
+	go func() { defer s.wg.Done(); s.monitorHealth() }()
+	return nil
+}
+
+// Close stops the health check monitor.
+func (s *StaticNodeSet) Close() error {
+	select {
+	case <-s.closing:
+	default:
+		close(s.closing)
+	}
+	s.wg.Wait()
 	return nil
 }
 
 // Join sets the NodeSet nodes to the slice of Nodes passed in.
 func (s *StaticNodeSet) Join(nodes []*Node) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.nodes = nodes
+	s.health = make(map[string]*nodeHealth, len(nodes))
+	for _, n := range nodes {
+		s.health[n.Host] = &nodeHealth{state: NodeStateUp}
+	}
+	return nil
+}
+
+func (s *StaticNodeSet) logger() *log.Logger { return log.New(s.LogOutput, "", log.LstdFlags) }
+
+// monitorHealth periodically probes every node until the node set is closed.
+func (s *StaticNodeSet) monitorHealth() {
+	ticker := time.NewTicker(s.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-ticker.C:
+		}
+
+		s.CheckHealth()
+	}
+}
+
+// CheckHealth probes all nodes in parallel and updates their state.
+func (s *StaticNodeSet) CheckHealth() {
+	s.mu.RLock()
+	nodes := Nodes(s.nodes).Clone()
+	s.mu.RUnlock()
+
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n *Node) {
+			defer wg.Done()
+			errs[i] = s.probe(n)
+		}(i, n)
+	}
+	wg.Wait()
+
+	for i, n := range nodes {
+		s.recordProbe(n.Host, errs[i])
+	}
+}
+
+// recordProbe updates the health of host based on the result of a probe.
+func (s *StaticNodeSet) recordProbe(host string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.health[host]
+	if h == nil {
+		return
+	}
+	h.lastError = err
+
+	if err != nil {
+		h.failureN, h.successN = h.failureN+1, 0
+		if h.state == NodeStateUp && h.failureN >= s.FailureN {
+			h.state = NodeStateDown
+			s.logger().Printf("node down: host=%s, err=%s", host, err)
+		}
+		return
+	}
+
+	h.failureN, h.successN = 0, h.successN+1
+	if h.state == NodeStateDown && h.successN >= s.SuccessN {
+		h.state = NodeStateUp
+		s.logger().Printf("node up: host=%s", host)
+	}
+}
+
+// probe sends a health check request to a node.
+func (s *StaticNodeSet) probe(node *Node) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.HealthCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", (&url.URL{
+		Scheme: s.Scheme,
+		Host:   node.Host,
+		Path:   "/health",
+	}).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid status: code=%d", resp.StatusCode)
+	}
 	return nil
 }
 
@@ -113,6 +322,8 @@ const (
 	MessageTypeDeleteIndex = 3
 	MessageTypeCreateFrame = 4
 	MessageTypeDeleteFrame = 5
+	MessageTypeDeleteView  = 6
+	MessageTypeRollUpViews = 7
 )
 
 // MarshalMessage encodes the protobuf message into a byte slice.
@@ -129,6 +340,10 @@ func MarshalMessage(m proto.Message) ([]byte, error) {
 		typ = MessageTypeCreateFrame
 	case *internal.DeleteFrameMessage:
 		typ = MessageTypeDeleteFrame
+	case *internal.DeleteViewMessage:
+		typ = MessageTypeDeleteView
+	case *internal.RollUpViewsMessage:
+		typ = MessageTypeRollUpViews
 	default:
 		return nil, fmt.Errorf("message type not implemented for marshalling: %s", reflect.TypeOf(obj))
 	}
@@ -155,6 +370,10 @@ func UnmarshalMessage(buf []byte) (proto.Message, error) {
 		m = &internal.CreateFrameMessage{}
 	case MessageTypeDeleteFrame:
 		m = &internal.DeleteFrameMessage{}
+	case MessageTypeDeleteView:
+		m = &internal.DeleteViewMessage{}
+	case MessageTypeRollUpViews:
+		m = &internal.RollUpViewsMessage{}
 	default:
 		return nil, fmt.Errorf("invalid message type: %d", typ)
 	}
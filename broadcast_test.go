@@ -15,7 +15,10 @@
 package pilosa_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/gogo/protobuf/proto"
@@ -73,6 +76,50 @@ func TestBroadcast_BroadcastReceiver(t *testing.T) {
 	}
 }
 
+// Ensure StaticNodeSet marks nodes DOWN and UP with hysteresis.
+func TestStaticNodeSet_CheckHealth(t *testing.T) {
+	var healthy = true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	ns := pilosa.NewStaticNodeSet()
+	ns.FailureN, ns.SuccessN = 2, 2
+	if err := ns.Join([]*pilosa.Node{{Host: host}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A single failure should not mark the node as down.
+	healthy = false
+	ns.CheckHealth()
+	if state := ns.NodeState(host); state != pilosa.NodeStateUp {
+		t.Fatalf("unexpected state after one failure: %s", state)
+	}
+	ns.CheckHealth()
+	if state := ns.NodeState(host); state != pilosa.NodeStateDown {
+		t.Fatalf("unexpected state after two failures: %s", state)
+	} else if n := len(ns.Nodes()); n != 0 {
+		t.Fatalf("unexpected node count: %d", n)
+	}
+
+	// The node should require two successes to be marked up again.
+	healthy = true
+	ns.CheckHealth()
+	if state := ns.NodeState(host); state != pilosa.NodeStateDown {
+		t.Fatalf("unexpected state after one success: %s", state)
+	}
+	ns.CheckHealth()
+	if state := ns.NodeState(host); state != pilosa.NodeStateUp {
+		t.Fatalf("unexpected state after two successes: %s", state)
+	} else if n := len(ns.Nodes()); n != 1 {
+		t.Fatalf("unexpected node count: %d", n)
+	}
+}
+
 type SimpleBroadcastReceiver struct {
 	broadcastHandler pilosa.BroadcastHandler
 }
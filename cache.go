@@ -16,10 +16,12 @@ package pilosa
 
 import (
 	"bytes"
+	"container/list"
 	"fmt"
 	"io"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/groupcache/lru"
@@ -53,6 +55,7 @@ type Cache interface {
 
 // LRUCache represents a least recently used Cache implemenation.
 type LRUCache struct {
+	mu     sync.Mutex
 	cache  *lru.Cache
 	counts map[uint64]uint64
 }
@@ -74,19 +77,27 @@ func (c *LRUCache) BulkAdd(id, n uint64) {
 
 // Add adds a count to the cache.
 func (c *LRUCache) Add(id, n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.cache.Add(id, n)
 	c.counts[id] = n
 }
 
 // Get returns a count for a given id.
 func (c *LRUCache) Get(id uint64) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	n, _ := c.cache.Get(id)
 	nn, _ := n.(uint64)
 	return nn
 }
 
 // Len returns the number of items in the cache.
-func (c *LRUCache) Len() int { return c.cache.Len() }
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Len()
+}
 
 // Invalidate is a no-op.
 func (c *LRUCache) Invalidate() {}
@@ -96,6 +107,8 @@ func (c *LRUCache) Recalculate() {}
 
 // IDs returns a list of all IDs in the cache.
 func (c *LRUCache) IDs() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	a := make([]uint64, 0, len(c.counts))
 	for id := range c.counts {
 		a = append(a, id)
@@ -106,6 +119,8 @@ func (c *LRUCache) IDs() []uint64 {
 
 // Top returns all counts in the cache.
 func (c *LRUCache) Top() []BitmapPair {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	a := make([]BitmapPair, 0, len(c.counts))
 	for id, n := range c.counts {
 		a = append(a, BitmapPair{
@@ -259,7 +274,11 @@ func (c *RankCache) recalculate() {
 }
 
 // Top returns an ordered list of pairs.
-func (c *RankCache) Top() []BitmapPair { return c.rankings }
+func (c *RankCache) Top() []BitmapPair {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rankings
+}
 
 // WriteTo writes the cache to w.
 func (c *RankCache) WriteTo(w io.Writer) (n int64, err error) {
@@ -438,6 +457,10 @@ func (p uint64Slice) merge(other []uint64) []uint64 {
 type BitmapCache interface {
 	Fetch(id uint64) (*Bitmap, bool)
 	Add(id uint64, b *Bitmap)
+	Remove(id uint64)
+
+	// Removes all bitmaps from the cache.
+	Clear()
 }
 
 // SimpleCache implements BitmapCache
@@ -459,3 +482,234 @@ func (s *SimpleCache) Fetch(id uint64) (*Bitmap, bool) {
 func (s *SimpleCache) Add(id uint64, b *Bitmap) {
 	s.cache[id] = b
 }
+
+// Remove removes the bitmap at the id from the cache.
+func (s *SimpleCache) Remove(id uint64) {
+	delete(s.cache, id)
+}
+
+// Clear removes all bitmaps from the cache.
+func (s *SimpleCache) Clear() {
+	s.cache = make(map[uint64]*Bitmap)
+}
+
+// Ensure SimpleCache implements BitmapCache.
+var _ BitmapCache = &SimpleCache{}
+
+// LRUBitmapCache implements BitmapCache with a byte budget. The least recently
+// used bitmaps are evicted once the cache exceeds MaxBytes. Once the budget
+// shared with other caches is exhausted, the least recently used bitmaps across
+// all of those caches are evicted.
+type LRUBitmapCache struct {
+	mu    sync.Mutex // used when the cache has no budget
+	lru   *list.List // most recently used at the front
+	elems map[uint64]*list.Element
+	size  int64
+
+	// Maximum number of bytes held by the cache. Zero is unlimited.
+	MaxBytes int64
+
+	budget *bitmapCacheBudget // shared by all caches in a holder, may be nil
+	stats  StatsClient
+}
+
+// bitmapCacheEntry is a cached bitmap and its size at the time it was added.
+type bitmapCacheEntry struct {
+	id   uint64
+	bm   *Bitmap
+	size int64
+
+	cache  *LRUBitmapCache
+	elem   *list.Element // element in the cache's list
+	shared *list.Element // element in the budget's list
+}
+
+// NewLRUBitmapCache returns a new instance of LRUBitmapCache.
+func NewLRUBitmapCache(maxBytes int64) *LRUBitmapCache {
+	return &LRUBitmapCache{
+		lru:      list.New(),
+		elems:    make(map[uint64]*list.Element),
+		MaxBytes: maxBytes,
+		stats:    NopStatsClient,
+	}
+}
+
+// lock locks the cache. Caches sharing a limited budget share its lock so that
+// any of them can evict bitmaps from the others.
+func (c *LRUBitmapCache) lock() {
+	if c.budget.limited() {
+		c.budget.mu.Lock()
+		return
+	}
+	c.mu.Lock()
+}
+
+// unlock unlocks the cache.
+func (c *LRUBitmapCache) unlock() {
+	if c.budget.limited() {
+		c.budget.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+}
+
+// Fetch retrieves the bitmap at the id in the cache.
+func (c *LRUBitmapCache) Fetch(id uint64) (*Bitmap, bool) {
+	c.lock()
+	defer c.unlock()
+
+	elem := c.elems[id]
+	if elem == nil {
+		c.stats.Count("rowCache.miss", 1)
+		return nil, false
+	}
+	c.stats.Count("rowCache.hit", 1)
+
+	entry := elem.Value.(*bitmapCacheEntry)
+	c.lru.MoveToFront(elem)
+	c.budget.touch(entry)
+	return entry.bm, true
+}
+
+// Add adds the bitmap to the cache, keyed on the id. Bitmaps larger than
+// the cache are not added.
+func (c *LRUBitmapCache) Add(id uint64, b *Bitmap) {
+	c.lock()
+	defer c.unlock()
+
+	if elem := c.elems[id]; elem != nil {
+		c.remove(elem.Value.(*bitmapCacheEntry))
+	}
+
+	size := int64(b.Size())
+	if c.MaxBytes > 0 && size > c.MaxBytes {
+		return
+	}
+	entry := &bitmapCacheEntry{id: id, bm: b, size: size, cache: c}
+	entry.elem = c.lru.PushFront(entry)
+	c.elems[id] = entry.elem
+	c.size += size
+	c.budget.push(entry)
+
+	// Evict this cache's least recently used bitmaps until it is within MaxBytes.
+	for c.MaxBytes > 0 && c.size > c.MaxBytes {
+		c.remove(c.lru.Back().Value.(*bitmapCacheEntry))
+		c.stats.Count("rowCache.evict", 1)
+	}
+
+	// Evict the least recently used bitmaps of all caches sharing the budget
+	// until the budget is no longer exceeded.
+	for c.budget.exceeded() {
+		entry := c.budget.lru.Back().Value.(*bitmapCacheEntry)
+		entry.cache.remove(entry)
+		entry.cache.stats.Count("rowCache.evict", 1)
+	}
+}
+
+// Remove removes the bitmap at the id from the cache.
+func (c *LRUBitmapCache) Remove(id uint64) {
+	c.lock()
+	defer c.unlock()
+	if elem := c.elems[id]; elem != nil {
+		c.remove(elem.Value.(*bitmapCacheEntry))
+	}
+}
+
+// Clear removes all bitmaps from the cache.
+func (c *LRUBitmapCache) Clear() {
+	c.lock()
+	defer c.unlock()
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		c.budget.pop(elem.Value.(*bitmapCacheEntry))
+	}
+	c.lru.Init()
+	c.elems = make(map[uint64]*list.Element)
+	c.size = 0
+}
+
+// Len returns the number of bitmaps in the cache.
+func (c *LRUBitmapCache) Len() int {
+	c.lock()
+	defer c.unlock()
+	return c.lru.Len()
+}
+
+// Size returns the number of bytes used by bitmaps in the cache.
+func (c *LRUBitmapCache) Size() int64 {
+	c.lock()
+	defer c.unlock()
+	return c.size
+}
+
+func (c *LRUBitmapCache) remove(entry *bitmapCacheEntry) {
+	c.lru.Remove(entry.elem)
+	delete(c.elems, entry.id)
+	c.size -= entry.size
+	c.budget.pop(entry)
+}
+
+// Ensure LRUBitmapCache implements BitmapCache.
+var _ BitmapCache = &LRUBitmapCache{}
+
+// bitmapCacheBudget tracks the bytes used by all row caches in a holder. It
+// keeps the bitmaps of all caches in a single list so the least recently used
+// bitmaps can be evicted regardless of the cache holding them.
+type bitmapCacheBudget struct {
+	mu       sync.Mutex // held by caches sharing a limited budget
+	lru      *list.List // most recently used at the front, if limited
+	n        int64      // accessed atomically
+	maxBytes int64
+}
+
+// newBitmapCacheBudget returns a budget of maxBytes. Zero is unlimited.
+func newBitmapCacheBudget(maxBytes int64) *bitmapCacheBudget {
+	return &bitmapCacheBudget{lru: list.New(), maxBytes: maxBytes}
+}
+
+// push adds an entry as the most recently used. A nil budget is ignored.
+func (b *bitmapCacheBudget) push(entry *bitmapCacheEntry) {
+	if b == nil {
+		return
+	}
+	if b.limited() {
+		entry.shared = b.lru.PushFront(entry)
+	}
+	atomic.AddInt64(&b.n, entry.size)
+}
+
+// touch marks an entry as the most recently used. A nil budget is ignored.
+func (b *bitmapCacheBudget) touch(entry *bitmapCacheEntry) {
+	if !b.limited() {
+		return
+	}
+	b.lru.MoveToFront(entry.shared)
+}
+
+// pop removes an entry. A nil budget is ignored.
+func (b *bitmapCacheBudget) pop(entry *bitmapCacheEntry) {
+	if b == nil {
+		return
+	}
+	if b.limited() {
+		b.lru.Remove(entry.shared)
+	}
+	atomic.AddInt64(&b.n, -entry.size)
+}
+
+// limited returns true if the budget has a maximum number of bytes.
+func (b *bitmapCacheBudget) limited() bool {
+	return b != nil && b.maxBytes > 0
+}
+
+// exceeded returns true if more bytes are used than the budget allows.
+func (b *bitmapCacheBudget) exceeded() bool {
+	return b.limited() && atomic.LoadInt64(&b.n) > b.maxBytes
+}
+
+// Size returns the number of bytes used.
+func (b *bitmapCacheBudget) Size() int64 {
+	if b == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&b.n)
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa
+
+import (
+	"testing"
+)
+
+// Ensure caches sharing a budget evict the least recently used bitmaps across
+// all of the caches instead of the bitmap just added.
+func TestLRUBitmapCache_Budget(t *testing.T) {
+	size := int64(NewBitmap(1, 2, 3).Size())
+	budget := newBitmapCacheBudget(size * 3)
+
+	// Two fragments' row caches sharing the holder's budget.
+	a, b := NewLRUBitmapCache(0), NewLRUBitmapCache(0)
+	a.budget, b.budget = budget, budget
+
+	a.Add(1, NewBitmap(1, 2, 3))
+	a.Add(2, NewBitmap(4, 5, 6))
+	a.Add(3, NewBitmap(7, 8, 9))
+	if _, ok := a.Fetch(1); !ok {
+		t.Fatal("expected bitmap a/1")
+	}
+
+	// Adding to the second cache should evict the first cache's least
+	// recently used bitmap, then the next one.
+	b.Add(1, NewBitmap(1, 2, 3))
+	if _, ok := b.Fetch(1); !ok {
+		t.Fatal("expected bitmap b/1")
+	} else if _, ok := a.Fetch(2); ok {
+		t.Fatal("expected bitmap a/2 to be evicted")
+	}
+	b.Add(2, NewBitmap(4, 5, 6))
+	if _, ok := b.Fetch(2); !ok {
+		t.Fatal("expected bitmap b/2")
+	} else if _, ok := a.Fetch(3); ok {
+		t.Fatal("expected bitmap a/3 to be evicted")
+	} else if _, ok := a.Fetch(1); !ok {
+		t.Fatal("expected bitmap a/1")
+	}
+	if a.Len() != 1 || b.Len() != 2 {
+		t.Fatalf("unexpected len: %d/%d", a.Len(), b.Len())
+	} else if n := budget.Size(); n != size*3 {
+		t.Fatalf("unexpected budget size: %d", n)
+	}
+
+	// Clearing a cache releases its bytes from the budget.
+	b.Clear()
+	if n := budget.Size(); n != size {
+		t.Fatalf("unexpected budget size: %d", n)
+	} else if _, ok := a.Fetch(1); !ok {
+		t.Fatal("expected bitmap a/1")
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa_test
+
+import (
+	"testing"
+
+	"github.com/pilosa/pilosa"
+)
+
+// Ensure the bitmap cache evicts the least recently used bitmaps over its byte limit.
+func TestLRUBitmapCache_MaxBytes(t *testing.T) {
+	bm := pilosa.NewBitmap(1, 2, 3)
+	c := pilosa.NewLRUBitmapCache(int64(bm.Size() * 2))
+
+	c.Add(1, bm)
+	c.Add(2, pilosa.NewBitmap(4, 5, 6))
+	if _, ok := c.Fetch(1); !ok {
+		t.Fatal("expected bitmap 1")
+	}
+
+	// Adding a third bitmap should evict bitmap 2.
+	c.Add(3, pilosa.NewBitmap(7, 8, 9))
+	if c.Len() != 2 {
+		t.Fatalf("unexpected len: %d", c.Len())
+	} else if _, ok := c.Fetch(2); ok {
+		t.Fatal("expected bitmap 2 to be evicted")
+	} else if other, ok := c.Fetch(1); !ok || other != bm {
+		t.Fatal("expected bitmap 1")
+	}
+
+	// Bitmaps larger than the cache are not added.
+	c.Add(4, pilosa.NewBitmap(1, 2, 3, 4, 5, 6, 7, 8, 9, 10))
+	if _, ok := c.Fetch(4); ok {
+		t.Fatal("unexpected bitmap 4")
+	}
+
+	c.Clear()
+	if c.Len() != 0 || c.Size() != 0 {
+		t.Fatalf("unexpected len/size: %d/%d", c.Len(), c.Size())
+	}
+}
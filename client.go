@@ -29,6 +29,7 @@ import (
 	"net/url"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
@@ -39,19 +40,36 @@ import (
 type Client struct {
 	host string
 
+	// The URL scheme used for requests, either "http" or "https".
+	// Defaults to the scheme of the host passed to NewClient, or "http".
+	Scheme string
+
 	// The client to use for HTTP communication.
 	// Defaults to the http.DefaultClient.
 	HTTPClient *http.Client
 }
 
 // NewClient returns a new instance of Client to connect to host.
+// The host may be prefixed with an "http://" or "https://" scheme.
 func NewClient(host string) (*Client, error) {
 	if host == "" {
 		return nil, ErrHostRequired
 	}
 
+	scheme := "http"
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return nil, err
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid scheme: %s", u.Scheme)
+		}
+		scheme, host = u.Scheme, u.Host
+	}
+
 	return &Client{
 		host:       host,
+		Scheme:     scheme,
 		HTTPClient: http.DefaultClient,
 	}, nil
 }
@@ -73,7 +91,7 @@ func (c *Client) MaxInverseSliceByIndex(ctx context.Context) (map[string]uint64,
 func (c *Client) maxSliceByIndex(ctx context.Context, inverse bool) (map[string]uint64, error) {
 	// Execute request against the host.
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   "/slices/max",
 		RawQuery: (&url.Values{
@@ -108,7 +126,7 @@ func (c *Client) maxSliceByIndex(ctx context.Context, inverse bool) (map[string]
 func (c *Client) Schema(ctx context.Context) ([]*IndexInfo, error) {
 	// Execute request against the host.
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   "/schema",
 	}
@@ -146,7 +164,7 @@ func (c *Client) CreateIndex(ctx context.Context, index string, opt IndexOptions
 	}
 
 	// Create URL & HTTP request.
-	u := url.URL{Scheme: "http", Host: c.host, Path: fmt.Sprintf("/index/%s", index)}
+	u := url.URL{Scheme: c.Scheme, Host: c.host, Path: fmt.Sprintf("/index/%s", index)}
 	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(buf))
 	if err != nil {
 		return err
@@ -183,7 +201,7 @@ func (c *Client) CreateIndex(ctx context.Context, index string, opt IndexOptions
 func (c *Client) FragmentNodes(ctx context.Context, index string, slice uint64) ([]*Node, error) {
 	// Execute request against the host.
 	u := url.URL{
-		Scheme:   "http",
+		Scheme:   c.Scheme,
 		Host:     c.host,
 		Path:     "/fragment/nodes",
 		RawQuery: (url.Values{"index": {index}, "slice": {strconv.FormatUint(slice, 10)}}).Encode(),
@@ -231,7 +249,7 @@ func (c *Client) ExecuteQuery(ctx context.Context, index, query string, allowRed
 
 	// Create URL & HTTP request.
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   fmt.Sprintf("/index/%s/query", index),
 	}
@@ -271,7 +289,7 @@ func (c *Client) ExecuteQuery(ctx context.Context, index, query string, allowRed
 // ExecutePQL executes query string against index on the server.
 func (c *Client) ExecutePQL(ctx context.Context, index, query string) (interface{}, error) {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   "/query",
 		RawQuery: url.Values{
@@ -354,7 +372,7 @@ func MarshalImportPayload(index, frame string, slice uint64, bits []Bit) ([]byte
 // importNode sends a pre-marshaled import request to a node.
 func (c *Client) importNode(ctx context.Context, node *Node, buf []byte) error {
 	// Create URL & HTTP request.
-	u := url.URL{Scheme: "http", Host: node.Host, Path: "/import"}
+	u := url.URL{Scheme: c.Scheme, Host: node.Host, Path: "/import"}
 	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(buf))
 	if err != nil {
 		return err
@@ -422,7 +440,7 @@ func (c *Client) ExportCSV(ctx context.Context, index, frame string, slice uint6
 func (c *Client) exportNodeCSV(ctx context.Context, node *Node, index, frame string, slice uint64, w io.Writer) error {
 	// Create URL.
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   node.Host,
 		Path:   "/export",
 		RawQuery: url.Values{
@@ -555,7 +573,7 @@ func (c *Client) BackupSlice(ctx context.Context, index, frame, view string, sli
 
 func (c *Client) backupSliceNode(ctx context.Context, index, frame, view string, slice uint64, node *Node) (io.ReadCloser, error) {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   node.Host,
 		Path:   "/fragment/data",
 		RawQuery: url.Values{
@@ -640,7 +658,7 @@ func (c *Client) restoreSliceFrom(ctx context.Context, buf []byte, index, frame,
 	// Restore slice to each owner.
 	for _, node := range nodes {
 		u := url.URL{
-			Scheme: "http",
+			Scheme: c.Scheme,
 			Host:   node.Host,
 			Path:   "/fragment/data",
 			RawQuery: url.Values{
@@ -688,7 +706,7 @@ func (c *Client) CreateFrame(ctx context.Context, index, frame string, opt Frame
 	}
 
 	// Create URL & HTTP request.
-	u := url.URL{Scheme: "http", Host: c.host, Path: fmt.Sprintf("/index/%s/frame/%s", index, frame)}
+	u := url.URL{Scheme: c.Scheme, Host: c.host, Path: fmt.Sprintf("/index/%s/frame/%s", index, frame)}
 	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(buf))
 	if err != nil {
 		return err
@@ -724,7 +742,7 @@ func (c *Client) CreateFrame(ctx context.Context, index, frame string, opt Frame
 // RestoreFrame restores an entire frame from a host in another cluster.
 func (c *Client) RestoreFrame(ctx context.Context, host, index, frame string) error {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.Host(),
 		Path:   fmt.Sprintf("/index/%s/frame/%s/restore", index, frame),
 		RawQuery: url.Values{
@@ -758,7 +776,7 @@ func (c *Client) RestoreFrame(ctx context.Context, host, index, frame string) er
 func (c *Client) FrameViews(ctx context.Context, index, frame string) ([]string, error) {
 	// Create URL & HTTP request.
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   fmt.Sprintf("/index/%s/frame/%s/views", index, frame),
 	}
@@ -797,7 +815,7 @@ func (c *Client) FrameViews(ctx context.Context, index, frame string) ([]string,
 // Only returns blocks which contain data.
 func (c *Client) FragmentBlocks(ctx context.Context, index, frame, view string, slice uint64) ([]FragmentBlock, error) {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   "/fragment/blocks",
 		RawQuery: url.Values{
@@ -851,7 +869,7 @@ func (c *Client) BlockData(ctx context.Context, index, frame, view string, slice
 		return nil, nil, err
 	}
 
-	u := url.URL{Scheme: "http", Host: c.host, Path: "/fragment/block/data"}
+	u := url.URL{Scheme: c.Scheme, Host: c.host, Path: "/fragment/block/data"}
 	req, err := http.NewRequest("GET", u.String(), bytes.NewReader(buf))
 	if err != nil {
 		return nil, nil, err
@@ -888,7 +906,7 @@ func (c *Client) BlockData(ctx context.Context, index, frame, view string, slice
 // ColumnAttrDiff returns data from differing blocks on a remote host.
 func (c *Client) ColumnAttrDiff(ctx context.Context, index string, blks []AttrBlock) (map[uint64]map[string]interface{}, error) {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   fmt.Sprintf("/index/%s/attr/diff", index),
 	}
@@ -931,7 +949,7 @@ func (c *Client) ColumnAttrDiff(ctx context.Context, index string, blks []AttrBl
 // RowAttrDiff returns data from differing blocks on a remote host.
 func (c *Client) RowAttrDiff(ctx context.Context, index, frame string, blks []AttrBlock) (map[uint64]map[string]interface{}, error) {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: c.Scheme,
 		Host:   c.host,
 		Path:   fmt.Sprintf("/index/%s/frame/%s/attr/diff", index, frame),
 	}
@@ -1023,11 +1041,11 @@ func (p Bits) Timestamps() []int64 {
 	return other
 }
 
-// GroupBySlice returns a map of bits by slice.
-func (p Bits) GroupBySlice() map[uint64][]Bit {
+// GroupBySlice returns a map of bits by slice for an index with sliceWidth.
+func (p Bits) GroupBySlice(sliceWidth uint64) map[uint64][]Bit {
 	m := make(map[uint64][]Bit)
 	for _, bit := range p {
-		slice := bit.ColumnID / SliceWidth
+		slice := bit.ColumnID / sliceWidth
 		m[slice] = append(m[slice], bit)
 	}
 
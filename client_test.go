@@ -307,6 +307,25 @@ func TestClient_BackupRestore(t *testing.T) {
 	}
 }
 
+// Ensure client parses the scheme from its host.
+func TestNewClient_Scheme(t *testing.T) {
+	if c, err := pilosa.NewClient("localhost:10101"); err != nil {
+		t.Fatal(err)
+	} else if c.Scheme != "http" || c.Host() != "localhost:10101" {
+		t.Fatalf("unexpected client: scheme=%s, host=%s", c.Scheme, c.Host())
+	}
+
+	if c, err := pilosa.NewClient("https://localhost:10101"); err != nil {
+		t.Fatal(err)
+	} else if c.Scheme != "https" || c.Host() != "localhost:10101" {
+		t.Fatalf("unexpected client: scheme=%s, host=%s", c.Scheme, c.Host())
+	}
+
+	if _, err := pilosa.NewClient("ftp://localhost:10101"); err == nil || err.Error() != "invalid scheme: ftp" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure client can retrieve a list of all checksums for blocks in a fragment.
 func TestClient_FragmentBlocks(t *testing.T) {
 	hldr := MustOpenHolder()
@@ -17,6 +17,7 @@ package pilosa
 import (
 	"encoding/binary"
 	"hash/fnv"
+	"net/http"
 
 	"github.com/pilosa/pilosa/internal"
 )
@@ -130,6 +131,11 @@ type Cluster struct {
 
 	// The number of replicas a partition has.
 	ReplicaN int
+
+	// The URL scheme & HTTP client used for requests between nodes.
+	// Defaults to "http" and http.DefaultClient.
+	Scheme     string
+	HTTPClient *http.Client
 }
 
 // NewCluster returns a new instance of Cluster with defaults.
@@ -141,6 +147,33 @@ func NewCluster() *Cluster {
 	}
 }
 
+// NodeClient returns a client for sending requests to another node in the cluster.
+func (c *Cluster) NodeClient(host string) (*Client, error) {
+	client, err := NewClient(host)
+	if err != nil {
+		return nil, err
+	}
+	client.Scheme = c.scheme()
+	client.HTTPClient = c.httpClient()
+	return client, nil
+}
+
+// scheme returns the URL scheme used for requests between nodes.
+func (c *Cluster) scheme() string {
+	if c.Scheme == "" {
+		return "http"
+	}
+	return c.Scheme
+}
+
+// httpClient returns the HTTP client used for requests between nodes.
+func (c *Cluster) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
 // NodeSetHosts returns the list of host strings for NodeSet members.
 func (c *Cluster) NodeSetHosts() []string {
 	if c.NodeSet == nil {
@@ -168,6 +201,20 @@ func (c *Cluster) NodeStates() map[string]string {
 	return h
 }
 
+// NodeState returns the state of the node with host as reported by the NodeSet.
+// Nodes are considered UP unless the NodeSet tracks node health and reports otherwise.
+func (c *Cluster) NodeState(host string) string {
+	if ns, ok := c.NodeSet.(nodeStater); ok {
+		return ns.NodeState(host)
+	}
+	return NodeStateUp
+}
+
+// nodeStater is implemented by NodeSets that track the health of their nodes.
+type nodeStater interface {
+	NodeState(host string) string
+}
+
 // Status returns the internal ClusterStatus representation.
 func (c *Cluster) Status() *internal.ClusterStatus {
 	return &internal.ClusterStatus{
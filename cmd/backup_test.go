@@ -48,6 +48,24 @@ frame = "f1"
 				return v.Error()
 			},
 		},
+		{
+			args: []string{"backup", "--tls.ca-certificate", "/ca.crt", "--tls.skip-verify"},
+			env:  map[string]string{"PILOSA_TOKEN": "secret"},
+			cfgFileContent: `
+[tls]
+certificate = "/client.crt"
+key = "/client.key"
+`,
+			validation: func() error {
+				v := validator{}
+				v.Check(cmd.Backuper.TLSCertificatePath, "/client.crt")
+				v.Check(cmd.Backuper.TLSKeyPath, "/client.key")
+				v.Check(cmd.Backuper.TLSCACertificatePath, "/ca.crt")
+				v.Check(cmd.Backuper.TLSSkipVerify, true)
+				v.Check(cmd.Backuper.Token, "secret")
+				return v.Error()
+			},
+		},
 	}
 	executeDry(t, tests)
 }
@@ -43,6 +43,7 @@ Executes a benchmark for a given operation against the index.
 	}
 	flags := benchCmd.Flags()
 	flags.StringVarP(&Bencher.Host, "host", "", "localhost:10101", "host:port of Pilosa.")
+	addClientFlags(flags, &Bencher.ClientOptions)
 	flags.StringVarP(&Bencher.Index, "index", "i", "", "Pilosa index to benchmark.")
 	flags.StringVarP(&Bencher.Frame, "frame", "f", "", "Frame to benchmark.")
 	flags.StringVarP(&Bencher.Op, "operation", "o", "set-bit", "Operation to perform: choose from [set-bit]")
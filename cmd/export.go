@@ -51,6 +51,7 @@ The file does not contain any headers.
 	flags := exportCmd.Flags()
 
 	flags.StringVarP(&Exporter.Host, "host", "", "localhost:10101", "host:port of Pilosa.")
+	addClientFlags(flags, &Exporter.ClientOptions)
 	flags.StringVarP(&Exporter.Index, "index", "i", "", "Pilosa index to export into.")
 	flags.StringVarP(&Exporter.Frame, "frame", "f", "", "Frame to export into.")
 	flags.StringVarP(&Exporter.Path, "output-file", "o", "", "File to write export to - default stdout")
@@ -0,0 +1,60 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pilosa/pilosa/ctl"
+)
+
+var Migrator *ctl.MigrateCommand
+
+func NewMigrateCommand(stdin io.Reader, stdout, stderr io.Writer) *cobra.Command {
+	Migrator = ctl.NewMigrateCommand(os.Stdin, os.Stdout, os.Stderr)
+	migrateCmd := &cobra.Command{
+		Use:   "migrate <data-dir>",
+		Short: "Upgrade pilosa data files to the current format.",
+		Long: `
+Rewrites every fragment file in a data directory using the current file format
+version. Each file is verified with the same consistency check used by the
+check command before it replaces the original.
+
+The server must be stopped while the data directory is migrated.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("path required")
+			} else if len(args) > 1 {
+				return fmt.Errorf("only one path allowed")
+			}
+			Migrator.Path = args[0]
+			if err := Migrator.Run(context.Background()); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+	return migrateCmd
+}
+
+func init() {
+	subcommandFns["migrate"] = NewMigrateCommand
+}
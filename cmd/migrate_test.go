@@ -0,0 +1,98 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pilosa/pilosa/ctl"
+	"github.com/pilosa/pilosa/roaring"
+)
+
+func TestMigrateHelp(t *testing.T) {
+	output, err := ExecNewRootCommand(t, "migrate", "--help")
+	if !strings.Contains(output, "Usage:") ||
+		!strings.Contains(output, "Flags:") ||
+		!strings.Contains(output, "pilosa migrate") || err != nil {
+		t.Fatalf("Command 'migrate --help' not working, err: '%v', output: '%s'", err, output)
+	}
+}
+
+func TestMigrateNoPath(t *testing.T) {
+	output, err := ExecNewRootCommand(t, "migrate")
+	if !strings.Contains(err.Error(), "path required") {
+		t.Fatalf("Command 'migrate' without args should error but: err: '%v', output: '%v'", err, output)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	path, err := ioutil.TempDir("", "pilosa-migrate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	// Write a version 0 fragment file containing the values 1 & 5.
+	dir := filepath.Join(path, "i", "f", "views", "standard", "fragments")
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	fragmentPath := filepath.Join(dir, "0")
+	if err := ioutil.WriteFile(fragmentPath, []byte{
+		0x3A, 0x30, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		0x18, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00,
+	}, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	m := ctl.NewMigrateCommand(os.Stdin, &buf, &buf)
+	m.Path = path
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(buf.String(), "migrated 1 fragments") {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+
+	data, err := ioutil.ReadFile(fragmentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bm := roaring.NewBitmap()
+	if err := bm.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	} else if v := bm.Info().Version; v != roaring.FormatVersion {
+		t.Fatalf("unexpected version: %d", v)
+	} else if got := bm.Slice(); !reflect.DeepEqual(got, []uint64{1, 5}) {
+		t.Fatalf("unexpected values: %+v", got)
+	}
+
+	// Migrating again should leave the file untouched.
+	buf.Reset()
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(buf.String(), "migrated 0 fragments, 1 already at version") {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+}
@@ -44,6 +44,7 @@ Restores a view to the cluster from a backup file.
 	}
 	flags := restoreCmd.Flags()
 	flags.StringVarP(&Restorer.Host, "host", "", "localhost:10101", "host:port of Pilosa.")
+	addClientFlags(flags, &Restorer.ClientOptions)
 	flags.StringVarP(&Restorer.Index, "index", "i", "", "Pilosa index to restore into.")
 	flags.StringVarP(&Restorer.Frame, "frame", "f", "", "Frame to restore into.")
 	flags.StringVarP(&Restorer.View, "view", "v", "", "View to restore into.")
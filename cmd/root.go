@@ -22,6 +22,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/pilosa/pilosa/ctl"
 )
 
 var (
@@ -151,3 +153,12 @@ func setAllConfig(v *viper.Viper, flags *pflag.FlagSet, envPrefix string) error
 	})
 	return flagErr
 }
+
+// addClientFlags adds the flags used to connect to a server to flags.
+func addClientFlags(flags *pflag.FlagSet, opt *ctl.ClientOptions) {
+	flags.StringVarP(&opt.TLSCertificatePath, "tls.certificate", "", "", "Path to the client certificate presented to servers using mutual TLS.")
+	flags.StringVarP(&opt.TLSKeyPath, "tls.key", "", "", "Path to the client certificate key.")
+	flags.StringVarP(&opt.TLSCACertificatePath, "tls.ca-certificate", "", "", "Path to the CA certificate used to verify servers. Enables HTTPS.")
+	flags.BoolVarP(&opt.TLSSkipVerify, "tls.skip-verify", "", false, "Skip verification of server certificates.")
+	flags.StringVarP(&opt.Token, "token", "", "", "Token sent as a bearer token to authenticate requests.")
+}
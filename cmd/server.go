@@ -24,6 +24,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pilosa/pilosa"
 	"github.com/pilosa/pilosa/server"
 )
 
@@ -93,14 +94,34 @@ on the configured port.`,
 	flags.StringSliceVarP(&Server.Config.Cluster.Hosts, "cluster.hosts", "", []string{}, "Comma separated list of hosts in cluster.")
 	flags.StringSliceVarP(&Server.Config.Cluster.InternalHosts, "cluster.internal-hosts", "", []string{}, "Comma separated list of hosts in cluster used for internal communication.")
 	flags.DurationVarP((*time.Duration)(&Server.Config.Cluster.PollingInterval), "cluster.poll-interval", "", time.Minute, "Polling interval for cluster.") // TODO what actually is this?
+	flags.StringVarP(&Server.Config.TLS.CertificatePath, "tls.certificate", "", "", "Path to the TLS certificate used to serve HTTPS and to authenticate to other nodes.")
+	flags.StringVarP(&Server.Config.TLS.CertificateKeyPath, "tls.key", "", "", "Path to the TLS certificate key.")
+	flags.StringVarP(&Server.Config.TLS.CACertificatePath, "tls.ca-certificate", "", "", "Path to the CA certificate used to verify other nodes. Enables mutual TLS.")
+	flags.BoolVarP(&Server.Config.TLS.SkipVerify, "tls.skip-verify", "", false, "Skip verification of server certificates.")
+	flags.StringVarP(&Server.Config.Auth.JWTKeyPath, "auth.jwt-key", "", "", "Path to the HS256 secret or RS256 public key used to verify JWTs. Enables authentication.")
+	flags.StringVarP(&Server.Config.Auth.InternalToken, "auth.internal-token", "", "", "Token used to authenticate requests between nodes. Enables authentication.")
+	flags.StringVarP(&Server.Config.Storage.Durability, "storage.durability", "", pilosa.DurabilityNone, "Fsync policy for fragment writes. Choose from [none, write, group]")
+	flags.DurationVarP((*time.Duration)(&Server.Config.Storage.GroupCommitInterval), "storage.group-commit-interval", "", pilosa.DefaultGroupCommitInterval, "Interval between fsyncs when using group durability.")
+	flags.IntVarP(&Server.Config.Storage.SnapshotConcurrency, "storage.snapshot-concurrency", "", pilosa.DefaultSnapshotConcurrency, "Maximum number of fragments snapshotting in the background at once. Zero is unlimited.")
+	flags.IntVarP(&Server.Config.Storage.MaxOpenFragments, "storage.max-open-fragments", "", pilosa.DefaultMaxOpenFragments, "Maximum number of fragments open at once. Least recently used fragments are closed over the limit. Zero is unlimited.")
+	flags.DurationVarP((*time.Duration)(&Server.Config.Storage.FragmentIdleTimeout), "storage.fragment-idle-timeout", "", pilosa.DefaultFragmentIdleTimeout, "Duration after which an unused fragment is closed. Zero never closes.")
+	flags.IntVarP(&Server.Config.Storage.OpenConcurrency, "storage.open-concurrency", "", pilosa.DefaultOpenConcurrency, "Maximum number of indexes, frames or fragments opened in parallel on startup.")
+	flags.BoolVarP(&Server.Config.Storage.PreloadFragments, "storage.preload-fragments", "", false, "Open all fragments in the background on startup instead of on first access.")
+	flags.DurationVarP((*time.Duration)(&Server.Config.Storage.RetentionCheckInterval), "storage.retention-check-interval", "", pilosa.DefaultRetentionCheckInterval, "Interval between deleting time views past their frame's retention. Zero disables retention.")
+	flags.Int64VarP(&Server.Config.Storage.RowCacheMaxBytes, "storage.row-cache-max-bytes", "", pilosa.DefaultRowCacheMaxBytes, "Maximum number of bytes of rows cached by all fragments. Zero is unlimited.")
+	flags.Int64VarP(&Server.Config.Storage.FragmentRowCacheMaxBytes, "storage.fragment-row-cache-max-bytes", "", pilosa.DefaultFragmentRowCacheMaxBytes, "Maximum number of bytes of rows cached by a single fragment. Zero is unlimited.")
 	flags.StringVarP(&Server.Config.Plugins.Path, "plugins.path", "", "", "Path to plugin directory.")
 	flags.StringVar(&Server.Config.LogPath, "log-path", "", "Log path")
 	flags.DurationVarP((*time.Duration)(&Server.Config.AntiEntropy.Interval), "anti-entropy.interval", "", time.Minute*10, "Interval at which to run anti-entropy routine.")
+	flags.IntVarP(&Server.Config.AntiEntropy.RateLimit, "anti-entropy.rate-limit", "", 0, "Maximum bytes per second transferred by anti-entropy. Zero is unlimited.")
+	flags.Float64VarP(&Server.Config.AntiEntropy.ReadRepairRate, "anti-entropy.read-repair-rate", "", 0, "Fraction of queries which repair the replicas they read. Zero disables read repair.")
 	flags.StringVarP(&Server.CPUProfile, "profile.cpu", "", "", "Where to store CPU profile.")
 	flags.DurationVarP(&Server.CPUTime, "profile.cpu-time", "", 30*time.Second, "CPU profile duration.")
 	flags.StringVarP(&Server.Config.Cluster.Type, "cluster.type", "", "static", "Determine how the cluster handles membership and state sharing. Choose from [static, http, gossip]")
 	flags.StringVarP(&Server.Config.Cluster.GossipSeed, "cluster.gossip-seed", "", "", "Host with which to seed the gossip membership.")
 	flags.StringVarP(&Server.Config.Cluster.InternalPort, "cluster.internal-port", "", "", "Port to which pilosa should bind for internal state sharing.")
+	flags.DurationVarP((*time.Duration)(&Server.Config.Cluster.HealthCheckInterval), "cluster.health-check-interval", "", pilosa.DefaultHealthCheckInterval, "Interval at which static cluster nodes are health checked. Zero disables checks.")
+	flags.DurationVarP((*time.Duration)(&Server.Config.Cluster.HealthCheckTimeout), "cluster.health-check-timeout", "", pilosa.DefaultHealthCheckTimeout, "Timeout for a single node health check.")
 
 	return serveCmd
 }
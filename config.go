@@ -14,7 +14,10 @@
 
 package pilosa
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	// DefaultHost is the default hostname to use.
@@ -43,14 +46,51 @@ type Config struct {
 		PollingInterval Duration `toml:"polling-interval"`
 		InternalPort    string   `toml:"internal-port"`
 		GossipSeed      string   `toml:"gossip-seed"`
+
+		HealthCheckInterval Duration `toml:"health-check-interval"`
+		HealthCheckTimeout  Duration `toml:"health-check-timeout"`
 	} `toml:"cluster"`
 
+	TLS struct {
+		CertificatePath    string `toml:"certificate"`
+		CertificateKeyPath string `toml:"key"`
+		CACertificatePath  string `toml:"ca-certificate"`
+		SkipVerify         bool   `toml:"skip-verify"`
+	} `toml:"tls"`
+
+	Auth struct {
+		Tokens []struct {
+			Name        string            `toml:"name"`
+			Token       string            `toml:"token"`
+			Permissions map[string]string `toml:"permissions"`
+		} `toml:"tokens"`
+		JWTKeyPath    string `toml:"jwt-key"`
+		InternalToken string `toml:"internal-token"`
+	} `toml:"auth"`
+
+	Storage struct {
+		Durability          string   `toml:"durability"`
+		GroupCommitInterval Duration `toml:"group-commit-interval"`
+		SnapshotConcurrency int      `toml:"snapshot-concurrency"`
+		MaxOpenFragments    int      `toml:"max-open-fragments"`
+		FragmentIdleTimeout Duration `toml:"fragment-idle-timeout"`
+		OpenConcurrency     int      `toml:"open-concurrency"`
+		PreloadFragments    bool     `toml:"preload-fragments"`
+
+		RetentionCheckInterval Duration `toml:"retention-check-interval"`
+
+		RowCacheMaxBytes         int64 `toml:"row-cache-max-bytes"`
+		FragmentRowCacheMaxBytes int64 `toml:"fragment-row-cache-max-bytes"`
+	} `toml:"storage"`
+
 	Plugins struct {
 		Path string `toml:"path"`
 	} `toml:"plugins"`
 
 	AntiEntropy struct {
-		Interval Duration `toml:"interval"`
+		Interval       Duration `toml:"interval"`
+		RateLimit      int      `toml:"rate-limit"`
+		ReadRepairRate float64  `toml:"read-repair-rate"`
 	} `toml:"anti-entropy"`
 
 	LogPath string `toml:"log-path"`
@@ -66,10 +106,29 @@ func NewConfig() *Config {
 	c.Cluster.PollingInterval = Duration(DefaultPollingInterval)
 	c.Cluster.Hosts = []string{}
 	c.Cluster.InternalHosts = []string{}
+	c.Cluster.HealthCheckInterval = Duration(DefaultHealthCheckInterval)
+	c.Cluster.HealthCheckTimeout = Duration(DefaultHealthCheckTimeout)
+	c.Storage.Durability = DurabilityNone
+	c.Storage.GroupCommitInterval = Duration(DefaultGroupCommitInterval)
+	c.Storage.SnapshotConcurrency = DefaultSnapshotConcurrency
+	c.Storage.MaxOpenFragments = DefaultMaxOpenFragments
+	c.Storage.FragmentIdleTimeout = Duration(DefaultFragmentIdleTimeout)
+	c.Storage.OpenConcurrency = DefaultOpenConcurrency
+	c.Storage.RetentionCheckInterval = Duration(DefaultRetentionCheckInterval)
+	c.Storage.RowCacheMaxBytes = DefaultRowCacheMaxBytes
+	c.Storage.FragmentRowCacheMaxBytes = DefaultFragmentRowCacheMaxBytes
 	c.AntiEntropy.Interval = Duration(DefaultAntiEntropyInterval)
 	return c
 }
 
+// Validate returns an error if the configuration has invalid values.
+func (c *Config) Validate() error {
+	if rate := c.AntiEntropy.ReadRepairRate; !(rate >= 0 && rate <= 1) {
+		return fmt.Errorf("invalid read repair rate: %v, must be between 0 and 1", rate)
+	}
+	return nil
+}
+
 // Duration is a TOML wrapper type for time.Duration.
 type Duration time.Duration
 
@@ -30,6 +30,9 @@ type BenchCommand struct {
 	// Destination host and port.
 	Host string
 
+	// Options used to connect to the host.
+	ClientOptions
+
 	// Name of the index & frame to execute against.
 	Index string
 	Frame string
@@ -52,7 +55,7 @@ func NewBenchCommand(stdin io.Reader, stdout, stderr io.Writer) *BenchCommand {
 // Run executes the bench command.
 func (cmd *BenchCommand) Run(ctx context.Context) error {
 	// Create a client to the server.
-	client, err := pilosa.NewClient(cmd.Host)
+	client, err := cmd.NewClient(cmd.Host)
 	if err != nil {
 		return err
 	}
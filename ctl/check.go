@@ -87,22 +87,16 @@ func (cmd *CheckCommand) checkBitmapFile(path string) error {
 	}
 	defer syscall.Munmap(data)
 
-	// Attach the mmap file to the bitmap.
-	bm := roaring.NewBitmap()
-	if err := bm.UnmarshalBinary(data); err != nil {
-		return err
-	}
+	// Attach the mmap file to the bitmap & perform consistency check.
+	if _, err := checkBitmap(data); err != nil {
+		errs, ok := err.(roaring.ErrorList)
+		if !ok {
+			return err
+		}
 
-	// Perform consistency check.
-	if err := bm.Check(); err != nil {
 		// Print returned errors.
-		switch err := err.(type) {
-		case roaring.ErrorList:
-			for i := range err {
-				fmt.Fprintf(cmd.Stdout, "%s: %s\n", path, err[i].Error())
-			}
-		default:
-			fmt.Fprintf(cmd.Stdout, "%s: %s\n", path, err.Error())
+		for i := range errs {
+			fmt.Fprintf(cmd.Stdout, "%s: %s\n", path, errs[i].Error())
 		}
 	}
 
@@ -112,6 +106,19 @@ func (cmd *CheckCommand) checkBitmapFile(path string) error {
 	return nil
 }
 
+// checkBitmap decodes data as a roaring bitmap file and performs a
+// consistency check. Inconsistencies are returned as a roaring.ErrorList.
+func checkBitmap(data []byte) (*roaring.Bitmap, error) {
+	bm := roaring.NewBitmap()
+	if err := bm.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if err := bm.Check(); err != nil {
+		return bm, err
+	}
+	return bm, nil
+}
+
 // checkCacheFile performs a consistency check on path for a cache file.
 func (cmd *CheckCommand) checkCacheFile(path string) error {
 	fmt.Fprintf(cmd.Stderr, "%s: ignoring cache file\n", path)
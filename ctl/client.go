@@ -0,0 +1,68 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctl
+
+import (
+	"strings"
+
+	"github.com/pilosa/pilosa"
+)
+
+// ClientOptions represents the options commands use to connect to a server.
+type ClientOptions struct {
+	// Paths to the client certificate & key presented to servers using
+	// mutual TLS, and to the CA certificate used to verify servers.
+	TLSCertificatePath   string
+	TLSKeyPath           string
+	TLSCACertificatePath string
+
+	// Skip verification of server certificates.
+	TLSSkipVerify bool
+
+	// Token sent as a bearer token on every request, if set.
+	Token string
+}
+
+// NewClient returns a client to host configured with the options.
+// The client uses HTTPS if TLS is configured and host has no scheme.
+func (o *ClientOptions) NewClient(host string) (*pilosa.Client, error) {
+	client, err := pilosa.NewClient(host)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.tlsEnabled() {
+		config, err := pilosa.NewClientTLSConfig(o.TLSCertificatePath, o.TLSKeyPath, o.TLSCACertificatePath, o.TLSSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		client.HTTPClient = pilosa.NewTLSHTTPClient(config)
+
+		if !strings.Contains(host, "://") {
+			client.Scheme = "https"
+		}
+	}
+
+	if o.Token != "" {
+		client.HTTPClient = pilosa.NewAuthHTTPClient(client.HTTPClient, o.Token)
+	}
+
+	return client, nil
+}
+
+// tlsEnabled returns true if any TLS options are set.
+func (o *ClientOptions) tlsEnabled() bool {
+	return o.TLSCertificatePath != "" || o.TLSKeyPath != "" || o.TLSCACertificatePath != "" || o.TLSSkipVerify
+}
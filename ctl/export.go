@@ -28,6 +28,9 @@ type ExportCommand struct {
 	// Remote host and port.
 	Host string
 
+	// Options used to connect to the host.
+	ClientOptions
+
 	// Name of the index & frame to export from.
 	Index string
 	Frame string
@@ -71,7 +74,7 @@ func (cmd *ExportCommand) Run(ctx context.Context) error {
 	}
 
 	// Create a client to the server.
-	client, err := pilosa.NewClient(cmd.Host)
+	client, err := cmd.NewClient(cmd.Host)
 	if err != nil {
 		return err
 	}
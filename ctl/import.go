@@ -33,6 +33,9 @@ type ImportCommand struct {
 	// Destination host and port.
 	Host string `json:"host"`
 
+	// Options used to connect to the host.
+	ClientOptions `json:"-"`
+
 	// Name of the index & frame to import into.
 	Index string `json:"index"`
 	Frame string `json:"frame"`
@@ -46,6 +49,9 @@ type ImportCommand struct {
 	// Reusable client.
 	Client *pilosa.Client `json:"-"`
 
+	// Slice width of the index, read from the server's schema.
+	sliceWidth uint64
+
 	// Standard input/output
 	*pilosa.CmdIO
 }
@@ -77,12 +83,19 @@ func (cmd *ImportCommand) Run(ctx context.Context) error {
 		return errors.New("path required")
 	}
 	// Create a client to the server.
-	client, err := pilosa.NewClient(cmd.Host)
+	client, err := cmd.NewClient(cmd.Host)
 	if err != nil {
 		return err
 	}
 	cmd.Client = client
 
+	// Read the slice width so bits are grouped by the server's slices.
+	sliceWidth, err := cmd.indexSliceWidth(ctx)
+	if err != nil {
+		return err
+	}
+	cmd.sliceWidth = sliceWidth
+
 	// Import each path and import by slice.
 	for _, path := range cmd.Paths {
 		// Parse path into bits.
@@ -95,6 +108,21 @@ func (cmd *ImportCommand) Run(ctx context.Context) error {
 	return nil
 }
 
+// indexSliceWidth returns the slice width of the index being imported into.
+// Servers which do not report a slice width use the default.
+func (cmd *ImportCommand) indexSliceWidth(ctx context.Context) (uint64, error) {
+	indexes, err := cmd.Client.Schema(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, index := range indexes {
+		if index.Name == cmd.Index && index.SliceWidth != 0 {
+			return index.SliceWidth, nil
+		}
+	}
+	return pilosa.SliceWidth, nil
+}
+
 // importPath parses a path into bits and imports it to the server.
 func (cmd *ImportCommand) importPath(ctx context.Context, path string) error {
 	a := make([]pilosa.Bit, 0, cmd.BufferSize)
@@ -185,7 +213,7 @@ func (cmd *ImportCommand) importBits(ctx context.Context, bits []pilosa.Bit) err
 
 	// Group bits by slice.
 	logger.Printf("grouping %d bits", len(bits))
-	bitsBySlice := pilosa.Bits(bits).GroupBySlice()
+	bitsBySlice := pilosa.Bits(bits).GroupBySlice(cmd.sliceWidth)
 
 	// Parse path into bits.
 	for slice, bits := range bitsBySlice {
@@ -82,6 +82,10 @@ func (cmd *InspectCommand) Run(ctx context.Context) error {
 
 	// Print top-level info.
 	fmt.Fprintf(cmd.Stdout, "== Bitmap Info ==\n")
+	fmt.Fprintf(cmd.Stdout, "Version: %d\n", info.Version)
+	if !info.CreatedAt.IsZero() {
+		fmt.Fprintf(cmd.Stdout, "Created: %s\n", info.CreatedAt.Format(time.RFC3339))
+	}
 	fmt.Fprintf(cmd.Stdout, "Containers: %d\n", len(info.Containers))
 	fmt.Fprintf(cmd.Stdout, "Operations: %d\n", info.OpN)
 	fmt.Fprintln(cmd.Stdout, "")
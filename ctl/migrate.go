@@ -0,0 +1,186 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/pilosa/pilosa"
+	"github.com/pilosa/pilosa/roaring"
+)
+
+// MigrateExt is the file extension used while a fragment file is migrated.
+const MigrateExt = ".migrating"
+
+// MigrateCommand represents a command for upgrading the fragment files in a
+// data directory to the current file format version.
+type MigrateCommand struct {
+	// Data directory path.
+	Path string
+
+	// Standard input/output
+	*pilosa.CmdIO
+}
+
+// NewMigrateCommand returns a new instance of MigrateCommand.
+func NewMigrateCommand(stdin io.Reader, stdout, stderr io.Writer) *MigrateCommand {
+	return &MigrateCommand{
+		CmdIO: pilosa.NewCmdIO(stdin, stdout, stderr),
+	}
+}
+
+// Run executes the migrate command.
+func (cmd *MigrateCommand) Run(ctx context.Context) error {
+	if cmd.Path == "" {
+		return errors.New("path required")
+	}
+
+	var migratedN, skippedN int
+	if err := filepath.Walk(cmd.Path, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if !isFragmentFile(path, fi) {
+			return nil
+		}
+
+		migrated, err := cmd.migrateFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		} else if migrated {
+			migratedN++
+		} else {
+			skippedN++
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Stdout, "migrated %d fragments, %d already at version %d\n", migratedN, skippedN, roaring.FormatVersion)
+	return nil
+}
+
+// isFragmentFile returns true if path is a fragment data file.
+func isFragmentFile(path string, fi os.FileInfo) bool {
+	if !fi.Mode().IsRegular() || filepath.Base(filepath.Dir(path)) != "fragments" {
+		return false
+	}
+	_, err := strconv.ParseUint(fi.Name(), 10, 64)
+	return err == nil
+}
+
+// migrateFile rewrites the fragment file at path using the current file
+// format version. Returns false if the file is already at the current version.
+func (cmd *MigrateCommand) migrateFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	// Lock the file so that fragments in use by a running server are not changed.
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return false, fmt.Errorf("flock: %s (is the server running?)", err)
+	}
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return false, err
+	}
+
+	// Verify the original file before migrating it.
+	bm, err := checkBitmap(data)
+	if err != nil {
+		return false, fmt.Errorf("check: %s", err)
+	} else if bm.Info().Version == roaring.FormatVersion {
+		return false, nil
+	}
+
+	// Write the bitmap to a temporary file in the new format.
+	tmpPath := path + MigrateExt
+	if err := writeBitmapFile(tmpPath, bm); err != nil {
+		os.Remove(tmpPath)
+		return false, err
+	}
+
+	// Verify the new file before replacing the original.
+	if err := verifyMigratedFile(tmpPath, bm); err != nil {
+		os.Remove(tmpPath)
+		return false, fmt.Errorf("verify: %s", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(cmd.Stdout, "%s: migrated to version %d\n", path, roaring.FormatVersion)
+	return true, nil
+}
+
+// writeBitmapFile writes bm to a new file at path and syncs it to disk.
+func writeBitmapFile(path string, bm *roaring.Bitmap) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	bw := bufio.NewWriter(file)
+	if _, err := bm.WriteTo(bw); err != nil {
+		return err
+	} else if err := bw.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// verifyMigratedFile checks the file at path for consistency and ensures it
+// contains the same values as bm.
+func verifyMigratedFile(path string, bm *roaring.Bitmap) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	other, err := checkBitmap(data)
+	if err != nil {
+		return err
+	} else if v := other.Info().Version; v != roaring.FormatVersion {
+		return fmt.Errorf("unexpected version: %d", v)
+	} else if n, m := bm.Count(), other.Count(); n != m {
+		return fmt.Errorf("count mismatch: %d != %d", n, m)
+	}
+
+	// Compare every value in both bitmaps.
+	itr, otherItr := bm.Iterator(), other.Iterator()
+	for {
+		v, eof := itr.Next()
+		ov, oeof := otherItr.Next()
+		if eof != oeof || v != ov {
+			return fmt.Errorf("value mismatch: %d != %d", v, ov)
+		} else if eof {
+			return nil
+		}
+	}
+}
@@ -28,6 +28,9 @@ type RestoreCommand struct {
 	// Destination host and port.
 	Host string
 
+	// Options used to connect to the host.
+	ClientOptions
+
 	// Name of the index & frame to backup.
 	Index string
 	Frame string
@@ -55,7 +58,7 @@ func (cmd *RestoreCommand) Run(ctx context.Context) error {
 	}
 
 	// Create a client to the server.
-	client, err := pilosa.NewClient(cmd.Host)
+	client, err := cmd.NewClient(cmd.Host)
 	if err != nil {
 		return err
 	}
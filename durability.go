@@ -0,0 +1,93 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Durability modes.
+const (
+	// DurabilityNone leaves flushing writes to disk to the operating system.
+	DurabilityNone = "none"
+
+	// DurabilityWrite fsyncs the op log after every write.
+	DurabilityWrite = "write"
+
+	// DurabilityGroup fsyncs the op log once per interval for all writes
+	// received during that interval. Writers block until their batch is synced.
+	DurabilityGroup = "group"
+)
+
+const (
+	// DefaultGroupCommitInterval is the default interval between group commits.
+	DefaultGroupCommitInterval = 10 * time.Millisecond
+)
+
+// Durability represents the fsync policy for fragment writes & snapshots.
+type Durability struct {
+	Mode string
+
+	// Interval between fsyncs when using DurabilityGroup.
+	Interval time.Duration
+}
+
+// Validate returns an error if the durability settings are invalid.
+func (d Durability) Validate() error {
+	switch d.Mode {
+	case "", DurabilityNone, DurabilityWrite:
+		return nil
+	case DurabilityGroup:
+		if d.Interval <= 0 {
+			return fmt.Errorf("invalid group commit interval: %s", d.Interval)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid durability: %q", d.Mode)
+	}
+}
+
+// enabled returns true if writes & snapshots should be fsynced.
+func (d Durability) enabled() bool {
+	return d.Mode == DurabilityWrite || d.Mode == DurabilityGroup
+}
+
+// commitBatch represents a set of writes waiting on the same fsync.
+type commitBatch struct {
+	done chan struct{}
+	err  error
+}
+
+// wait blocks until the batch has been synced. Returns the sync error, if any.
+// A nil batch returns immediately.
+func (b *commitBatch) wait() error {
+	if b == nil {
+		return nil
+	}
+	<-b.done
+	return b.err
+}
+
+// syncDir fsyncs the directory at path so that renames within it are durable.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
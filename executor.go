@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"sort"
@@ -37,6 +39,19 @@ const (
 	// MinThreshold is the lowest count to use in a Top-N operation when
 	// looking for additional id/count pairs.
 	MinThreshold = 1
+
+	// DefaultReadRepairQueueSize is the maximum number of read repairs
+	// waiting to run. Further repairs are skipped until the queue drains.
+	DefaultReadRepairQueueSize = 16
+
+	// MaxTimeBuckets is the maximum number of intervals a CountByTime()
+	// call can return.
+	MaxTimeBuckets = 10000
+
+	// decayScale is the fixed-point scale of decayed TopN() counts. Counts
+	// are merged across views, slices & nodes at this scale so that they
+	// are only rounded in the final result.
+	decayScale = 1 << 16
 )
 
 // Executor recursively executes calls in a PQL query across all slices.
@@ -49,12 +64,21 @@ type Executor struct {
 
 	// Client used for remote HTTP requests.
 	HTTPClient *http.Client
+
+	// Fraction of queries, between 0 and 1, which compare the fragments they
+	// read locally against the other replicas and repair any differences.
+	// Zero disables read repair.
+	ReadRepairRate float64
+
+	// Read repairs waiting to be processed by ProcessReadRepairs().
+	readRepairs chan readRepairRequest
 }
 
 // NewExecutor returns a new instance of Executor.
 func NewExecutor() *Executor {
 	return &Executor{
-		HTTPClient: http.DefaultClient,
+		HTTPClient:  http.DefaultClient,
+		readRepairs: make(chan readRepairRequest, DefaultReadRepairQueueSize),
 	}
 }
 
@@ -70,6 +94,14 @@ func (e *Executor) Execute(ctx context.Context, index string, q *pql.Query, slic
 		opt = &ExecOptions{}
 	}
 
+	// Sample query for read repair. Remote queries are sampled by the
+	// originating node which sets the read repair option for every node.
+	if !opt.Remote && e.ReadRepairRate > 0 && rand.Float64() < e.ReadRepairRate {
+		other := *opt
+		other.readRepair = true
+		opt = &other
+	}
+
 	// Don't bother calculating slices for query types that don't require it.
 	needsSlices := needsSlices(q.Calls)
 
@@ -108,6 +140,13 @@ func (e *Executor) Execute(ctx context.Context, index string, q *pql.Query, slic
 		}
 	}
 
+	// Resolve relative times once so all nodes see the same instant.
+	if !opt.Remote {
+		if err := resolveTimeArgs(q.Calls, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Optimize handling for bulk attribute insertion.
 	if hasOnlySetRowAttrs(q.Calls) {
 		return e.executeBulkSetRowAttrs(ctx, index, q.Calls, opt)
@@ -155,10 +194,16 @@ func (e *Executor) executeCall(ctx context.Context, index string, c *pql.Call, s
 	switch c.Name {
 	case "ClearBit":
 		return e.executeClearBit(ctx, index, c, opt)
+	case "ClearRow":
+		return e.executeClearRow(ctx, index, c, slices, opt)
 	case "Count":
 		return e.executeCount(ctx, index, c, slices, opt)
+	case "CountByTime":
+		return e.executeCountByTime(ctx, index, c, slices, opt)
 	case "SetBit":
 		return e.executeSetBit(ctx, index, c, opt)
+	case "SetRow", "Store":
+		return e.executeSetRow(ctx, index, c, slices, opt)
 	case "SetRowAttrs":
 		return nil, e.executeSetRowAttrs(ctx, index, c, opt)
 	case "SetColumnAttrs":
@@ -280,15 +325,28 @@ func (e *Executor) executeTopN(ctx context.Context, index string, c *pql.Call, s
 		return nil, fmt.Errorf("executeTopN: %v", err)
 	}
 
+	halfLife, err := halfLifeArg(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decayed counts are scaled until they reach the original caller.
+	decayed := halfLife > 0
+
 	// Execute original query.
-	pairs, err := e.executeTopNSlices(ctx, index, c, slices, opt)
+	pairs, err := e.executeTopNSlices(ctx, index, c, slices, halfLife, opt)
 	if err != nil {
 		return nil, err
 	}
 
 	// If this call is against specific ids, or we didn't get results,
 	// or we are part of a larger distributed query then don't refetch.
-	if len(pairs) == 0 || len(rowIDs) > 0 || opt.Remote {
+	if opt.Remote {
+		return pairs, nil
+	} else if len(pairs) == 0 || len(rowIDs) > 0 {
+		if decayed {
+			pairs = unscaleDecayedPairs(pairs)
+		}
 		return pairs, nil
 	}
 	// Only the original caller should refetch the full counts.
@@ -298,10 +356,13 @@ func (e *Executor) executeTopN(ctx context.Context, index string, c *pql.Call, s
 	sort.Sort(uint64Slice(ids))
 	other.Args["ids"] = ids
 
-	trimmedList, err := e.executeTopNSlices(ctx, index, other, slices, opt)
+	trimmedList, err := e.executeTopNSlices(ctx, index, other, slices, halfLife, opt)
 	if err != nil {
 		return nil, err
 	}
+	if decayed {
+		trimmedList = unscaleDecayedPairs(trimmedList)
+	}
 
 	if n != 0 && int(n) < len(trimmedList) {
 		trimmedList = trimmedList[0:n]
@@ -309,10 +370,10 @@ func (e *Executor) executeTopN(ctx context.Context, index string, c *pql.Call, s
 	return trimmedList, nil
 }
 
-func (e *Executor) executeTopNSlices(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]Pair, error) {
+func (e *Executor) executeTopNSlices(ctx context.Context, index string, c *pql.Call, slices []uint64, halfLife time.Duration, opt *ExecOptions) ([]Pair, error) {
 	// Execute calls in bulk on each remote node and merge.
 	mapFn := func(slice uint64) (interface{}, error) {
-		return e.executeTopNSlice(ctx, index, c, slice)
+		return e.executeTopNSlice(ctx, index, c, slice, halfLife)
 	}
 
 	// Merge returned results at coordinating node.
@@ -333,8 +394,9 @@ func (e *Executor) executeTopNSlices(ctx context.Context, index string, c *pql.C
 	return results, nil
 }
 
-// executeTopNSlice executes a TopN call for a single slice.
-func (e *Executor) executeTopNSlice(ctx context.Context, index string, c *pql.Call, slice uint64) ([]Pair, error) {
+// executeTopNSlice executes a TopN call for a single slice. Counts of older
+// time views are decayed if halfLife is non-zero.
+func (e *Executor) executeTopNSlice(ctx context.Context, index string, c *pql.Call, slice uint64, halfLife time.Duration) ([]Pair, error) {
 	frame, _ := c.Args["frame"].(string)
 	n, _, err := c.UintArg("n")
 	if err != nil {
@@ -372,11 +434,6 @@ func (e *Executor) executeTopNSlice(ctx context.Context, index string, c *pql.Ca
 		frame = DefaultFrame
 	}
 
-	f := e.Holder.Fragment(index, frame, ViewStandard, slice)
-	if f == nil {
-		return nil, nil
-	}
-
 	if minThreshold <= 0 {
 		minThreshold = MinThreshold
 	}
@@ -384,7 +441,7 @@ func (e *Executor) executeTopNSlice(ctx context.Context, index string, c *pql.Ca
 	if tanimotoThreshold > 100 {
 		return nil, errors.New("Tanimoto Threshold is from 1 to 100 only")
 	}
-	return f.Top(TopOptions{
+	opt := TopOptions{
 		N:                 int(n),
 		Src:               src,
 		RowIDs:            rowIDs,
@@ -392,7 +449,44 @@ func (e *Executor) executeTopNSlice(ctx context.Context, index string, c *pql.Ca
 		FilterValues:      filters,
 		MinThreshold:      minThreshold,
 		TanimotoThreshold: tanimotoThreshold,
-	})
+	}
+
+	// Merge the top rows of each time view's rank cache if a range is given.
+	start, end, ok, err := timeRangeArgs(c)
+	if err != nil {
+		return nil, err
+	} else if halfLife > 0 && end.IsZero() {
+		return nil, errors.New("TopN() end time required with halfLife")
+	} else if ok {
+		fr := e.Holder.Frame(index, frame)
+		if fr == nil {
+			return nil, ErrFrameNotFound
+		}
+
+		var pairs []Pair
+		for _, view := range timeRangeViews(fr, ViewStandard, start, end) {
+			f := e.Holder.Fragment(index, frame, view, slice)
+			if f == nil {
+				continue
+			}
+
+			other, err := f.Top(opt)
+			if err != nil {
+				return nil, err
+			}
+			if halfLife > 0 {
+				other = decayPairs(other, decayWeight(view, end, halfLife))
+			}
+			pairs = Pairs(pairs).Add(other)
+		}
+		return pairs, nil
+	}
+
+	f := e.Holder.Fragment(index, frame, ViewStandard, slice)
+	if f == nil {
+		return nil, nil
+	}
+	return f.Top(opt)
 }
 
 // executeDifferenceSlice executes a difference() call for a local slice.
@@ -457,6 +551,14 @@ func (e *Executor) executeBitmapSlice(ctx context.Context, index string, c *pql.
 		}
 	}
 
+	// Union the row across time views if a range is given.
+	start, end, ok, err := timeRangeArgs(c)
+	if err != nil {
+		return nil, err
+	} else if ok {
+		return e.timeRangeRow(index, f, view, id, start, end, slice), nil
+	}
+
 	frag := e.Holder.Fragment(index, frame, view, slice)
 	if frag == nil {
 		return NewBitmap(), nil
@@ -507,42 +609,177 @@ func (e *Executor) executeRangeSlice(ctx context.Context, index string, c *pql.C
 		return nil, fmt.Errorf("executeRangeSlice - reading row: %v", err)
 	}
 
-	// Parse start time.
-	startTimeStr, ok := c.Args["start"].(string)
-	if !ok {
-		return nil, errors.New("Range() start time required")
-	}
-	startTime, err := time.Parse(TimeFormat, startTimeStr)
+	// Parse start & end times.
+	startTime, endTime, ok, err := timeRangeArgs(c)
 	if err != nil {
-		return nil, errors.New("cannot parse Range() start time")
+		return nil, err
+	} else if !ok {
+		return nil, errors.New("Range() start or end time required")
 	}
 
-	// Parse end time.
-	endTimeStr, _ := c.Args["end"].(string)
+	// Union bitmaps across all time-based subframes.
+	return e.timeRangeRow(index, f, ViewStandard, rowID, startTime, endTime, slice), nil
+}
+
+// timeRangeRow returns the union of a row across the time views of base
+// that cover the range from start to end.
+func (e *Executor) timeRangeRow(index string, f *Frame, base string, id uint64, start, end time.Time, slice uint64) *Bitmap {
+	bm := &Bitmap{}
+	for _, view := range timeRangeViews(f, base, start, end) {
+		frag := e.Holder.Fragment(index, f.Name(), view, slice)
+		if frag == nil {
+			continue
+		}
+		bm = bm.Union(frag.Row(id))
+	}
+	return bm
+}
+
+// halfLifeArg returns the half-life used to decay counts of older time views
+// in a TopN() call. The value is a duration string, such as "7d", or an
+// integer number of seconds. Returns zero if the argument is not set.
+func halfLifeArg(c *pql.Call) (time.Duration, error) {
+	v, ok := c.Args["halfLife"]
 	if !ok {
-		return nil, errors.New("Range() end time required")
+		return 0, nil
 	}
-	endTime, err := time.Parse(TimeFormat, endTimeStr)
-	if err != nil {
-		return nil, errors.New("cannot parse Range() end time")
+
+	var halfLife time.Duration
+	switch v := v.(type) {
+	case string:
+		d, err := parseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("TopN() invalid halfLife: %s", v)
+		}
+		halfLife = d
+	case int64:
+		if v > math.MaxInt64/int64(time.Second) {
+			return 0, fmt.Errorf("TopN() invalid halfLife: %d", v)
+		}
+		halfLife = time.Duration(v) * time.Second
+	case uint64:
+		if v > math.MaxInt64/uint64(time.Second) {
+			return 0, fmt.Errorf("TopN() invalid halfLife: %d", v)
+		}
+		halfLife = time.Duration(v) * time.Second
+	default:
+		return 0, fmt.Errorf("TopN() invalid halfLife: %v", v)
+	}
+
+	if halfLife <= 0 {
+		return 0, fmt.Errorf("TopN() invalid halfLife: %v", v)
 	}
+	return halfLife, nil
+}
+
+// decayPairs returns a copy of pairs with each count multiplied by weight.
+// Counts are scaled by decayScale so fractional counts can be summed.
+func decayPairs(pairs []Pair, weight float64) []Pair {
+	other := make([]Pair, 0, len(pairs))
+	for _, pair := range pairs {
+		if n := uint64(float64(pair.Count)*weight*decayScale + 0.5); n > 0 {
+			other = append(other, Pair{ID: pair.ID, Count: n})
+		}
+	}
+	return other
+}
 
-	// If no quantum exists then return an empty bitmap.
+// unscaleDecayedPairs returns a copy of pairs with each decayed count
+// rounded to the nearest integer. Pairs which round to zero are removed.
+func unscaleDecayedPairs(pairs []Pair) []Pair {
+	other := make([]Pair, 0, len(pairs))
+	for _, pair := range pairs {
+		if n := (pair.Count + decayScale/2) / decayScale; n > 0 {
+			other = append(other, Pair{ID: pair.ID, Count: n})
+		}
+	}
+	return other
+}
+
+// timeRangeViews returns the time views of base that cover the range from
+// start to end. A zero start or end is open-ended and is bounded by the
+// time views that exist in the frame.
+func timeRangeViews(f *Frame, base string, start, end time.Time) []string {
+	// If no quantum exists then there are no time views.
 	q := f.TimeQuantum()
 	if q == "" {
-		return &Bitmap{}, nil
+		return nil
 	}
 
-	// Union bitmaps across all time-based subframes.
-	bm := &Bitmap{}
-	for _, view := range ViewsByTimeRange(ViewStandard, startTime, endTime, q) {
-		f := e.Holder.Fragment(index, frame, view, slice)
-		if f == nil {
-			continue
+	if start.IsZero() || end.IsZero() {
+		min, max, ok := f.timeViewRange(base)
+		if !ok {
+			return nil
+		}
+		if start.IsZero() {
+			start = min
+		}
+		if end.IsZero() {
+			end = max
 		}
-		bm = bm.Union(f.Row(rowID))
 	}
-	return bm, nil
+
+	return ViewsByTimeRange(base, start, end, q)
+}
+
+// timeRangeArgs parses the start & end time arguments of c. A missing start
+// or end is returned as a zero time. Returns false if neither argument is set.
+func timeRangeArgs(c *pql.Call) (start, end time.Time, ok bool, err error) {
+	startStr, hasStart := c.Args["start"].(string)
+	endStr, hasEnd := c.Args["end"].(string)
+	if !hasStart && !hasEnd {
+		return start, end, false, nil
+	}
+
+	// Relative times are normally resolved by the coordinator before this.
+	now := time.Now()
+	if hasStart {
+		if start, err = ParseTime(startStr, now); err != nil {
+			return start, end, false, fmt.Errorf("cannot parse %s() start time", c.Name)
+		}
+	}
+	if hasEnd {
+		if end, err = ParseTime(endStr, now); err != nil {
+			return start, end, false, fmt.Errorf("cannot parse %s() end time", c.Name)
+		}
+	}
+	return start, end, true, nil
+}
+
+// timeArgs are the call arguments which hold time values.
+var timeArgs = []string{"start", "end", "timestamp"}
+
+// resolveTimeArgs rewrites relative time arguments of calls and their children
+// as absolute RFC3339 timestamps. This is done once by the coordinator so that
+// every slice evaluates relative times at the same instant.
+func resolveTimeArgs(calls []*pql.Call, now time.Time) error {
+	for _, c := range calls {
+		for _, key := range timeArgs {
+			v, ok := c.Args[key].(string)
+			if !ok {
+				continue
+			}
+
+			// Validate absolute times but pass them through unchanged.
+			if !IsRelativeTime(v) {
+				if _, err := ParseTime(v, now); err != nil {
+					return fmt.Errorf("cannot parse %s() %s: %s", c.Name, key, v)
+				}
+				continue
+			}
+
+			t, err := ParseTime(v, now)
+			if err != nil {
+				return fmt.Errorf("cannot parse %s() %s: %s", c.Name, key, v)
+			}
+			c.Args[key] = t.Format(time.RFC3339)
+		}
+
+		if err := resolveTimeArgs(c.Children, now); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // executeUnionSlice executes a union() call for a local slice.
@@ -596,6 +833,131 @@ func (e *Executor) executeCount(ctx context.Context, index string, c *pql.Call,
 	return n, nil
 }
 
+// executeCountByTime executes a CountByTime() call. The input bitmap is
+// counted once per interval between start and end by limiting its calls
+// against frame to the time views of each interval.
+func (e *Executor) executeCountByTime(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]TimeCount, error) {
+	if len(c.Children) == 0 {
+		return nil, errors.New("CountByTime() requires an input bitmap")
+	} else if len(c.Children) > 1 {
+		return nil, errors.New("CountByTime() only accepts a single bitmap input")
+	}
+
+	frame, _ := c.Args["frame"].(string)
+	if frame == "" {
+		frame = DefaultFrame
+	}
+
+	start, end, _, err := timeRangeArgs(c)
+	if err != nil {
+		return nil, err
+	} else if start.IsZero() || end.IsZero() {
+		return nil, errors.New("CountByTime() start and end time required")
+	}
+
+	interval, _ := c.Args["interval"].(string)
+	unit, err := parseTimeInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// Intervals must be composed of whole units of the frame's finest time
+	// views. For example, weeks cannot be counted from month views.
+	f := e.Holder.Frame(index, frame)
+	if f == nil {
+		return nil, ErrFrameNotFound
+	}
+	q := f.TimeQuantum()
+	if q == "" {
+		return nil, errors.New("CountByTime() frame has no time quantum")
+	} else if finest := rune(q[len(q)-1]); finest != unit && !nestedTimeUnit(finest, unit) {
+		return nil, fmt.Errorf("CountByTime() interval %s is not made up of time quantum %s units", interval, q)
+	}
+
+	// Split the range into buckets aligned to the interval.
+	var buckets []time.Time
+	for t := truncateTimeUnit(start, unit); t.Before(end); t = addTimeUnit(t, unit) {
+		if len(buckets) == MaxTimeBuckets {
+			return nil, fmt.Errorf("CountByTime() exceeds %d buckets", MaxTimeBuckets)
+		}
+		buckets = append(buckets, t)
+	}
+
+	// Build an input bitmap call for each bucket.
+	calls := make([]*pql.Call, len(buckets))
+	for i, t := range buckets {
+		bucketStart, bucketEnd := t, addTimeUnit(t, unit)
+		if bucketStart.Before(start) {
+			bucketStart = start
+		}
+		if bucketEnd.After(end) {
+			bucketEnd = end
+		}
+		calls[i] = limitTimeRange(c.Children[0], frame, bucketStart, bucketEnd)
+	}
+
+	// Execute calls in bulk on each remote node and merge.
+	mapFn := func(slice uint64) (interface{}, error) {
+		counts := make([]TimeCount, len(calls))
+		for i, call := range calls {
+			bm, err := e.executeBitmapCallSlice(ctx, index, call, slice)
+			if err != nil {
+				return nil, err
+			}
+			counts[i] = TimeCount{Time: buckets[i], Count: bm.Count()}
+		}
+		return counts, nil
+	}
+
+	// Merge returned results at coordinating node.
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.([]TimeCount)
+		return TimeCounts(other).Add(v.([]TimeCount))
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return nil, err
+	}
+	counts, _ := result.([]TimeCount)
+
+	return counts, nil
+}
+
+// limitTimeRange returns a copy of c with the Bitmap() and Range() calls
+// against frame limited to the time range from start to end.
+func limitTimeRange(c *pql.Call, frame string, start, end time.Time) *pql.Call {
+	other := c.Clone()
+	limitTimeRangeArgs(other, frame, start, end)
+	return other
+}
+
+func limitTimeRangeArgs(c *pql.Call, frame string, start, end time.Time) {
+	if c.Name == "Bitmap" || c.Name == "Range" {
+		name, _ := c.Args["frame"].(string)
+		if name == "" {
+			name = DefaultFrame
+		}
+
+		if name == frame {
+			// Narrow any existing range on the call.
+			callStart, callEnd, _, err := timeRangeArgs(c)
+			if err != nil || callStart.Before(start) {
+				callStart = start
+			}
+			if err != nil || callEnd.IsZero() || callEnd.After(end) {
+				callEnd = end
+			}
+			c.Args["start"] = callStart.Format(time.RFC3339)
+			c.Args["end"] = callEnd.Format(time.RFC3339)
+		}
+	}
+
+	for _, child := range c.Children {
+		limitTimeRangeArgs(child, frame, start, end)
+	}
+}
+
 // executeClearBit executes a ClearBit() call.
 func (e *Executor) executeClearBit(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) (bool, error) {
 	view, _ := c.Args["view"].(string)
@@ -662,7 +1024,7 @@ func (e *Executor) executeClearBit(ctx context.Context, index string, c *pql.Cal
 
 // executeClearBitView executes a ClearBit() call for a single view.
 func (e *Executor) executeClearBitView(ctx context.Context, index string, c *pql.Call, f *Frame, view string, colID, rowID uint64, opt *ExecOptions) (bool, error) {
-	slice := colID / SliceWidth
+	slice := colID / f.SliceWidth()
 	ret := false
 	for _, node := range e.Cluster.FragmentNodes(index, slice) {
 		// Update locally if host matches.
@@ -730,7 +1092,7 @@ func (e *Executor) executeSetBit(ctx context.Context, index string, c *pql.Call,
 	var timestamp *time.Time
 	sTimestamp, ok := c.Args["timestamp"].(string)
 	if ok {
-		t, err := time.Parse(TimeFormat, sTimestamp)
+		t, err := ParseTime(sTimestamp, time.Now())
 		if err != nil {
 			return false, fmt.Errorf("invalid date: %s", sTimestamp)
 		}
@@ -766,7 +1128,7 @@ func (e *Executor) executeSetBit(ctx context.Context, index string, c *pql.Call,
 
 // executeSetBitView executes a SetBit() call for a specific view.
 func (e *Executor) executeSetBitView(ctx context.Context, index string, c *pql.Call, f *Frame, view string, colID, rowID uint64, timestamp *time.Time, opt *ExecOptions) (bool, error) {
-	slice := colID / SliceWidth
+	slice := colID / f.SliceWidth()
 	ret := false
 
 	for _, node := range e.Cluster.FragmentNodes(index, slice) {
@@ -796,6 +1158,236 @@ func (e *Executor) executeSetBitView(ctx context.Context, index string, c *pql.C
 	return ret, nil
 }
 
+// executeClearRow executes a ClearRow() call.
+func (e *Executor) executeClearRow(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (bool, error) {
+	f, rowID, err := e.rowWriteArgs(index, c)
+	if err != nil {
+		return false, err
+	}
+
+	// Clear the row from standard views and the matching column from inverse
+	// views, including all time views.
+	mapFn := func(slice uint64) (interface{}, error) {
+		var ret bool
+		for _, view := range f.Views() {
+			frag := view.Fragment(slice)
+			if frag == nil {
+				continue
+			}
+
+			var changed bool
+			var err error
+			if !IsInverseView(view.Name()) {
+				changed, err = frag.SetRow(rowID, NewBitmap())
+			} else if slice == rowID/f.SliceWidth() {
+				changed, err = frag.SetColumn(rowID, NewBitmap())
+			}
+			if err != nil {
+				return nil, err
+			} else if changed {
+				ret = true
+			}
+		}
+		return ret, nil
+	}
+
+	return e.executeRowWrite(ctx, index, c, f, rowID, slices, opt, mapFn)
+}
+
+// executeSetRow executes a SetRow() or Store() call. Store() materializes
+// the result of a bitmap expression so it can be reused by later queries.
+func (e *Executor) executeSetRow(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (bool, error) {
+	if len(c.Children) == 0 {
+		return false, fmt.Errorf("%s() requires an input bitmap", c.Name)
+	} else if len(c.Children) > 1 {
+		return false, fmt.Errorf("%s() only accepts a single bitmap input", c.Name)
+	}
+
+	f, rowID, err := e.rowWriteArgs(index, c)
+	if err != nil {
+		return false, err
+	}
+
+	// Overwrite the row in the standard view with the columns of the input
+	// bitmap in each slice. Unlike ClearRow(), time views are left unchanged
+	// since the call has no timestamp, so Range() still returns the bits set
+	// in the row before.
+	mapFn := func(slice uint64) (interface{}, error) {
+		bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice)
+		if err != nil {
+			return nil, err
+		}
+
+		ret, err := setFragmentRow(f, ViewStandard, slice, rowID, bm)
+		if err != nil {
+			return nil, err
+		}
+
+		// The inverse view stores the row as a column in a single slice so
+		// the input bitmap is needed across all slices.
+		if f.InverseEnabled() && slice == rowID/f.SliceWidth() {
+			maxSlice := e.Holder.Index(index).MaxSlice()
+			allSlices := make([]uint64, maxSlice+1)
+			for i := range allSlices {
+				allSlices[i] = uint64(i)
+			}
+
+			bm, err := e.executeBitmapCall(ctx, index, c.Children[0], allSlices, &ExecOptions{})
+			if err != nil {
+				return nil, err
+			}
+
+			if changed, err := setFragmentRow(f, ViewInverse, slice, rowID, bm); err != nil {
+				return nil, err
+			} else if changed {
+				ret = true
+			}
+
+			// Setting the row clears the other rows of its columns in mutex
+			// frames so the columns must be cleared from the other slices of
+			// the inverse view.
+			if view := f.View(ViewInverse); f.Mutex() && view != nil {
+				for _, columnID := range bm.Bits() {
+					if changed, err := view.clearMutexRow(columnID, slice); err != nil {
+						return nil, err
+					} else if changed {
+						ret = true
+					}
+				}
+			}
+		}
+		return ret, nil
+	}
+
+	return e.executeRowWrite(ctx, index, c, f, rowID, slices, opt, mapFn)
+}
+
+// rowWriteArgs returns the frame and row ID of a call which writes a row.
+func (e *Executor) rowWriteArgs(index string, c *pql.Call) (*Frame, uint64, error) {
+	frameName, ok := c.Args["frame"].(string)
+	if !ok {
+		return nil, 0, fmt.Errorf("%s() frame required", c.Name)
+	}
+
+	f := e.Holder.Frame(index, frameName)
+	if f == nil {
+		return nil, 0, ErrFrameNotFound
+	}
+	rowLabel := f.RowLabel()
+
+	rowID, ok, err := c.UintArg(rowLabel)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading %s() row: %v", c.Name, err)
+	} else if !ok {
+		return nil, 0, fmt.Errorf("%s() row field '%v' required", c.Name, rowLabel)
+	}
+	return f, rowID, nil
+}
+
+// setFragmentRow replaces a row of a frame view's fragment with bm. Standard
+// views set the row and inverse views set the column with the same ID. The
+// fragment is only created if bm has bits set.
+func setFragmentRow(f *Frame, view string, slice, rowID uint64, bm *Bitmap) (bool, error) {
+	var frag *Fragment
+	if bm.Count() == 0 {
+		if v := f.View(view); v != nil {
+			frag = v.Fragment(slice)
+		}
+		if frag == nil {
+			return false, nil
+		}
+	} else {
+		v, err := f.CreateViewIfNotExists(view)
+		if err != nil {
+			return false, err
+		}
+		if frag, err = v.CreateFragmentIfNotExists(slice); err != nil {
+			return false, err
+		}
+	}
+
+	if IsInverseView(view) {
+		return frag.SetColumn(rowID, bm)
+	}
+	return frag.SetRow(rowID, bm)
+}
+
+// executeRowWrite runs mapFn on every replica of each slice and returns true
+// if any bit changed. The slice holding the row's inverse column is written
+// too if the frame has an inverse view.
+func (e *Executor) executeRowWrite(ctx context.Context, index string, c *pql.Call, f *Frame, rowID uint64, slices []uint64, opt *ExecOptions, mapFn mapFunc) (bool, error) {
+	reduceFn := func(prev, v interface{}) interface{} {
+		changed, _ := prev.(bool)
+		return changed || v.(bool)
+	}
+
+	// Remote nodes only write the slices they are sent.
+	if opt.Remote {
+		if len(slices) == 0 {
+			return false, nil
+		}
+		result, err := e.mapperLocal(ctx, slices, mapFn, reduceFn)
+		changed, _ := result.(bool)
+		return changed, err
+	}
+
+	if f.InverseEnabled() {
+		inverseSlice := rowID / f.SliceWidth()
+		found := false
+		for _, slice := range slices {
+			if slice == inverseSlice {
+				found = true
+				break
+			}
+		}
+		if !found {
+			slices = append(slices[:len(slices):len(slices)], inverseSlice)
+		}
+	}
+
+	// Group slices by every node which owns them so replicas are written too.
+	m := make(map[*Node][]uint64)
+	for _, slice := range slices {
+		for _, node := range e.Cluster.FragmentNodes(index, slice) {
+			m[node] = append(m[node], slice)
+		}
+	}
+
+	// Write to each node concurrently.
+	ch := make(chan mapResponse, len(m))
+	for n, nodeSlices := range m {
+		go func(n *Node, nodeSlices []uint64) {
+			resp := mapResponse{node: n, slices: nodeSlices}
+			if n.Host == e.Host {
+				resp.result, resp.err = e.mapperLocal(ctx, nodeSlices, mapFn, reduceFn)
+			} else {
+				results, err := e.exec(ctx, n, index, &pql.Query{Calls: []*pql.Call{c}}, nodeSlices, opt)
+				if len(results) > 0 {
+					resp.result = results[0]
+				}
+				resp.err = err
+			}
+			ch <- resp
+		}(n, nodeSlices)
+	}
+
+	// Return the first error after all nodes have responded.
+	var result interface{}
+	var err error
+	for range m {
+		resp := <-ch
+		if resp.err != nil {
+			if err == nil {
+				err = resp.err
+			}
+			continue
+		}
+		result = reduceFn(result, resp.result)
+	}
+	changed, _ := result.(bool)
+	return changed, err
+}
+
 // executeSetRowAttrs executes a SetRowAttrs() call.
 func (e *Executor) executeSetRowAttrs(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) error {
 	frameName, ok := c.Args["frame"].(string)
@@ -1001,9 +1593,10 @@ func (e *Executor) executeSetColumnAttrs(ctx context.Context, index string, c *p
 func (e *Executor) exec(ctx context.Context, node *Node, index string, q *pql.Query, slices []uint64, opt *ExecOptions) (results []interface{}, err error) {
 	// Encode request object.
 	pbreq := &internal.QueryRequest{
-		Query:  q.String(),
-		Slices: slices,
-		Remote: true,
+		Query:      q.String(),
+		Slices:     slices,
+		Remote:     true,
+		ReadRepair: opt != nil && opt.readRepair,
 	}
 	buf, err := proto.Marshal(pbreq)
 	if err != nil {
@@ -1012,7 +1605,7 @@ func (e *Executor) exec(ctx context.Context, node *Node, index string, q *pql.Qu
 
 	// Create HTTP request.
 	req, err := http.NewRequest("POST", (&url.URL{
-		Scheme: "http",
+		Scheme: e.Cluster.scheme(),
 		Host:   node.Host,
 		Path:   fmt.Sprintf("/index/%s/query", index),
 	}).String(), bytes.NewReader(buf))
@@ -1064,10 +1657,14 @@ func (e *Executor) exec(ctx context.Context, node *Node, index string, q *pql.Qu
 			v, err = decodePairs(pb.Results[i].GetPairs()), nil
 		case "Count":
 			v, err = pb.Results[i].N, nil
+		case "CountByTime":
+			v, err = decodeTimeCounts(pb.Results[i].GetTimeCounts()), nil
 		case "SetBit":
 			v, err = pb.Results[i].Changed, nil
 		case "ClearBit":
 			v, err = pb.Results[i].Changed, nil
+		case "ClearRow", "SetRow", "Store":
+			v, err = pb.Results[i].Changed, nil
 		case "SetRowAttrs":
 		case "SetColumnAttrs":
 		default:
@@ -1083,6 +1680,7 @@ func (e *Executor) exec(ctx context.Context, node *Node, index string, q *pql.Qu
 }
 
 // slicesByNode returns a mapping of nodes to slices.
+// Remote nodes known to be down are skipped in favor of other replicas.
 // Returns errSliceUnavailable if a slice cannot be allocated to a node.
 func (e *Executor) slicesByNode(nodes []*Node, index string, slices []uint64) (map[*Node][]uint64, error) {
 	m := make(map[*Node][]uint64)
@@ -1090,6 +1688,9 @@ func (e *Executor) slicesByNode(nodes []*Node, index string, slices []uint64) (m
 loop:
 	for _, slice := range slices {
 		for _, node := range e.Cluster.FragmentNodes(index, slice) {
+			if node.Host != e.Host && e.Cluster.NodeState(node.Host) == NodeStateDown {
+				continue
+			}
 			if Nodes(nodes).Contains(node) {
 				m[node] = append(m[node], slice)
 				continue loop
@@ -1177,6 +1778,9 @@ func (e *Executor) mapper(ctx context.Context, ch chan mapResponse, nodes []*Nod
 			// Send local slices to mapper, otherwise remote exec.
 			if n.Host == e.Host {
 				resp.result, resp.err = e.mapperLocal(ctx, nodeSlices, mapFn, reduceFn)
+				if resp.err == nil && opt.readRepair {
+					e.readRepair(index, c, nodeSlices)
+				}
 			} else if !opt.Remote {
 
 				results, err := e.exec(ctx, n, index, &pql.Query{Calls: []*pql.Call{c}}, nodeSlices, opt)
@@ -1253,6 +1857,128 @@ type mapResponse struct {
 // ExecOptions represents an execution context for a single Execute() call.
 type ExecOptions struct {
 	Remote bool
+
+	// Set if the query was sampled for read repair. Sent to remote nodes so
+	// each node repairs the slices it reads.
+	readRepair bool
+}
+
+// readRepairRequest identifies the local fragments to compare against the
+// other replicas after a query.
+type readRepairRequest struct {
+	index  string
+	blocks map[string][]int // blocks by frame; nil compares all blocks
+	slices []uint64
+}
+
+// readRepair queues a comparison of the blocks read by c in the local
+// fragments against the other replicas. The repair is skipped if the queue
+// is full.
+func (e *Executor) readRepair(index string, c *pql.Call, slices []uint64) {
+	req := readRepairRequest{
+		index:  index,
+		blocks: e.readRepairBlocks(index, c),
+		slices: slices,
+	}
+
+	select {
+	case e.readRepairs <- req:
+	default:
+	}
+}
+
+// ProcessReadRepairs repairs queued fragments until closing is closed.
+// Only blocks whose checksums differ between replicas are synchronized.
+func (e *Executor) ProcessReadRepairs(closing <-chan struct{}) {
+	for {
+		select {
+		case <-closing:
+			return
+		case req := <-e.readRepairs:
+			e.processReadRepair(req, closing)
+		}
+	}
+}
+
+func (e *Executor) processReadRepair(req readRepairRequest, closing <-chan struct{}) {
+	for frame, blocks := range req.blocks {
+		for _, slice := range req.slices {
+			frag := e.Holder.Fragment(req.index, frame, ViewStandard, slice)
+			if frag == nil {
+				continue
+			}
+
+			syncer := FragmentSyncer{
+				Fragment: frag,
+				Host:     e.Host,
+				Cluster:  e.Cluster,
+				Closing:  closing,
+			}
+			if err := syncer.SyncBlocks(blocks); err != nil {
+				e.Holder.logger().Printf("read repair error: index=%s, frame=%s, slice=%d, err=%s", req.index, frame, slice, err)
+				return
+			}
+			frag.stats.Count("readRepairN", 1)
+
+			if syncer.isClosing() {
+				return
+			}
+		}
+	}
+}
+
+// readRepairBlocks returns the blocks of each frame read by c and its
+// children. Bitmap() calls for a single row only read the row's block.
+// Other calls map their frame to nil so that all blocks are compared.
+func (e *Executor) readRepairBlocks(index string, c *pql.Call) map[string][]int {
+	m := make(map[string][]int)
+
+	var fn func(*pql.Call)
+	fn = func(c *pql.Call) {
+		frame, _ := c.Args["frame"].(string)
+		switch c.Name {
+		case "Bitmap", "Range", "TopN":
+			if frame == "" {
+				frame = DefaultFrame
+			}
+		}
+
+		if frame != "" {
+			// Determine the row read by Bitmap() calls on the standard view.
+			var rowID uint64
+			var hasRow bool
+			if f := e.Holder.Frame(index, frame); f != nil && c.Name == "Bitmap" {
+				rowID, hasRow, _ = c.UintArg(f.RowLabel())
+			}
+
+			blocks, ok := m[frame]
+			switch {
+			case ok && blocks == nil:
+				// All blocks are already compared.
+			case hasRow:
+				m[frame] = appendBlockID(blocks, int(rowID/HashBlockSize))
+			default:
+				m[frame] = nil
+			}
+		}
+
+		for _, child := range c.Children {
+			fn(child)
+		}
+	}
+	fn(c)
+
+	return m
+}
+
+// appendBlockID appends id to ids if it is not already present.
+func appendBlockID(ids []int, id int) []int {
+	for _, v := range ids {
+		if v == id {
+			return ids
+		}
+	}
+	return append(ids, id)
 }
 
 // decodeError returns an error representation of s if s is non-blank.
@@ -1278,6 +2004,17 @@ func hasOnlySetRowAttrs(calls []*pql.Call) bool {
 	return true
 }
 
+// hasWriteCalls returns true if calls contains any call which modifies data.
+func hasWriteCalls(calls []*pql.Call) bool {
+	for _, call := range calls {
+		switch call.Name {
+		case "ClearBit", "ClearRow", "SetBit", "SetRow", "SetRowAttrs", "SetColumnAttrs", "Store":
+			return true
+		}
+	}
+	return false
+}
+
 func needsSlices(calls []*pql.Call) bool {
 	if len(calls) == 0 {
 		return false
@@ -16,10 +16,14 @@ package pilosa_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/pilosa/pilosa"
@@ -233,6 +237,252 @@ func TestExecutor_Execute_SetBit(t *testing.T) {
 	}
 }
 
+// Ensure a ClearRow() query can be executed.
+func TestExecutor_Execute_ClearRow(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(``+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 10, 3)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 10, SliceWidth+1)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 11, 3),
+	), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ClearRow(frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !res[0].(bool) {
+		t.Fatal("expected change")
+	}
+
+	// Verify the row is cleared in both views and removed from the rank cache.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(columnID=3, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{11}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res[0], []pilosa.Pair{{ID: 11, Count: 1}}) {
+		t.Fatalf("unexpected pairs: %+v", res[0])
+	}
+
+	// Clearing an empty row does not change anything.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ClearRow(frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0].(bool) {
+		t.Fatal("expected no change")
+	}
+}
+
+// Ensure a SetRow() query can be executed.
+func TestExecutor_Execute_SetRow(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(``+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 10, 3)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 10, SliceWidth+1)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 11, 1)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 11, 3)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 11, (2*SliceWidth)+5),
+	), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetRow(Bitmap(frame=f, rowID=11), frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !res[0].(bool) {
+		t.Fatal("expected change")
+	}
+
+	// Verify the row in both views and the rank cache.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1, 3, (2 * SliceWidth) + 5}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(columnID=1, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{10, 11}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`Bitmap(columnID=%d, frame=f)`, SliceWidth+1)), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, ids=[10])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res[0], []pilosa.Pair{{ID: 10, Count: 3}}) {
+		t.Fatalf("unexpected pairs: %+v", res[0])
+	}
+
+	// Setting the same row again does not change anything.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetRow(Bitmap(frame=f, rowID=11), frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0].(bool) {
+		t.Fatal("expected no change")
+	}
+
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRow(frame=f, rowID=10)`), nil, nil); err == nil || err.Error() != "SetRow() requires an input bitmap" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a SetRow() query on a mutex frame clears the row's columns from
+// other rows in both views, including other slices of the inverse view.
+func TestExecutor_Execute_SetRow_Mutex(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{Mutex: true, InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	} else if _, err := index.CreateFrame("g", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(``+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 1, 3)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", SliceWidth+1, 5)+
+		fmt.Sprintf("SetBit(frame=g, rowID=%d, columnID=%d)\n", 0, 3)+
+		fmt.Sprintf("SetBit(frame=g, rowID=%d, columnID=%d)\n", 0, 5),
+	), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetRow(Bitmap(frame=g, rowID=0), frame=f, rowID=2)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !res[0].(bool) {
+		t.Fatal("expected change")
+	}
+
+	for _, tt := range []struct {
+		q    string
+		bits []uint64
+	}{
+		{`Bitmap(rowID=1, frame=f)`, []uint64{}},
+		{fmt.Sprintf(`Bitmap(rowID=%d, frame=f)`, SliceWidth+1), []uint64{}},
+		{`Bitmap(rowID=2, frame=f)`, []uint64{3, 5}},
+		{`Bitmap(columnID=3, frame=f)`, []uint64{2}},
+		{`Bitmap(columnID=5, frame=f)`, []uint64{2}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, tt.bits) {
+			t.Fatalf("unexpected bits for %s: %+v", tt.q, bits)
+		}
+	}
+}
+
+// Ensure a SetRow() query leaves the time views unchanged while a ClearRow()
+// query clears them.
+func TestExecutor_Execute_SetRow_TimeViews(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{TimeQuantum: pilosa.TimeQuantum("YMD")})
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := index.CreateFrame("g", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	f.MustSetBit(pilosa.ViewStandard, 10, 1, MustParseTimePtr("2000-01-01 00:00"))
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(frame=g, rowID=0, columnID=2)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := e.Execute(context.Background(), "i", MustParse(`SetRow(Bitmap(frame=g, rowID=0), frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rangeQuery := `Range(rowID=10, frame=f, start="2000-01-01T00:00", end="2000-01-02T00:00")`
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(rangeQuery), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected range bits: %+v", bits)
+	}
+
+	if _, err := e.Execute(context.Background(), "i", MustParse(`ClearRow(frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(rangeQuery), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected range bits: %+v", bits)
+	}
+}
+
+// Ensure a Store() query can materialize a bitmap expression into a row.
+func TestExecutor_Execute_Store(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	} else if _, err := index.CreateFrame("segments", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(``+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 10, 1)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 10, SliceWidth+2)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 11, 3)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 12, 1)+
+		fmt.Sprintf("SetBit(frame=segments, rowID=%d, columnID=%d)\n", 1, 5),
+	), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	q := `Store(Difference(Union(Bitmap(frame=f, rowID=10), Bitmap(frame=f, rowID=11)), Bitmap(frame=f, rowID=12)), frame=segments, rowID=1)`
+	if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !res[0].(bool) {
+		t.Fatal("expected change")
+	}
+
+	// Verify the stored row replaced the existing row.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(frame=segments, rowID=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{3, SliceWidth + 2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Storing the same result again does not change anything.
+	if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0].(bool) {
+		t.Fatal("expected no change")
+	}
+
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Store(frame=segments, rowID=1)`), nil, nil); err == nil || err.Error() != "Store() requires an input bitmap" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure a SetRowAttrs() query can be executed.
 func TestExecutor_Execute_SetRowAttrs(t *testing.T) {
 	hldr := MustOpenHolder()
@@ -469,6 +719,265 @@ func TestExecutor_Execute_Range(t *testing.T) {
 	}
 }
 
+// Ensure a range query can be open-ended or use relative & zoned times.
+func TestExecutor_Execute_Range_OpenEnded(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{TimeQuantum: pilosa.TimeQuantum("YMDH")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("1999-12-31 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 3, MustParseTimePtr("2000-01-01 05:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 4, &now)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q    string
+		bits []uint64
+	}{
+		{`Range(rowID=1, frame=f, end="2000-01-01T00:00")`, []uint64{2}},
+		{`Range(rowID=1, frame=f, start="2000-01-01T00:00")`, []uint64{3, 4}},
+		{`Range(rowID=1, frame=f, start="2000-01-01T00:00:00-05:00", end="-7d")`, []uint64{3}},
+		{`Range(rowID=1, frame=f, start="-7d", end="now")`, []uint64{4}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, tt.bits) {
+			t.Fatalf("%s: unexpected bits: %+v", tt.q, bits)
+		}
+	}
+}
+
+// Ensure a bitmap query can be limited to a time range.
+func TestExecutor_Execute_Bitmap_TimeRange(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{InverseEnabled: true, TimeQuantum: pilosa.TimeQuantum("YMD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Set bits in both orientations.
+	for _, view := range []string{pilosa.ViewStandard, pilosa.ViewInverse} {
+		f.MustSetBit(view, 1, 2, MustParseTimePtr("2000-01-01 00:00"))
+		f.MustSetBit(view, 1, 3, MustParseTimePtr("2000-01-05 00:00"))
+		f.MustSetBit(view, 1, 4, MustParseTimePtr("2000-02-01 00:00")) // too late
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	t.Run("Row", func(t *testing.T) {
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=1, frame=f, start="2000-01-01T00:00", end="2000-01-10T00:00")`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2, 3}) {
+			t.Fatalf("unexpected bits: %+v", bits)
+		}
+	})
+
+	t.Run("Column", func(t *testing.T) {
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(columnID=1, frame=f, start="2000-01-01T00:00", end="2000-01-10T00:00")`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2, 3}) {
+			t.Fatalf("unexpected bits: %+v", bits)
+		}
+	})
+
+	t.Run("ErrInvalidTime", func(t *testing.T) {
+		if _, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=1, frame=f, start="yesterday")`), nil, nil); err == nil || err.Error() != "cannot parse Bitmap() start: yesterday" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// Ensure a TopN query can be limited to a time range.
+func TestExecutor_Execute_TopN_TimeRange(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{TimeQuantum: pilosa.TimeQuantum("YMD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Row 0 has the most bits overall but row 10 has the most within the range.
+	f.MustSetBit(pilosa.ViewStandard, 0, 1, MustParseTimePtr("1999-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 0, 2, MustParseTimePtr("1999-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 0, 3, MustParseTimePtr("1999-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 0, 4, MustParseTimePtr("2000-01-02 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 10, 1, MustParseTimePtr("2000-01-02 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 10, 2, MustParseTimePtr("2000-01-03 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 10, SliceWidth, MustParseTimePtr("2000-01-04 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 20, 5, MustParseTimePtr("2000-02-01 00:00"))
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2, start="2000-01-01T00:00", end="2000-01-10T00:00")`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
+		{ID: 10, Count: 3},
+		{ID: 0, Count: 1},
+	}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+}
+
+// Ensure a TopN query can weight recent time views more than older ones.
+func TestExecutor_Execute_TopN_HalfLife(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{TimeQuantum: pilosa.TimeQuantum("D")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Row 0 has the most bits but they are much older than rows 10 & 20.
+	for _, columnID := range []uint64{1, 2, 3, 4} {
+		f.MustSetBit(pilosa.ViewStandard, 0, columnID, MustParseTimePtr("2000-01-01 00:00"))
+	}
+	for _, columnID := range []uint64{1, 2, SliceWidth} {
+		f.MustSetBit(pilosa.ViewStandard, 10, columnID, MustParseTimePtr("2000-01-10 00:00"))
+	}
+	f.MustSetBit(pilosa.ViewStandard, 20, 1, MustParseTimePtr("2000-01-10 00:00"))
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	t.Run("OK", func(t *testing.T) {
+		if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2, start="2000-01-01T00:00", end="2000-01-11T00:00", halfLife="1d")`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
+			{ID: 10, Count: 2},
+			{ID: 20, Count: 1},
+		}) {
+			t.Fatalf("unexpected result: %s", spew.Sdump(result))
+		}
+	})
+
+	// The half-life can be given as an integer number of seconds.
+	t.Run("Seconds", func(t *testing.T) {
+		if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2, start="2000-01-01T00:00", end="2000-01-11T00:00", halfLife=86400)`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
+			{ID: 10, Count: 2},
+			{ID: 20, Count: 1},
+		}) {
+			t.Fatalf("unexpected result: %s", spew.Sdump(result))
+		}
+	})
+
+	t.Run("ErrInvalidHalfLife", func(t *testing.T) {
+		for _, v := range []string{`0`, `"0d"`, `"fortnight"`, `[1]`} {
+			if _, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2, start="2000-01-01T00:00", end="2000-01-11T00:00", halfLife=`+v+`)`), nil, nil); err == nil || !strings.HasPrefix(err.Error(), "TopN() invalid halfLife") {
+				t.Fatalf("unexpected error: halfLife=%s, err=%v", v, err)
+			}
+		}
+	})
+
+	// Decayed counts are summed across slices before rounding.
+	t.Run("Slices", func(t *testing.T) {
+		for _, columnID := range []uint64{1, SliceWidth + 1, 2*SliceWidth + 1} {
+			f.MustSetBit(pilosa.ViewStandard, 30, columnID, MustParseTimePtr("2000-01-09 00:00"))
+		}
+
+		if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, ids=[30], start="2000-01-01T00:00", end="2000-01-11T00:00", halfLife="1d")`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
+			{ID: 30, Count: 1},
+		}) {
+			t.Fatalf("unexpected result: %s", spew.Sdump(result))
+		}
+	})
+
+	t.Run("ErrEndRequired", func(t *testing.T) {
+		if _, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2, start="2000-01-01T00:00", halfLife="1d")`), nil, nil); err == nil || err.Error() != "TopN() end time required with halfLife" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// Ensure a query can count a bitmap by time interval.
+func TestExecutor_Execute_CountByTime(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{TimeQuantum: pilosa.TimeQuantum("YMDH")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.MustSetBit(pilosa.ViewStandard, 1, 1, MustParseTimePtr("2000-01-01 03:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2000-01-01 23:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 3, MustParseTimePtr("2000-01-03 10:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, SliceWidth, MustParseTimePtr("2000-01-03 11:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 4, MustParseTimePtr("2000-01-04 00:00")) // too late
+	f.MustSetBit(pilosa.ViewStandard, 2, 5, MustParseTimePtr("2000-01-02 00:00")) // different row
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	t.Run("Day", func(t *testing.T) {
+		if res, err := e.Execute(context.Background(), "i", MustParse(`CountByTime(Bitmap(rowID=1, frame=f), frame=f, start="2000-01-01T00:00", end="2000-01-04T00:00", interval=day)`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res[0], []pilosa.TimeCount{
+			{Time: MustParseTime("2000-01-01 00:00"), Count: 2},
+			{Time: MustParseTime("2000-01-02 00:00"), Count: 0},
+			{Time: MustParseTime("2000-01-03 00:00"), Count: 2},
+		}) {
+			t.Fatalf("unexpected result: %s", spew.Sdump(res))
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		// The buckets are narrowed by the Range() call's own time range.
+		if res, err := e.Execute(context.Background(), "i", MustParse(`CountByTime(Union(Range(rowID=1, frame=f, start="2000-01-01T12:00", end="2000-01-03T11:00"), Range(rowID=2, frame=f, start="2000-01-01T00:00", end="2000-01-03T00:00")), frame=f, start="2000-01-01T00:00", end="2000-01-04T00:00", interval=day)`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res[0], []pilosa.TimeCount{
+			{Time: MustParseTime("2000-01-01 00:00"), Count: 1},
+			{Time: MustParseTime("2000-01-02 00:00"), Count: 1},
+			{Time: MustParseTime("2000-01-03 00:00"), Count: 1},
+		}) {
+			t.Fatalf("unexpected result: %s", spew.Sdump(res))
+		}
+	})
+
+	t.Run("ErrInterval", func(t *testing.T) {
+		if _, err := e.Execute(context.Background(), "i", MustParse(`CountByTime(Bitmap(rowID=1, frame=f), frame=f, start="2000-01-01T00:00", end="2000-01-04T00:00", interval=fortnight)`), nil, nil); err == nil || err.Error() != `invalid time interval: "fortnight"` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrIntervalQuantum", func(t *testing.T) {
+		if _, err := index.CreateFrameIfNotExists("d", pilosa.FrameOptions{TimeQuantum: pilosa.TimeQuantum("D")}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Execute(context.Background(), "i", MustParse(`CountByTime(Bitmap(rowID=1, frame=d), frame=d, start="2000-01-01T00:00", end="2000-01-04T00:00", interval=hour)`), nil, nil); err == nil || err.Error() != `CountByTime() interval hour is not made up of time quantum D units` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Weeks do not nest within months.
+		if _, err := index.CreateFrameIfNotExists("ym", pilosa.FrameOptions{TimeQuantum: pilosa.TimeQuantum("YM")}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Execute(context.Background(), "i", MustParse(`CountByTime(Bitmap(rowID=1, frame=ym), frame=ym, start="2000-01-01T00:00", end="2000-02-01T00:00", interval=week)`), nil, nil); err == nil || err.Error() != `CountByTime() interval week is not made up of time quantum YM units` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrNoTimeQuantum", func(t *testing.T) {
+		if _, err := index.CreateFrameIfNotExists("n", pilosa.FrameOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Execute(context.Background(), "i", MustParse(`CountByTime(Bitmap(rowID=1, frame=n), frame=n, start="2000-01-01T00:00", end="2000-01-04T00:00", interval=day)`), nil, nil); err == nil || err.Error() != `CountByTime() frame has no time quantum` {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
 // Ensure a remote query can return a bitmap.
 func TestExecutor_Execute_Remote_Bitmap(t *testing.T) {
 	c := NewCluster(2)
@@ -539,6 +1048,200 @@ func TestExecutor_Execute_Remote_Count(t *testing.T) {
 	}
 }
 
+// Ensure a remote query can return counts by time.
+func TestExecutor_Execute_Remote_CountByTime(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to return counts by time.
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{[]pilosa.TimeCount{
+			{Time: MustParseTime("2000-01-01 00:00"), Count: 3},
+			{Time: MustParseTime("2000-01-02 00:00"), Count: 4},
+		}}, nil
+	}
+
+	// Create local executor data. The local node owns slice 2.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	f := hldr.MustCreateFrameIfNotExists("i", "f")
+	if err := f.SetTimeQuantum(pilosa.TimeQuantum("D")); err != nil {
+		t.Fatal(err)
+	}
+	f.MustSetBit(pilosa.ViewStandard, 10, (2*SliceWidth)+1, MustParseTimePtr("2000-01-02 05:00"))
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`CountByTime(Bitmap(rowID=10, frame=f), frame=f, start="2000-01-01T00:00", end="2000-01-03T00:00", interval=day)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res[0], []pilosa.TimeCount{
+		{Time: MustParseTime("2000-01-01 00:00"), Count: 3},
+		{Time: MustParseTime("2000-01-02 00:00"), Count: 5},
+	}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure a query skips replicas that are known to be down.
+func TestExecutor_Execute_Remote_SkipDownNode(t *testing.T) {
+	c := NewCluster(2)
+	c.ReplicaN = 2
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// The secondary node should never be queried.
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		t.Fatalf("unexpected remote execution: slices=%v", slices)
+		return nil, nil
+	}
+
+	// Mark the secondary node as down by failing all health checks.
+	ns := pilosa.NewStaticNodeSet()
+	ns.FailureN = 1
+	ns.HTTPClient = &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+	ns.Join(c.Nodes)
+	ns.CheckHealth()
+	c.NodeSet = ns
+
+	// Create local executor data. The local node is a replica of every slice.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(2) {
+		t.Fatalf("unexpected n: %d", res[0])
+	}
+}
+
+// Ensure a sampled query repairs differences between replicas in the background.
+func TestExecutor_Execute_ReadRepair(t *testing.T) {
+	c := NewCluster(2)
+	c.ReplicaN = 2
+	c.Hasher = NewConstHasher(0)
+
+	// Create a remote holder wrapped by an HTTP server.
+	hldr1 := MustOpenHolder()
+	defer hldr1.Close()
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+	s.Handler.Holder = hldr1.Holder
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		e := pilosa.NewExecutor()
+		e.Holder = hldr1.Holder
+		e.Host = c.Nodes[1].Host
+		e.Cluster = c
+		return e.Execute(ctx, index, query, slices, opt)
+	}
+
+	// Create local holder. The local node is the primary for every slice.
+	hldr0 := MustOpenHolder()
+	defer hldr0.Close()
+
+	// Set differing bits on each replica.
+	hldr0.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+	hldr1.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 2)
+
+	e := NewExecutor(hldr0.Holder, c)
+	e.ReadRepairRate = 1
+
+	// Process repairs in the background until the test completes.
+	closing := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); e.ProcessReadRepairs(closing) }()
+	defer wg.Wait()
+	defer close(closing)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Wait for the background repair to converge both replicas.
+	for i := 0; ; i++ {
+		a0 := hldr0.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits()
+		a1 := hldr1.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits()
+		if reflect.DeepEqual(a0, []uint64{1, 2}) && reflect.DeepEqual(a1, []uint64{1, 2}) {
+			break
+		} else if i == 100 {
+			t.Fatalf("replicas not repaired: local=%v, remote=%v", a0, a1)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Ensure a sampled query repairs the slices read by remote nodes.
+func TestExecutor_Execute_ReadRepair_Remote(t *testing.T) {
+	c := NewCluster(2)
+	c.ReplicaN = 2
+	c.Hasher = NewConstHasher(1)
+
+	// Create local & remote holders wrapped by HTTP servers. The remote node
+	// is the primary for every slice so the local node reads nothing.
+	hldr0, hldr1 := MustOpenHolder(), MustOpenHolder()
+	defer hldr0.Close()
+	defer hldr1.Close()
+	s0, s1 := NewServer(), NewServer()
+	defer s0.Close()
+	defer s1.Close()
+	c.Nodes[0].Host, c.Nodes[1].Host = s0.Host(), s1.Host()
+
+	e0 := pilosa.NewExecutor()
+	e0.Holder, e0.Host, e0.Cluster = hldr0.Holder, c.Nodes[0].Host, c
+	s0.Handler.Holder = hldr0.Holder
+	s0.Handler.Executor.ExecuteFn = e0.Execute
+
+	// Only the remote node processes its read repairs.
+	e1 := pilosa.NewExecutor()
+	e1.Holder, e1.Host, e1.Cluster = hldr1.Holder, c.Nodes[1].Host, c
+	s1.Handler.Holder = hldr1.Holder
+	s1.Handler.Executor.ExecuteFn = e1.Execute
+
+	closing := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); e1.ProcessReadRepairs(closing) }()
+	defer wg.Wait()
+	defer close(closing)
+
+	// Set differing bits on each replica.
+	hldr0.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+	hldr1.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 2)
+
+	e := NewExecutor(hldr0.Holder, c)
+	e.ReadRepairRate = 1
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Wait for the remote node's repair to converge both replicas.
+	for i := 0; ; i++ {
+		a0 := hldr0.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits()
+		a1 := hldr1.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits()
+		if reflect.DeepEqual(a0, []uint64{1, 2}) && reflect.DeepEqual(a1, []uint64{1, 2}) {
+			break
+		} else if i == 100 {
+			t.Fatalf("replicas not repaired: local=%v, remote=%v", a0, a1)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // Ensure a remote query can set bits on multiple nodes.
 func TestExecutor_Execute_Remote_SetBit(t *testing.T) {
 	c := NewCluster(2)
@@ -631,6 +1334,105 @@ func TestExecutor_Execute_Remote_SetBit_With_Timestamp(t *testing.T) {
 	}
 }
 
+// Ensure a ClearRow() query clears the row on every replica.
+func TestExecutor_Execute_Remote_ClearRow(t *testing.T) {
+	c := NewCluster(2)
+	c.ReplicaN = 2
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to verify arguments.
+	var remoteCalled bool
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if index != `i` {
+			t.Fatalf("unexpected index: %s", index)
+		} else if query.String() != `ClearRow(frame="f", rowID=10)` {
+			t.Fatalf("unexpected query: %s", query.String())
+		} else if !reflect.DeepEqual(slices, []uint64{0, 1}) {
+			t.Fatalf("unexpected slices: %+v", slices)
+		}
+		remoteCalled = true
+		return []interface{}{false}, nil
+	}
+
+	// Create local executor data.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(20, SliceWidth+1)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ClearRow(frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !res[0].(bool) {
+		t.Fatal("expected change")
+	}
+
+	// Verify the row is cleared locally and the remote replica was written.
+	if n := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Count(); n != 0 {
+		t.Fatalf("unexpected local count(0): %d", n)
+	} else if n := hldr.Fragment("i", "f", pilosa.ViewStandard, 1).Row(10).Count(); n != 0 {
+		t.Fatalf("unexpected local count(1): %d", n)
+	} else if n := hldr.Fragment("i", "f", pilosa.ViewStandard, 1).Row(20).Count(); n != 1 {
+		t.Fatalf("unexpected local count(20): %d", n)
+	}
+	if !remoteCalled {
+		t.Fatalf("expected remote execution")
+	}
+}
+
+// Ensure a Store() query writes the result on every replica.
+func TestExecutor_Execute_Remote_Store(t *testing.T) {
+	c := NewCluster(2)
+	c.ReplicaN = 2
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to verify arguments.
+	var remoteCalled bool
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if index != `i` {
+			t.Fatalf("unexpected index: %s", index)
+		} else if query.String() != `Store(Bitmap(frame="f", rowID=10), frame="f", rowID=20)` {
+			t.Fatalf("unexpected query: %s", query.String())
+		} else if !reflect.DeepEqual(slices, []uint64{0, 1}) {
+			t.Fatalf("unexpected slices: %+v", slices)
+		}
+		remoteCalled = true
+		return []interface{}{true}, nil
+	}
+
+	// Create local executor data.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Store(Bitmap(frame=f, rowID=10), frame=f, rowID=20)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !res[0].(bool) {
+		t.Fatal("expected change")
+	}
+
+	// Verify the row is stored locally and the remote replica was written.
+	if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(20).Bits(); !reflect.DeepEqual(bits, []uint64{3}) {
+		t.Fatalf("unexpected local bits(0): %+v", bits)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 1).Row(20).Bits(); !reflect.DeepEqual(bits, []uint64{SliceWidth + 1}) {
+		t.Fatalf("unexpected local bits(1): %+v", bits)
+	}
+	if !remoteCalled {
+		t.Fatalf("expected remote execution")
+	}
+}
+
 // Ensure a remote query can return a top-n query.
 func TestExecutor_Execute_Remote_TopN(t *testing.T) {
 	c := NewCluster(2)
@@ -706,6 +1508,11 @@ func NewExecutor(holder *pilosa.Holder, cluster *pilosa.Cluster) *Executor {
 	return e
 }
 
+// roundTripperFunc implements http.RoundTripper with a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
 // MustParse parses s into a PQL query. Panic on error.
 func MustParse(s string) *pql.Query {
 	q, err := pql.NewParser(strings.NewReader(s)).Parse()
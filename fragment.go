@@ -16,7 +16,6 @@ package pilosa
 
 import (
 	"archive/tar"
-	"bufio"
 	"bytes"
 	"container/heap"
 	"context"
@@ -31,9 +30,7 @@ import (
 	"os"
 	"sort"
 	"sync"
-	"syscall"
 	"time"
-	"unsafe"
 
 	"math"
 
@@ -43,18 +40,31 @@ import (
 )
 
 const (
-	// SliceWidth is the number of column IDs in a slice.
+	// SliceWidth is the default number of column IDs in a slice. It is also
+	// the width of the segments in a Bitmap, regardless of the index.
 	SliceWidth = 1048576
 
 	// SnapshotExt is the file extension used for an in-process snapshot.
 	SnapshotExt = ".snapshotting"
 
+	// BackgroundSnapshotExt is the file extension used for a snapshot
+	// written in the background.
+	BackgroundSnapshotExt = ".background"
+
+	// SegmentExt is the file extension for the op log that receives writes
+	// while a background snapshot is in progress.
+	SegmentExt = ".segment"
+
 	// CopyExt is the file extension used for the temp file used while copying.
 	CopyExt = ".copying"
 
 	// CacheExt is the file extension for persisted cache ids.
 	CacheExt = ".cache"
 
+	// QuarantineExt is the file extension for a copy of a data file
+	// whose corrupt op log was truncated.
+	QuarantineExt = ".corrupt"
+
 	// HashBlockSize is the number of rows in a merkle hash block.
 	HashBlockSize = 100
 )
@@ -62,11 +72,16 @@ const (
 const (
 	// DefaultFragmentMaxOpN is the default value for Fragment.MaxOpN.
 	DefaultFragmentMaxOpN = 2000
+
+	// DefaultFragmentRowCacheMaxBytes is the default value for
+	// Fragment.RowCacheMaxBytes.
+	DefaultFragmentRowCacheMaxBytes = 16 << 20
 )
 
 // Fragment represents the intersection of a frame and slice in an index.
 type Fragment struct {
-	mu sync.Mutex
+	// Readers share the lock while writers hold it exclusively.
+	mu sync.RWMutex
 
 	// Composite identifiers
 	index string
@@ -74,12 +89,30 @@ type Fragment struct {
 	view  string
 	slice uint64
 
-	// File-backed storage
-	path        string
-	file        *os.File
-	storage     *roaring.Bitmap
-	storageData []byte
-	opN         int // number of ops since snapshot
+	// Number of column IDs in the slice. Set by the index.
+	sliceWidth uint64
+
+	// Storage bitmap & the backend it is persisted to.
+	path    string
+	storage *roaring.Bitmap
+	opN     int // number of ops since snapshot
+
+	// Writes waiting on the next group commit.
+	batch *commitBatch
+
+	// Set while storage is open. Fragments are opened on first access and
+	// may be closed by the holder's pool when idle.
+	opened bool
+	pool   *fragmentPool // set by view
+
+	// Background snapshot state. While a snapshot is running, the backend
+	// keeps ops separate from the data being snapshotted. The generation
+	// is incremented whenever storage is closed so that an in-flight
+	// snapshot knows to discard its result.
+	snapshotting  bool
+	snapshotGen   int
+	snapshotWG    sync.WaitGroup
+	snapshotQueue chan struct{} // limits concurrent snapshots; set by view
 
 	// Cache for row counts.
 	cacheType string // passed in by frame
@@ -87,16 +120,32 @@ type Fragment struct {
 	cacheSize uint32
 
 	// Cache containing full rows (not just counts).
-	rowCache BitmapCache
+	rowCache       BitmapCache
+	rowCacheBudget *bitmapCacheBudget // shared by the holder; set by view
 
 	// Cached checksums for each block.
-	checksums map[int][]byte
+	// Guarded by checksumMu while readers share f.mu.
+	checksums  map[int][]byte
+	checksumMu sync.Mutex
 
 	// Number of operations performed before performing a snapshot.
 	// This limits the size of fragments on the heap and flushes them to disk
 	// so that they can be mmapped and heap utilization can be kept low.
 	MaxOpN int
 
+	// Maximum number of bytes of rows cached by the fragment. Zero is unlimited.
+	RowCacheMaxBytes int64
+
+	// Fsync policy for the op log & snapshots.
+	Durability Durability
+
+	// Keeps at most one row set per column. Set by view.
+	mutex bool
+
+	// Storage backend. Defaults to a memory mapped file at the fragment's
+	// path. This is set by the parent view unless overridden for testing.
+	Backend StorageBackend
+
 	// Writer used for out-of-band log entries.
 	LogOutput io.Writer
 
@@ -118,9 +167,13 @@ func NewFragment(path, index, frame, view string, slice uint64) *Fragment {
 		cacheType: DefaultCacheType,
 		cacheSize: DefaultCacheSize,
 
+		sliceWidth: SliceWidth,
+
 		LogOutput: ioutil.Discard,
 		MaxOpN:    DefaultFragmentMaxOpN,
 
+		RowCacheMaxBytes: DefaultFragmentRowCacheMaxBytes,
+
 		stats: NopStatsClient,
 	}
 }
@@ -143,15 +196,26 @@ func (f *Fragment) View() string { return f.view }
 // Slice returns the slice the fragment was initialized with.
 func (f *Fragment) Slice() uint64 { return f.slice }
 
+// SliceWidth returns the number of column IDs in the fragment's slice.
+func (f *Fragment) SliceWidth() uint64 { return f.sliceWidth }
+
 // Cache returns the fragment's cache.
 // This is not safe for concurrent use.
-func (f *Fragment) Cache() Cache { return f.cache }
+func (f *Fragment) Cache() Cache {
+	if err := f.ensureOpen(); err != nil {
+		f.logger().Printf("fragment: error opening: path=%s, err=%s", f.path, err)
+	}
+	return f.cache
+}
 
 // Open opens the underlying storage.
 func (f *Fragment) Open() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	return f.open()
+}
 
+func (f *Fragment) open() error {
 	if err := func() error {
 		// Initialize storage in a function so we can close if anything goes wrong.
 		if err := f.openStorage(); err != nil {
@@ -172,67 +236,131 @@ func (f *Fragment) Open() error {
 		return err
 	}
 
+	f.opened = true
+	f.pool.touch(f)
 	return nil
 }
 
-// openStorage opens the storage bitmap.
-func (f *Fragment) openStorage() error {
-	// Create a roaring bitmap to serve as storage for the slice.
-	f.storage = roaring.NewBitmap()
-
-	// Open the data file to be mmap'd and used as an ops log.
-	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		return fmt.Errorf("open file: %s", err)
+// acquire opens the fragment if it has not been opened or has been evicted,
+// and marks it as recently used. Must be called while holding f.mu.
+func (f *Fragment) acquire() error {
+	if !f.opened {
+		return f.open()
 	}
-	f.file = file
+	f.pool.touch(f)
+	return nil
+}
 
-	// Lock the underlying file.
-	if err := syscall.Flock(int(f.file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
-		return fmt.Errorf("flock: %s", err)
-	}
+// ensureOpen opens the fragment if it is not open.
+func (f *Fragment) ensureOpen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.acquire()
+}
 
-	// If the file is empty then initialize it with an empty bitmap.
-	fi, err := f.file.Stat()
-	if err != nil {
-		return err
-	} else if fi.Size() == 0 {
-		if _, err := f.storage.WriteTo(f.file); err != nil {
-			return fmt.Errorf("init storage file: %s", err)
+// rlock acquires a read lock on the fragment, opening it first if needed.
+// The caller must release the lock with f.mu.RUnlock() on success.
+func (f *Fragment) rlock() error {
+	for {
+		f.mu.RLock()
+		if f.opened {
+			f.pool.touch(f)
+			return nil
 		}
+		f.mu.RUnlock()
 
-		fi, err = f.file.Stat()
-		if err != nil {
+		// Open the fragment and retry in case it is evicted in between.
+		if err := f.ensureOpen(); err != nil {
 			return err
 		}
 	}
+}
+
+// evict closes the fragment if it is open. It is reopened on next access.
+func (f *Fragment) evict() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.opened {
+		f.close()
+		f.stats.Count("evictN", 1)
+	}
+}
 
-	// Mmap the underlying file so it can be zero copied.
-	storageData, err := syscall.Mmap(int(f.file.Fd()), 0, int(fi.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+// openStorage opens the storage bitmap.
+func (f *Fragment) openStorage() error {
+	// Create a roaring bitmap to serve as storage for the slice.
+	f.storage = roaring.NewBitmap()
+
+	if f.Backend == nil {
+		f.Backend = NewMmapBackend(f.path, f.Durability)
+	}
+	data, err := f.Backend.Open()
 	if err != nil {
-		return fmt.Errorf("mmap: %s", err)
+		return err
 	}
-	f.storageData = storageData
 
-	// Advise the kernel that the mmap is accessed randomly.
-	if err := madvise(f.storageData, syscall.MADV_RANDOM); err != nil {
-		return fmt.Errorf("madvise: %s", err)
+	// If the backend is empty then initialize it with an empty bitmap.
+	if len(data) == 0 {
+		if err := f.Backend.Snapshot(f.writeStorage); err != nil {
+			return fmt.Errorf("init storage: %s", err)
+		} else if data, err = f.Backend.Open(); err != nil {
+			return err
+		}
 	}
 
-	// Attach the mmap file to the bitmap.
-	data := f.storageData
+	// Attach the data to the bitmap.
 	if err := f.storage.UnmarshalBinary(data); err != nil {
-		return fmt.Errorf("unmarshal storage: file=%s, err=%s", f.file.Name(), err)
+		// Trim a corrupt op log tail, such as from a torn write, and reopen.
+		if err, ok := err.(*roaring.OpLogError); ok {
+			return f.recoverStorage(err)
+		}
+		return fmt.Errorf("unmarshal storage: path=%s, err=%s", f.path, err)
 	}
 
-	// Attach the file to the bitmap to act as a write-ahead log.
-	f.storage.OpWriter = f.file
-	f.rowCache = &SimpleCache{make(map[uint64]*Bitmap)}
+	// Attach the backend to the bitmap to act as a write-ahead log.
+	f.storage.OpWriter = storageOpWriter{f.Backend}
+	f.resetRowCache()
 
 	return nil
 
 }
 
+// writeStorage writes the storage bitmap to w.
+func (f *Fragment) writeStorage(w io.Writer) error {
+	_, err := f.storage.WriteTo(w)
+	return err
+}
+
+// recoverStorage truncates the op log at the first invalid op and reopens
+// storage.
+func (f *Fragment) recoverStorage(opErr *roaring.OpLogError) error {
+	lostN := opErr.LostOpN()
+	f.logger().Printf("fragment: truncating corrupt op log: path=%s, offset=%d, lost=%d, err=%s", f.path, opErr.Offset, lostN, opErr.Err)
+
+	if err := f.closeStorage(); err != nil {
+		return fmt.Errorf("close storage: %s", err)
+	} else if err := f.Backend.Truncate(int64(opErr.Offset)); err != nil {
+		return err
+	}
+
+	f.stats.Count("opLog.recovered", 1)
+	f.stats.Count("opLog.lostOps", int64(lostN))
+
+	return f.openStorage()
+}
+
+// resetRowCache replaces the row cache with an empty cache.
+func (f *Fragment) resetRowCache() {
+	if f.rowCache != nil {
+		f.rowCache.Clear()
+	}
+
+	c := NewLRUBitmapCache(f.RowCacheMaxBytes)
+	c.budget = f.rowCacheBudget
+	c.stats = f.stats
+	f.rowCache = c
+}
+
 // openCache initializes the cache from row ids persisted to disk.
 func (f *Fragment) openCache() error {
 	// Determine cache type from frame name.
@@ -264,7 +392,6 @@ func (f *Fragment) openCache() error {
 	// Read in all rows by ID.
 	// This will cause them to be added to the cache.
 	for _, id := range pb.IDs {
-		//n := f.storage.CountRange(id*SliceWidth, (id+1)*SliceWidth)
 		n := f.row(id, true, true).Count()
 		f.cache.BulkAdd(id, n)
 	}
@@ -276,14 +403,20 @@ func (f *Fragment) openCache() error {
 // Close flushes the underlying storage, closes the file and unlocks it.
 func (f *Fragment) Close() error {
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	return f.close()
+	err := f.close()
+	f.mu.Unlock()
+
+	// Wait for an in-flight background snapshot to exit.
+	f.snapshotWG.Wait()
+	return err
 }
 
 func (f *Fragment) close() error {
 	// Flush cache if closing gracefully.
-	if err := f.flushCache(); err != nil {
-		f.logger().Printf("fragment: error flushing cache on close: err=%s, path=%s", err, f.path)
+	if f.opened {
+		if err := f.flushCache(); err != nil {
+			f.logger().Printf("fragment: error flushing cache on close: err=%s, path=%s", err, f.path)
+		}
 	}
 
 	// Close underlying storage.
@@ -291,38 +424,35 @@ func (f *Fragment) close() error {
 		f.logger().Printf("fragment: error closing storage: err=%s, path=%s", err, f.path)
 	}
 
-	// Remove checksums.
+	// Remove checksums & cached rows.
 	f.checksums = nil
+	if f.rowCache != nil {
+		f.rowCache.Clear()
+	}
+
+	f.opened = false
+	f.pool.remove(f)
 
 	return nil
 }
 
 func (f *Fragment) closeStorage() error {
-	// Clear the storage bitmap so it doesn't access the closed mmap.
+	// Clear the storage bitmap so it doesn't access the closed backend.
 	f.storage = roaring.NewBitmap()
 
-	// Unmap the file.
-	if f.storageData != nil {
-		if err := syscall.Munmap(f.storageData); err != nil {
-			return fmt.Errorf("munmap: %s", err)
-		}
-		f.storageData = nil
-	}
+	// Discard the result of any in-flight background snapshot.
+	f.snapshotGen++
+	f.snapshotting = false
 
-	// Flush file, unlock & close.
-	if f.file != nil {
-		if err := f.file.Sync(); err != nil {
-			return fmt.Errorf("sync: %s", err)
-		}
-		if err := syscall.Flock(int(f.file.Fd()), syscall.LOCK_UN); err != nil {
-			return fmt.Errorf("unlock: %s", err)
-		}
-		if err := f.file.Close(); err != nil {
-			return fmt.Errorf("close file: %s", err)
-		}
+	if f.Backend == nil {
+		return nil
 	}
 
-	return nil
+	// Any writes waiting on a group commit are durable after the backend
+	// syncs on close.
+	err := f.Backend.Close()
+	f.releaseBatch(err)
+	return err
 }
 
 // logger returns a logger instance for the fragment.nt.
@@ -330,8 +460,11 @@ func (f *Fragment) logger() *log.Logger { return log.New(f.LogOutput, "", log.Ls
 
 // Row returns a row by ID.
 func (f *Fragment) Row(rowID uint64) *Bitmap {
-	f.mu.Lock()
-	defer f.mu.Unlock()
+	if err := f.rlock(); err != nil {
+		f.logger().Printf("fragment: error opening: path=%s, err=%s", f.path, err)
+		return NewBitmap()
+	}
+	defer f.mu.RUnlock()
 	return f.row(rowID, true, true)
 }
 
@@ -345,19 +478,13 @@ func (f *Fragment) row(rowID uint64, checkRowCache bool, updateRowCache bool) *B
 
 	// Only use a subset of the containers.
 	// NOTE: The start & end ranges must be divisible by
-	data := f.storage.OffsetRange(f.slice*SliceWidth, rowID*SliceWidth, (rowID+1)*SliceWidth)
+	offset := f.slice * f.sliceWidth
+	data := f.storage.OffsetRange(offset, rowID*f.sliceWidth, (rowID+1)*f.sliceWidth)
 
 	// Reference bitmap subrange in storage.
 	// We Clone() data because otherwise bm will contains pointers to containers in storage.
 	// This causes unexpected results when we cache the row and try to use it later.
-	bm := &Bitmap{
-		segments: []BitmapSegment{{
-			data:     *data.Clone(),
-			slice:    f.slice,
-			writable: false,
-		}},
-	}
-	bm.InvalidateCount()
+	bm := newRangeBitmap(data.Clone(), offset, f.sliceWidth)
 
 	if updateRowCache {
 		f.rowCache.Add(rowID, bm)
@@ -370,8 +497,19 @@ func (f *Fragment) row(rowID uint64, checkRowCache bool, updateRowCache bool) *B
 // This updates both the on-disk storage and the in-cache bitmap.
 func (f *Fragment) SetBit(rowID, columnID uint64) (changed bool, err error) {
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	return f.setBit(rowID, columnID)
+	if err := f.acquire(); err != nil {
+		f.mu.Unlock()
+		return false, err
+	}
+	changed, err = f.setBit(rowID, columnID)
+	batch := f.batch
+	f.mu.Unlock()
+
+	// Wait for the write to be synced when using group commit.
+	if err != nil || !changed {
+		return changed, err
+	}
+	return changed, batch.wait()
 }
 
 func (f *Fragment) setBit(rowID, columnID uint64) (changed bool, err error) {
@@ -382,6 +520,18 @@ func (f *Fragment) setBit(rowID, columnID uint64) (changed bool, err error) {
 		return false, err
 	}
 
+	// Clear any other row set for the column in mutex fragments.
+	var cleared bool
+	if f.mutex {
+		rowIDs, columnIDs := f.mutexConflicts(rowID, columnID)
+		for i := range rowIDs {
+			if _, err := f.clearBit(rowIDs[i], columnIDs[i]); err != nil {
+				return false, err
+			}
+			cleared = true
+		}
+	}
+
 	// Write to storage.
 	if changed, err = f.storage.Add(pos); err != nil {
 		return false, err
@@ -389,7 +539,7 @@ func (f *Fragment) setBit(rowID, columnID uint64) (changed bool, err error) {
 
 	// Don't update the cache if nothing changed.
 	if !changed {
-		return changed, nil
+		return cleared, nil
 	}
 
 	// Invalidate block checksum.
@@ -400,12 +550,8 @@ func (f *Fragment) setBit(rowID, columnID uint64) (changed bool, err error) {
 		return false, err
 	}
 
-	// Get the row from row cache or fragment.storage.
-	bm := f.row(rowID, true, true)
-	bm.SetBit(columnID)
-
-	// Update the cache.
-	f.cache.Add(rowID, bm.Count())
+	// Update the cached row & count.
+	f.updateRow(rowID)
 
 	f.stats.Count("setN", 1)
 
@@ -416,8 +562,19 @@ func (f *Fragment) setBit(rowID, columnID uint64) (changed bool, err error) {
 // This updates both the on-disk storage and the in-cache bitmap.
 func (f *Fragment) ClearBit(rowID, columnID uint64) (bool, error) {
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	return f.clearBit(rowID, columnID)
+	if err := f.acquire(); err != nil {
+		f.mu.Unlock()
+		return false, err
+	}
+	changed, err := f.clearBit(rowID, columnID)
+	batch := f.batch
+	f.mu.Unlock()
+
+	// Wait for the write to be synced when using group commit.
+	if err != nil || !changed {
+		return changed, err
+	}
+	return changed, batch.wait()
 }
 
 func (f *Fragment) clearBit(rowID, columnID uint64) (changed bool, err error) {
@@ -446,33 +603,128 @@ func (f *Fragment) clearBit(rowID, columnID uint64) (changed bool, err error) {
 		return false, err
 	}
 
-	// Get the row from cache or fragment.storage.
-	bm := f.row(rowID, true, true)
-	bm.ClearBit(columnID)
-
-	// Update the cache.
-	f.cache.Add(rowID, bm.Count())
+	// Update the cached row & count.
+	f.updateRow(rowID)
 
 	f.stats.Count("clearN", 1)
 
 	return changed, nil
 }
 
+// mutexConflicts returns the bits which must be cleared before setting the bit
+// at rowID & columnID in a mutex fragment. Standard views keep one row per
+// column. Inverse views are transposed so they keep one column per row.
+func (f *Fragment) mutexConflicts(rowID, columnID uint64) (rowIDs, columnIDs []uint64) {
+	if IsInverseView(f.view) {
+		for _, id := range f.row(rowID, false, false).Bits() {
+			if id != columnID {
+				rowIDs = append(rowIDs, rowID)
+				columnIDs = append(columnIDs, id)
+			}
+		}
+		return rowIDs, columnIDs
+	}
+
+	for _, id := range f.columnRows(columnID) {
+		if id != rowID {
+			rowIDs = append(rowIDs, id)
+			columnIDs = append(columnIDs, columnID)
+		}
+	}
+	return rowIDs, columnIDs
+}
+
+// columnRows returns the IDs of the rows with a bit set in the column.
+// The storage is seeked from one set bit to the next row at the column's
+// offset so that only rows containing bits are visited.
+func (f *Fragment) columnRows(columnID uint64) []uint64 {
+	offset := columnID % f.sliceWidth
+
+	var rowIDs []uint64
+	itr := f.storage.Iterator()
+	for rowID := uint64(0); rowID < math.MaxUint64/f.sliceWidth; {
+		itr.Seek((rowID * f.sliceWidth) + offset)
+		v, eof := itr.Next()
+		if eof {
+			break
+		}
+
+		// Move to the row of the next bit. Skip to the following row if the
+		// bit is past the column's offset.
+		rowID = v / f.sliceWidth
+		if v%f.sliceWidth == offset {
+			rowIDs = append(rowIDs, rowID)
+			rowID++
+		} else if v%f.sliceWidth > offset {
+			rowID++
+		}
+	}
+	return rowIDs
+}
+
+// mutexImportBits returns the bits to import into a mutex fragment. If a
+// column is given several rows then the highest row ID is kept so that the
+// result does not depend on the order of the bits. Inverse views keep the
+// highest column ID for each row.
+func (f *Fragment) mutexImportBits(rowIDs, columnIDs []uint64) ([]uint64, []uint64) {
+	inverse := IsInverseView(f.view)
+
+	m := make(map[uint64]uint64, len(rowIDs))
+	for i := range rowIDs {
+		key, value := columnIDs[i], rowIDs[i]
+		if inverse {
+			key, value = rowIDs[i], columnIDs[i]
+		}
+		if prev, ok := m[key]; !ok || value > prev {
+			m[key] = value
+		}
+	}
+
+	bits := importBitSet{
+		rowIDs:    make([]uint64, 0, len(m)),
+		columnIDs: make([]uint64, 0, len(m)),
+	}
+	for key, value := range m {
+		if inverse {
+			key, value = value, key
+		}
+		bits.rowIDs = append(bits.rowIDs, value)
+		bits.columnIDs = append(bits.columnIDs, key)
+	}
+	sort.Sort(bits)
+
+	return bits.rowIDs, bits.columnIDs
+}
+
+// updateRow removes a changed row from the row cache and updates its count.
+// Cached rows are never modified in place since readers may still hold them.
+func (f *Fragment) updateRow(rowID uint64) {
+	f.rowCache.Remove(rowID)
+	f.cache.Add(rowID, f.storage.OffsetRange(f.slice*f.sliceWidth, rowID*f.sliceWidth, (rowID+1)*f.sliceWidth).Count())
+}
+
 // pos translates the row ID and column ID into a position in the storage bitmap.
 func (f *Fragment) pos(rowID, columnID uint64) (uint64, error) {
 	// Return an error if the column ID is out of the range of the fragment's slice.
-	minColumnID := f.slice * SliceWidth
-	if columnID < minColumnID || columnID >= minColumnID+SliceWidth {
+	minColumnID := f.slice * f.sliceWidth
+	if columnID < minColumnID || columnID >= minColumnID+f.sliceWidth {
 		return 0, errors.New("column out of bounds")
 	}
-	return Pos(rowID, columnID), nil
+
+	// Return an error if the row's range of positions overflows the storage.
+	if rowID >= math.MaxUint64/f.sliceWidth {
+		return 0, errors.New("row out of bounds")
+	}
+	return (rowID * f.sliceWidth) + (columnID % f.sliceWidth), nil
 }
 
 // ForEachBit executes fn for every bit set in the fragment.
 // Errors returned from fn are passed through.
 func (f *Fragment) ForEachBit(fn func(rowID, columnID uint64) error) error {
-	f.mu.Lock()
-	defer f.mu.Unlock()
+	if err := f.rlock(); err != nil {
+		return err
+	}
+	defer f.mu.RUnlock()
 
 	var err error
 	f.storage.ForEach(func(i uint64) {
@@ -482,7 +734,7 @@ func (f *Fragment) ForEachBit(fn func(rowID, columnID uint64) error) error {
 		}
 
 		// Invoke caller's function.
-		err = fn(i/SliceWidth, (f.slice*SliceWidth)+(i%SliceWidth))
+		err = fn(i/f.sliceWidth, (f.slice*f.sliceWidth)+(i%f.sliceWidth))
 	})
 	return err
 }
@@ -491,6 +743,10 @@ func (f *Fragment) ForEachBit(fn func(rowID, columnID uint64) error) error {
 // If opt.Src is specified then only rows which intersect src are returned.
 // If opt.FilterValues exist then the row attribute specified by field is matched.
 func (f *Fragment) Top(opt TopOptions) ([]Pair, error) {
+	if err := f.ensureOpen(); err != nil {
+		return nil, err
+	}
+
 	// Retrieve pairs. If no row ids specified then return from cache.
 	pairs := f.topBitmapPairs(opt.RowIDs)
 
@@ -627,8 +883,8 @@ func (f *Fragment) Top(opt TopOptions) ([]Pair, error) {
 func (f *Fragment) topBitmapPairs(rowIDs []uint64) []BitmapPair {
 	// If no specific rows are requested, retrieve top rows.
 	if len(rowIDs) == 0 {
-		f.mu.Lock()
-		defer f.mu.Unlock()
+		f.mu.RLock()
+		defer f.mu.RUnlock()
 		f.cache.Invalidate()
 		return f.cache.Top()
 	}
@@ -637,7 +893,10 @@ func (f *Fragment) topBitmapPairs(rowIDs []uint64) []BitmapPair {
 	pairs := make([]BitmapPair, 0, len(rowIDs))
 	for _, rowID := range rowIDs {
 		// Look up cache first, if available.
-		if n := f.cache.Get(rowID); n > 0 {
+		f.mu.RLock()
+		n := f.cache.Get(rowID)
+		f.mu.RUnlock()
+		if n > 0 {
 			pairs = append(pairs, BitmapPair{
 				ID:    rowID,
 				Count: n,
@@ -688,9 +947,12 @@ func (f *Fragment) Checksum() []byte {
 
 // BlockN returns the number of blocks in the fragment.
 func (f *Fragment) BlockN() int {
-	f.mu.Lock()
-	defer f.mu.Unlock()
-	return int(f.storage.Max() / (HashBlockSize * SliceWidth))
+	if err := f.rlock(); err != nil {
+		f.logger().Printf("fragment: error opening: path=%s, err=%s", f.path, err)
+		return 0
+	}
+	defer f.mu.RUnlock()
+	return int(f.storage.Max() / (HashBlockSize * f.sliceWidth))
 }
 
 // InvalidateChecksums clears all cached block checksums.
@@ -702,8 +964,15 @@ func (f *Fragment) InvalidateChecksums() {
 
 // Blocks returns info for all blocks containing data.
 func (f *Fragment) Blocks() []FragmentBlock {
-	f.mu.Lock()
-	defer f.mu.Unlock()
+	if err := f.rlock(); err != nil {
+		f.logger().Printf("fragment: error opening: path=%s, err=%s", f.path, err)
+		return nil
+	}
+	defer f.mu.RUnlock()
+
+	// Checksums are cached by concurrent readers.
+	f.checksumMu.Lock()
+	defer f.checksumMu.Unlock()
 
 	var a []FragmentBlock
 
@@ -719,16 +988,16 @@ func (f *Fragment) Blocks() []FragmentBlock {
 	if eof {
 		return nil
 	}
-	blockID := int(v / (HashBlockSize * SliceWidth))
+	blockID := int(v / (HashBlockSize * f.sliceWidth))
 	for {
 		// Check for multiple block checksums in a row.
 		if n := f.readContiguousChecksums(&a, blockID); n > 0 {
-			itr.Seek(uint64(blockID+n) * HashBlockSize * SliceWidth)
+			itr.Seek(uint64(blockID+n) * HashBlockSize * f.sliceWidth)
 			v, eof = itr.Next()
 			if eof {
 				break
 			}
-			blockID = int(v / (HashBlockSize * SliceWidth))
+			blockID = int(v / (HashBlockSize * f.sliceWidth))
 			continue
 		}
 
@@ -739,7 +1008,7 @@ func (f *Fragment) Blocks() []FragmentBlock {
 		// Read all values for the block.
 		for ; ; v, eof = itr.Next() {
 			// Once we hit the next block, save the value for the next iteration.
-			blockID = int(v / (HashBlockSize * SliceWidth))
+			blockID = int(v / (HashBlockSize * f.sliceWidth))
 			if blockID != h.blockID || eof {
 				break
 			}
@@ -783,12 +1052,15 @@ func (f *Fragment) readContiguousChecksums(a *[]FragmentBlock, blockID int) (n i
 
 // BlockData returns bits in a block as row & column ID pairs.
 func (f *Fragment) BlockData(id int) (rowIDs, columnIDs []uint64) {
-	f.mu.Lock()
-	defer f.mu.Unlock()
+	if err := f.rlock(); err != nil {
+		f.logger().Printf("fragment: error opening: path=%s, err=%s", f.path, err)
+		return nil, nil
+	}
+	defer f.mu.RUnlock()
 
-	f.storage.ForEachRange(uint64(id)*HashBlockSize*SliceWidth, (uint64(id)+1)*HashBlockSize*SliceWidth, func(i uint64) {
-		rowIDs = append(rowIDs, i/SliceWidth)
-		columnIDs = append(columnIDs, i%SliceWidth)
+	f.storage.ForEachRange(uint64(id)*HashBlockSize*f.sliceWidth, (uint64(id)+1)*HashBlockSize*f.sliceWidth, func(i uint64) {
+		rowIDs = append(rowIDs, i/f.sliceWidth)
+		columnIDs = append(columnIDs, i%f.sliceWidth)
 	})
 	return
 }
@@ -800,15 +1072,25 @@ func (f *Fragment) BlockData(id int) (rowIDs, columnIDs []uint64) {
 // cleared bit then the bit is considered cleared. The function returns the
 // diff per incoming block so that all can be in sync.
 func (f *Fragment) MergeBlock(id int, data []PairSet) (sets, clears []PairSet, err error) {
+	sets, clears, _, err = f.mergeBlock(id, data)
+	return sets, clears, err
+}
+
+// mergeBlock merges data into the block and additionally returns the
+// number of local bits that were changed.
+func (f *Fragment) mergeBlock(id int, data []PairSet) (sets, clears []PairSet, n int, err error) {
 	// Ensure that all pair sets are of equal length.
 	for i := range data {
 		if len(data[i].RowIDs) != len(data[i].ColumnIDs) {
-			return nil, nil, fmt.Errorf("pair set mismatch(idx=%d): %d != %d", i, len(data[i].RowIDs), len(data[i].ColumnIDs))
+			return nil, nil, 0, fmt.Errorf("pair set mismatch(idx=%d): %d != %d", i, len(data[i].RowIDs), len(data[i].ColumnIDs))
 		}
 	}
 
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if err := f.acquire(); err != nil {
+		return nil, nil, 0, err
+	}
 
 	// Track sets and clears for all blocks (including local).
 	sets = make([]PairSet, len(data)+1)
@@ -816,13 +1098,13 @@ func (f *Fragment) MergeBlock(id int, data []PairSet) (sets, clears []PairSet, e
 
 	// Limit upper row/column pair.
 	maxRowID := uint64(id+1) * HashBlockSize
-	maxColumnID := uint64(SliceWidth)
+	maxColumnID := f.sliceWidth
 
 	// Create buffered iterator for local block.
 	itrs := make([]*BufIterator, 1, len(data)+1)
 	itrs[0] = NewBufIterator(
 		NewLimitIterator(
-			NewRoaringIterator(f.storage.Iterator()), maxRowID, maxColumnID,
+			NewRoaringIterator(f.storage.Iterator(), f.sliceWidth), maxRowID, maxColumnID,
 		),
 	)
 
@@ -904,19 +1186,20 @@ func (f *Fragment) MergeBlock(id int, data []PairSet) (sets, clears []PairSet, e
 
 	// Set local bits.
 	for i := range sets[0].ColumnIDs {
-		if _, err := f.setBit(sets[0].RowIDs[i], (f.Slice()*SliceWidth)+sets[0].ColumnIDs[i]); err != nil {
-			return nil, nil, err
+		if _, err := f.setBit(sets[0].RowIDs[i], (f.Slice()*f.sliceWidth)+sets[0].ColumnIDs[i]); err != nil {
+			return nil, nil, 0, err
 		}
 	}
 
 	// Clear local bits.
 	for i := range clears[0].ColumnIDs {
-		if _, err := f.clearBit(clears[0].RowIDs[i], (f.Slice()*SliceWidth)+clears[0].ColumnIDs[i]); err != nil {
-			return nil, nil, err
+		if _, err := f.clearBit(clears[0].RowIDs[i], (f.Slice()*f.sliceWidth)+clears[0].ColumnIDs[i]); err != nil {
+			return nil, nil, 0, err
 		}
 	}
 
-	return sets[1:], clears[1:], nil
+	n = len(sets[0].ColumnIDs) + len(clears[0].ColumnIDs)
+	return sets[1:], clears[1:], n, nil
 }
 
 // Import bulk imports a set of bits and then snapshots the storage.
@@ -924,11 +1207,20 @@ func (f *Fragment) MergeBlock(id int, data []PairSet) (sets, clears []PairSet, e
 func (f *Fragment) Import(rowIDs, columnIDs []uint64) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if err := f.acquire(); err != nil {
+		return err
+	}
+
 	// Verify that there are an equal number of row ids and column ids.
 	if len(rowIDs) != len(columnIDs) {
 		return fmt.Errorf("mismatch of row/column len: %d != %d", len(rowIDs), len(columnIDs))
 	}
 
+	// Keep one row per column for mutex fragments.
+	if f.mutex {
+		rowIDs, columnIDs = f.mutexImportBits(rowIDs, columnIDs)
+	}
+
 	// Disconnect op writer so we don't append updates.
 	f.storage.OpWriter = nil
 
@@ -946,6 +1238,21 @@ func (f *Fragment) Import(rowIDs, columnIDs []uint64) error {
 				return err
 			}
 
+			// Clear any other row set for the column in mutex fragments.
+			if f.mutex {
+				conflictRowIDs, conflictColumnIDs := f.mutexConflicts(rowID, columnID)
+				for j := range conflictRowIDs {
+					conflictPos, err := f.pos(conflictRowIDs[j], conflictColumnIDs[j])
+					if err != nil {
+						return err
+					} else if _, err := f.storage.Remove(conflictPos); err != nil {
+						return err
+					}
+					set[conflictRowIDs[j]] = struct{}{}
+					delete(f.checksums, int(conflictRowIDs[j]/HashBlockSize))
+				}
+			}
+
 			// Write to storage.
 			_, err = f.storage.Add(pos)
 			if err != nil {
@@ -988,24 +1295,238 @@ func (f *Fragment) Import(rowIDs, columnIDs []uint64) error {
 	return nil
 }
 
+// SetRow replaces the bits of a row with the bits in row which fall within
+// the fragment's slice. Returns true if any bit changed.
+func (f *Fragment) SetRow(rowID uint64, row *Bitmap) (changed bool, err error) {
+	f.mu.Lock()
+	if err := f.acquire(); err != nil {
+		f.mu.Unlock()
+		return false, err
+	}
+	changed, err = f.setRow(rowID, row)
+	batch := f.batch
+	f.mu.Unlock()
+
+	// Wait for the writes to be synced when using group commit.
+	if err != nil || !changed {
+		return changed, err
+	}
+	return changed, batch.wait()
+}
+
+func (f *Fragment) setRow(rowID uint64, row *Bitmap) (bool, error) {
+	minColumnID := f.slice * f.sliceWidth
+	existing := f.row(rowID, false, false)
+
+	var bits rowUpdate
+	for _, columnID := range existing.Difference(row).Bits() {
+		bits.clear(rowID, columnID)
+	}
+	for _, columnID := range row.Difference(existing).Bits() {
+		if columnID >= minColumnID && columnID < minColumnID+f.sliceWidth {
+			bits.set(rowID, columnID)
+		}
+	}
+	return f.updateBits(&bits)
+}
+
+// SetColumn replaces the rows set for a column with the row IDs in rows.
+// Returns true if any bit changed.
+func (f *Fragment) SetColumn(columnID uint64, rows *Bitmap) (changed bool, err error) {
+	f.mu.Lock()
+	if err := f.acquire(); err != nil {
+		f.mu.Unlock()
+		return false, err
+	}
+	changed, err = f.setColumn(columnID, rows)
+	batch := f.batch
+	f.mu.Unlock()
+
+	// Wait for the writes to be synced when using group commit.
+	if err != nil || !changed {
+		return changed, err
+	}
+	return changed, batch.wait()
+}
+
+func (f *Fragment) setColumn(columnID uint64, rows *Bitmap) (bool, error) {
+	// Verify the column is within the fragment's slice.
+	if _, err := f.pos(0, columnID); err != nil {
+		return false, err
+	}
+
+	existing := NewBitmap(f.columnRows(columnID)...)
+
+	var bits rowUpdate
+	for _, rowID := range existing.Difference(rows).Bits() {
+		bits.clear(rowID, columnID)
+	}
+	for _, rowID := range rows.Difference(existing).Bits() {
+		bits.set(rowID, columnID)
+	}
+	return f.updateBits(&bits)
+}
+
+// rowUpdate holds the bits set & cleared by a bulk row update.
+type rowUpdate struct {
+	setRowIDs, setColumnIDs     []uint64
+	clearRowIDs, clearColumnIDs []uint64
+}
+
+func (u *rowUpdate) set(rowID, columnID uint64) {
+	u.setRowIDs = append(u.setRowIDs, rowID)
+	u.setColumnIDs = append(u.setColumnIDs, columnID)
+}
+
+func (u *rowUpdate) clear(rowID, columnID uint64) {
+	u.clearRowIDs = append(u.clearRowIDs, rowID)
+	u.clearColumnIDs = append(u.clearColumnIDs, columnID)
+}
+
+// updateBits applies a bulk update to the storage. Each changed bit is
+// written to the op log and the cached rows & counts are updated once per
+// changed row. Returns true if any bit changed.
+func (f *Fragment) updateBits(u *rowUpdate) (bool, error) {
+	changed := make(map[uint64]struct{})
+	var setN, clearN int
+
+	err := func() error {
+		remove := func(rowID, columnID uint64) error {
+			pos, err := f.pos(rowID, columnID)
+			if err != nil {
+				return err
+			}
+			if ok, err := f.storage.Remove(pos); err != nil {
+				return err
+			} else if ok {
+				changed[rowID] = struct{}{}
+				clearN++
+			}
+			return nil
+		}
+
+		for i := range u.clearRowIDs {
+			if err := remove(u.clearRowIDs[i], u.clearColumnIDs[i]); err != nil {
+				return err
+			}
+		}
+
+		for i := range u.setRowIDs {
+			rowID, columnID := u.setRowIDs[i], u.setColumnIDs[i]
+
+			// Clear any other row set for the column in mutex fragments.
+			if f.mutex {
+				conflictRowIDs, conflictColumnIDs := f.mutexConflicts(rowID, columnID)
+				for j := range conflictRowIDs {
+					if err := remove(conflictRowIDs[j], conflictColumnIDs[j]); err != nil {
+						return err
+					}
+				}
+			}
+
+			pos, err := f.pos(rowID, columnID)
+			if err != nil {
+				return err
+			}
+			if ok, err := f.storage.Add(pos); err != nil {
+				return err
+			} else if ok {
+				changed[rowID] = struct{}{}
+				setN++
+			}
+		}
+		return nil
+	}()
+
+	// Invalidate block checksums and update the cached rows & counts of any
+	// rows changed before an error.
+	for rowID := range changed {
+		delete(f.checksums, int(rowID/HashBlockSize))
+		f.updateRow(rowID)
+	}
+	if setN > 0 {
+		f.stats.Count("setN", int64(setN))
+	}
+	if clearN > 0 {
+		f.stats.Count("clearN", int64(clearN))
+	}
+
+	// Count the ops until a snapshot is required and sync them once.
+	if opN := setN + clearN; opN > 0 {
+		f.opN += opN - 1
+		if ierr := f.incrementOpN(); err == nil {
+			err = ierr
+		}
+	}
+
+	if err != nil {
+		return false, err
+	}
+	return len(changed) > 0, nil
+}
+
 // incrementOpN increase the operation count by one.
 // If the count exceeds the maximum allowed then a snapshot is performed.
+// Otherwise the op is synced according to the durability mode.
 func (f *Fragment) incrementOpN() error {
 	f.opN++
-	if f.opN <= f.MaxOpN {
-		return nil
+	if f.opN <= f.MaxOpN || f.snapshotting {
+		return f.syncOp()
 	}
 
-	if err := f.snapshot(); err != nil {
+	if err := f.startSnapshot(); err != nil {
 		return fmt.Errorf("snapshot: %s", err)
 	}
+	return f.syncOp()
+}
+
+// syncOp makes the last op written to the op log durable. Per-write
+// durability syncs immediately; group commit adds the op to the next batch.
+func (f *Fragment) syncOp() error {
+	switch f.Durability.Mode {
+	case DurabilityWrite:
+		f.stats.Count("fsyncN", 1)
+		if err := f.Backend.Sync(); err != nil {
+			return fmt.Errorf("sync: %s", err)
+		}
+	case DurabilityGroup:
+		if f.batch == nil {
+			f.batch = &commitBatch{done: make(chan struct{})}
+			time.AfterFunc(f.Durability.Interval, f.commit)
+		}
+	}
 	return nil
 }
 
+// commit syncs the op log and releases all writers in the current batch.
+func (f *Fragment) commit() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.batch == nil {
+		return
+	}
+
+	f.stats.Count("fsyncN", 1)
+	f.releaseBatch(f.Backend.Sync())
+}
+
+// releaseBatch releases writers waiting on the current batch with err.
+func (f *Fragment) releaseBatch(err error) {
+	if f.batch == nil {
+		return
+	}
+	f.batch.err = err
+	close(f.batch.done)
+	f.batch = nil
+}
+
 // Snapshot writes the storage bitmap to disk and reopens it.
 func (f *Fragment) Snapshot() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if err := f.acquire(); err != nil {
+		return err
+	}
 	return f.snapshot()
 }
 
@@ -1019,39 +1540,119 @@ func (f *Fragment) snapshot() error {
 	logger.Printf("fragment: snapshotting %s/%s/%s/%d", f.index, f.frame, f.view, f.slice)
 	defer track(time.Now(), fmt.Sprintf("fragment: snapshot complete %s/%s/%s/%d", f.index, f.frame, f.view, f.slice), logger)
 
-	// Create a temporary file to snapshot to.
-	snapshotPath := f.path + SnapshotExt
-	file, err := os.Create(snapshotPath)
+	// Replace the backend's contents with the storage bitmap.
+	// The bitmap includes any ops written during a background snapshot.
+	if err := f.Backend.Snapshot(f.writeStorage); err != nil {
+		return err
+	} else if err := f.closeStorage(); err != nil {
+		return fmt.Errorf("close storage: %s", err)
+	}
+
+	// Reopen storage.
+	if err := f.openStorage(); err != nil {
+		return fmt.Errorf("open storage: %s", err)
+	}
+
+	// Reset operation count.
+	f.opN = 0
+
+	return nil
+}
+
+// startSnapshot freezes the current storage and writes it to the backend in
+// the background. New ops are kept separately by the backend until the
+// snapshot completes.
+func (f *Fragment) startSnapshot() error {
+	// Release writers waiting on ops written before the snapshot.
+	if f.Durability.Mode == DurabilityGroup && f.batch != nil {
+		f.stats.Count("fsyncN", 1)
+		f.releaseBatch(f.Backend.Sync())
+	}
+
+	snapshot, err := f.Backend.BeginSnapshot()
 	if err != nil {
-		return fmt.Errorf("create snapshot file: %s", err)
+		return err
 	}
-	defer file.Close()
+	f.snapshotting = true
 
-	// Write storage to snapshot.
-	bw := bufio.NewWriter(file)
-	if _, err := f.storage.WriteTo(bw); err != nil {
-		return fmt.Errorf("snapshot write to: %s", err)
-	} else if err := bw.Flush(); err != nil {
-		return fmt.Errorf("flush: %s", err)
+	frozen := &frozenStorage{
+		storage:  f.storage.Freeze(),
+		snapshot: snapshot,
+		gen:      f.snapshotGen,
+		opN:      f.opN,
 	}
+	f.snapshotWG.Add(1)
+	go func() {
+		defer f.snapshotWG.Done()
+		if err := f.backgroundSnapshot(frozen); err != nil {
+			f.logger().Printf("fragment: background snapshot error: path=%s, err=%s", f.path, err)
+		}
+	}()
+	return nil
+}
 
-	// Close current storage.
-	if err := f.closeStorage(); err != nil {
-		return fmt.Errorf("close storage: %s", err)
+// frozenStorage is a read-only copy of storage being written by a
+// background snapshot.
+type frozenStorage struct {
+	storage  *roaring.Bitmap
+	snapshot StorageSnapshot
+	gen      int // storage generation when frozen
+	opN      int // op count when frozen
+}
+
+// backgroundSnapshot writes a frozen copy of storage to the backend and then
+// replaces the backend's contents with it and the ops appended since.
+// The result is discarded if storage was closed in the meantime.
+func (f *Fragment) backgroundSnapshot(frozen *frozenStorage) error {
+	// Limit the number of concurrent snapshots.
+	if f.snapshotQueue != nil {
+		f.snapshotQueue <- struct{}{}
+		defer func() { <-f.snapshotQueue }()
 	}
 
-	// Move snapshot to data file location.
-	if err := os.Rename(snapshotPath, f.path); err != nil {
-		return fmt.Errorf("rename snapshot: %s", err)
+	logger := f.logger()
+	logger.Printf("fragment: snapshotting %s/%s/%s/%d", f.index, f.frame, f.view, f.slice)
+	defer track(time.Now(), fmt.Sprintf("fragment: snapshot complete %s/%s/%s/%d", f.index, f.frame, f.view, f.slice), logger)
+
+	// Write the frozen storage without holding the fragment lock.
+	err := frozen.snapshot.Write(func(w io.Writer) error {
+		_, err := frozen.storage.WriteTo(w)
+		return err
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Exit if storage has been closed or snapshotted since freezing.
+	if frozen.gen != f.snapshotGen {
+		frozen.snapshot.Discard()
+		return err
 	}
+	f.snapshotting = false
 
-	// Reopen storage.
-	if err := f.openStorage(); err != nil {
-		return fmt.Errorf("open storage: %s", err)
+	if err == nil {
+		err = frozen.snapshot.Commit()
+	} else {
+		frozen.snapshot.Discard()
+	}
+	if err == nil {
+		if err = f.closeStorage(); err == nil {
+			err = f.openStorage()
+		}
 	}
 
-	// Reset operation count.
-	f.opN = 0
+	// On failure, reopening storage folds the new ops into the data.
+	if err != nil {
+		f.closeStorage()
+		if err := f.openStorage(); err != nil {
+			f.logger().Printf("fragment: error reopening storage: path=%s, err=%s", f.path, err)
+		}
+		return err
+	}
+
+	// Ops appended since freezing remain in the op log.
+	f.opN -= frozen.opN
+	f.stats.Count("snapshotN", 1)
 
 	return nil
 }
@@ -1059,7 +1660,9 @@ func (f *Fragment) snapshot() error {
 // RecalculateCache rebuilds the cache regardless of invalidate time delay.
 func (f *Fragment) RecalculateCache() {
 	f.mu.Lock()
-	f.cache.Recalculate()
+	if f.opened {
+		f.cache.Recalculate()
+	}
 	f.mu.Unlock()
 }
 
@@ -1067,6 +1670,9 @@ func (f *Fragment) RecalculateCache() {
 func (f *Fragment) FlushCache() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if !f.opened {
+		return nil
+	}
 	return f.flushCache()
 }
 
@@ -1094,6 +1700,11 @@ func (f *Fragment) flushCache() error {
 
 // WriteTo writes the fragment's data to w.
 func (f *Fragment) WriteTo(w io.Writer) (n int64, err error) {
+	// Open the fragment so that ops from an interrupted snapshot are merged.
+	if err := f.ensureOpen(); err != nil {
+		return 0, err
+	}
+
 	// Force cache flush.
 	if err := f.FlushCache(); err != nil {
 		return 0, err
@@ -1111,30 +1722,15 @@ func (f *Fragment) WriteTo(w io.Writer) (n int64, err error) {
 }
 
 func (f *Fragment) writeStorageToArchive(tw *tar.Writer) error {
-	// Open separate file descriptor to read from.
-	file, err := os.Open(f.path)
+	// Retrieve a reader over the current contents under lock so we don't
+	// read while an operation is appending to the end.
+	f.mu.Lock()
+	r, sz, err := f.Backend.ReadAll()
+	f.mu.Unlock()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	// Retrieve the current file size under lock so we don't read
-	// while an operation is appending to the end.
-	var sz int64
-	if err := func() error {
-		f.mu.Lock()
-		defer f.mu.Unlock()
-
-		fi, err := file.Stat()
-		if err != nil {
-			return err
-		}
-		sz = fi.Size()
-
-		return nil
-	}(); err != nil {
-		return err
-	}
+	defer r.Close()
 
 	// Write archive header.
 	if err := tw.WriteHeader(&tar.Header{
@@ -1146,9 +1742,9 @@ func (f *Fragment) writeStorageToArchive(tw *tar.Writer) error {
 		return err
 	}
 
-	// Copy the file up to the last known size.
+	// Copy the contents up to the size read under lock.
 	// This is done outside the lock because the storage format is append-only.
-	if _, err := io.CopyN(tw, file, sz); err != nil {
+	if _, err := io.CopyN(tw, r, sz); err != nil {
 		return err
 	}
 	return nil
@@ -1187,6 +1783,9 @@ func (f *Fragment) writeCacheToArchive(tw *tar.Writer) error {
 func (f *Fragment) ReadFrom(r io.Reader) (n int64, err error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if err := f.acquire(); err != nil {
+		return 0, err
+	}
 
 	tr := tar.NewReader(r)
 	for {
@@ -1217,31 +1816,18 @@ func (f *Fragment) ReadFrom(r io.Reader) (n int64, err error) {
 }
 
 func (f *Fragment) readStorageFromArchive(r io.Reader) error {
-	// Create a temporary file to copy into.
-	path := f.path + CopyExt
-	file, err := os.Create(path)
-	if err != nil {
+	// Replace the backend's contents with the archived data.
+	if err := f.Backend.Snapshot(func(w io.Writer) error {
+		_, err := io.Copy(w, r)
 		return err
-	}
-	defer file.Close()
-
-	// Copy reader into temporary path.
-	if _, err = io.Copy(file, r); err != nil {
+	}); err != nil {
 		return err
 	}
 
-	// Close current storage.
+	// Reopen storage.
 	if err := f.closeStorage(); err != nil {
 		return err
-	}
-
-	// Move snapshot to data file location.
-	if err := os.Rename(path, f.path); err != nil {
-		return err
-	}
-
-	// Reopen storage.
-	if err := f.openStorage(); err != nil {
+	} else if err := f.openStorage(); err != nil {
 		return err
 	}
 
@@ -1304,6 +1890,12 @@ type FragmentSyncer struct {
 	Host    string
 	Cluster *Cluster
 
+	// Limits the rate of block data transferred. Optional.
+	Limiter *RateLimiter
+
+	// Tracks progress across a holder sync. Optional.
+	Progress *SyncProgress
+
 	Closing <-chan struct{}
 }
 
@@ -1320,6 +1912,13 @@ func (s *FragmentSyncer) isClosing() bool {
 // SyncFragment compares checksums for the local and remote fragments and
 // then merges any blocks which have differences.
 func (s *FragmentSyncer) SyncFragment() error {
+	return s.SyncBlocks(nil)
+}
+
+// SyncBlocks compares checksums for the blocks with the given IDs in the
+// local and remote fragments and then merges the blocks which have
+// differences. All blocks are compared if ids is nil.
+func (s *FragmentSyncer) SyncBlocks(ids []int) error {
 	// Determine replica set.
 	nodes := s.Cluster.FragmentNodes(s.Fragment.Index(), s.Fragment.Slice())
 	if len(nodes) == 1 {
@@ -1332,12 +1931,12 @@ func (s *FragmentSyncer) SyncFragment() error {
 		// Read local blocks.
 		if node.Host == s.Host {
 			b := s.Fragment.Blocks()
-			blockSets = append(blockSets, b)
+			blockSets = append(blockSets, filterBlocks(b, ids))
 			continue
 		}
 
 		// Retrieve remote blocks.
-		client, err := NewClient(node.Host)
+		client, err := s.Cluster.NodeClient(node.Host)
 		if err != nil {
 			return err
 		}
@@ -1345,7 +1944,7 @@ func (s *FragmentSyncer) SyncFragment() error {
 		if err != nil && err != ErrFragmentNotFound {
 			return err
 		}
-		blockSets = append(blockSets, blocks)
+		blockSets = append(blockSets, filterBlocks(blocks, ids))
 
 		// Verify sync is not prematurely closing.
 		if s.isClosing() {
@@ -1354,6 +1953,14 @@ func (s *FragmentSyncer) SyncFragment() error {
 	}
 
 	// Iterate over all blocks and find differences.
+	var blocksCompared, blocksRepaired, bitsRepaired int64
+	defer func() {
+		s.Fragment.stats.Count("antiEntropy.blocksCompared", blocksCompared)
+		s.Fragment.stats.Count("antiEntropy.blocksRepaired", blocksRepaired)
+		s.Fragment.stats.Count("antiEntropy.bitsRepaired", bitsRepaired)
+		s.Progress.addBlocks(blocksCompared, blocksRepaired, bitsRepaired)
+	}()
+
 	checksums := make([][]byte, len(nodes))
 	for {
 		// Find min block id.
@@ -1385,22 +1992,51 @@ func (s *FragmentSyncer) SyncFragment() error {
 		}
 
 		// Ignore if all the blocks on each node match.
+		blocksCompared++
 		if byteSlicesEqual(checksums) {
 			continue
 		}
 
 		// Synchronize block.
-		if err := s.syncBlock(blockID); err != nil {
+		n, err := s.syncBlock(blockID)
+		if err != nil {
 			return fmt.Errorf("sync block: id=%d, err=%s", blockID, err)
 		}
+		blocksRepaired++
+		bitsRepaired += int64(n)
+
+		// Verify sync is not prematurely closing.
+		if s.isClosing() {
+			return nil
+		}
 	}
 
 	return nil
 }
 
+// filterBlocks returns the blocks with the given IDs.
+// Returns all blocks if ids is nil.
+func filterBlocks(blocks []FragmentBlock, ids []int) []FragmentBlock {
+	if ids == nil {
+		return blocks
+	}
+
+	other := make([]FragmentBlock, 0, len(ids))
+	for _, block := range blocks {
+		for _, id := range ids {
+			if block.ID == id {
+				other = append(other, block)
+				break
+			}
+		}
+	}
+	return other
+}
+
 // syncBlock sends and receives all rows for a given block.
+// Returns the number of bits changed across all replicas.
 // Returns an error if any remote hosts are unreachable.
-func (s *FragmentSyncer) syncBlock(id int) error {
+func (s *FragmentSyncer) syncBlock(id int) (int, error) {
 	f := s.Fragment
 
 	// Read pairs from each remote block.
@@ -1413,36 +2049,39 @@ func (s *FragmentSyncer) syncBlock(id int) error {
 
 		// Verify sync is not prematurely closing.
 		if s.isClosing() {
-			return nil
+			return 0, nil
 		}
 
-		client, err := NewClient(node.Host)
+		client, err := s.Cluster.NodeClient(node.Host)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		clients = append(clients, client)
 
 		// Only sync the standard block.
 		rowIDs, columnIDs, err := client.BlockData(context.Background(), f.Index(), f.Frame(), ViewStandard, f.Slice(), id)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		pairSets = append(pairSets, PairSet{
 			ColumnIDs: columnIDs,
 			RowIDs:    rowIDs,
 		})
+
+		// Throttle based on the size of the transferred block.
+		s.Limiter.Wait(pairSize*len(rowIDs), s.Closing)
 	}
 
 	// Verify sync is not prematurely closing.
 	if s.isClosing() {
-		return nil
+		return 0, nil
 	}
 
 	// Merge blocks together.
-	sets, clears, err := f.MergeBlock(id, pairSets)
+	sets, clears, n, err := f.mergeBlock(id, pairSets)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Write updates to remote blocks.
@@ -1453,40 +2092,39 @@ func (s *FragmentSyncer) syncBlock(id int) error {
 		if len(set.ColumnIDs) == 0 && len(clear.ColumnIDs) == 0 {
 			continue
 		}
+		n += len(set.ColumnIDs) + len(clear.ColumnIDs)
 
 		// Generate query with sets & clears.
 		var buf bytes.Buffer
 
 		// Only sync the standard block.
 		for j := 0; j < len(set.ColumnIDs); j++ {
-			fmt.Fprintf(&buf, "SetBit(frame=%q, rowID=%d, columnID=%d)\n", f.Frame(), set.RowIDs[j], (f.Slice()*SliceWidth)+set.ColumnIDs[j])
+			fmt.Fprintf(&buf, "SetBit(frame=%q, rowID=%d, columnID=%d)\n", f.Frame(), set.RowIDs[j], (f.Slice()*f.sliceWidth)+set.ColumnIDs[j])
 		}
 		for j := 0; j < len(clear.ColumnIDs); j++ {
-			fmt.Fprintf(&buf, "ClearBit(frame=%q, rowID=%d, columnID=%d)\n", f.Frame(), clear.RowIDs[j], (f.Slice()*SliceWidth)+clear.ColumnIDs[j])
+			fmt.Fprintf(&buf, "ClearBit(frame=%q, rowID=%d, columnID=%d)\n", f.Frame(), clear.RowIDs[j], (f.Slice()*f.sliceWidth)+clear.ColumnIDs[j])
 		}
 
 		// Verify sync is not prematurely closing.
 		if s.isClosing() {
-			return nil
+			return 0, nil
 		}
 
+		// Throttle based on the size of the update.
+		s.Limiter.Wait(pairSize*(len(set.ColumnIDs)+len(clear.ColumnIDs)), s.Closing)
+
 		// Execute query.
 		_, err := clients[i].ExecuteQuery(context.Background(), f.Index(), buf.String(), false)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	return n, nil
 }
 
-func madvise(b []byte, advice int) (err error) {
-	_, _, e1 := syscall.Syscall(syscall.SYS_MADVISE, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), uintptr(advice))
-	if e1 != 0 {
-		err = e1
-	}
-	return
-}
+// pairSize is the approximate number of bytes used to transfer a row/column pair.
+const pairSize = 16
 
 // PairSet is a list of equal length row and column id lists.
 type PairSet struct {
@@ -1508,7 +2146,7 @@ func byteSlicesEqual(a [][]byte) bool {
 	return true
 }
 
-// Pos returns the row position of a row/column pair.
+// Pos returns the row position of a row/column pair using the default slice width.
 func Pos(rowID, columnID uint64) uint64 {
 	return (rowID * SliceWidth) + (columnID % SliceWidth)
 }
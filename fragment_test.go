@@ -20,11 +20,15 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/pilosa/pilosa"
+	"github.com/pilosa/pilosa/roaring"
 )
 
 // Test flags
@@ -66,6 +70,35 @@ func TestFragment_SetBit(t *testing.T) {
 	}
 }
 
+// Ensure a fragment rejects row IDs whose positions overflow at the maximum slice width.
+func TestFragment_SetBit_RowOverflow(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	idx := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{SliceWidth: pilosa.MaxSliceWidth})
+	frame, err := idx.CreateFrame("f", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last row whose range fits in the storage can be set.
+	maxRowID := uint64(math.MaxUint64/pilosa.MaxSliceWidth) - 1
+	if _, err := frame.SetBit(pilosa.ViewStandard, maxRowID, pilosa.MaxSliceWidth-1, nil); err != nil {
+		t.Fatal(err)
+	} else if n := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(maxRowID).Count(); n != 1 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+
+	// Larger rows are rejected instead of wrapping around.
+	for _, rowID := range []uint64{maxRowID + 1, 1 << 40} {
+		if _, err := frame.SetBit(pilosa.ViewStandard, rowID, 1, nil); err == nil || err.Error() != "row out of bounds" {
+			t.Fatalf("unexpected error: row=%d, err=%v", rowID, err)
+		}
+	}
+	if n := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(0).Count(); n != 0 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+}
+
 // Ensure a fragment can clear a set bit.
 func TestFragment_ClearBit(t *testing.T) {
 	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
@@ -93,6 +126,120 @@ func TestFragment_ClearBit(t *testing.T) {
 	}
 }
 
+// Ensure a fragment can replace a row.
+func TestFragment_SetRow(t *testing.T) {
+	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
+	defer f.Close()
+
+	f.MustSetBits(100, 1, 2, 3)
+	f.MustSetBits(101, 1)
+	orig := f.Checksum()
+
+	// Replace the row. Bits outside of the fragment's slice are ignored.
+	if changed, err := f.SetRow(100, pilosa.NewBitmap(3, 4, SliceWidth+1)); err != nil {
+		t.Fatal(err)
+	} else if !changed {
+		t.Fatal("expected change")
+	} else if bits := f.Row(100).Bits(); !reflect.DeepEqual(bits, []uint64{3, 4}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	} else if chksum := f.Checksum(); bytes.Equal(chksum, orig) {
+		t.Fatalf("expected checksum to change: %x", chksum)
+	}
+
+	// Setting the same row again does not change the fragment.
+	if changed, err := f.SetRow(100, pilosa.NewBitmap(3, 4)); err != nil {
+		t.Fatal(err)
+	} else if changed {
+		t.Fatal("expected no change")
+	}
+
+	// Clear the row and verify the rank cache.
+	if _, err := f.SetRow(100, pilosa.NewBitmap()); err != nil {
+		t.Fatal(err)
+	} else if pairs, err := f.Top(pilosa.TopOptions{N: 2}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(pairs, []pilosa.Pair{{ID: 101, Count: 1}}) {
+		t.Fatalf("unexpected pairs: %+v", pairs)
+	}
+
+	// Close and reopen the fragment & verify the data.
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(100).Count(); n != 0 {
+		t.Fatalf("unexpected count (reopen): %d", n)
+	} else if n := f.Row(101).Count(); n != 1 {
+		t.Fatalf("unexpected count (reopen): %d", n)
+	}
+}
+
+// Ensure a fragment can replace the rows set for a column.
+func TestFragment_SetColumn(t *testing.T) {
+	f := MustOpenFragment("i", "f", pilosa.ViewInverse, 0)
+	defer f.Close()
+
+	f.MustSetBits(1, 10)
+	f.MustSetBits(2, 10, 11)
+
+	if changed, err := f.SetColumn(10, pilosa.NewBitmap(2, 3)); err != nil {
+		t.Fatal(err)
+	} else if !changed {
+		t.Fatal("expected change")
+	} else if bits := f.Row(1).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits(1): %+v", bits)
+	} else if bits := f.Row(2).Bits(); !reflect.DeepEqual(bits, []uint64{10, 11}) {
+		t.Fatalf("unexpected bits(2): %+v", bits)
+	} else if bits := f.Row(3).Bits(); !reflect.DeepEqual(bits, []uint64{10}) {
+		t.Fatalf("unexpected bits(3): %+v", bits)
+	}
+
+	// Rows are found without scanning every row up to the largest row ID.
+	f.MustSetBits(1<<40, 10, 12)
+	if changed, err := f.SetColumn(10, pilosa.NewBitmap(3)); err != nil {
+		t.Fatal(err)
+	} else if !changed {
+		t.Fatal("expected change")
+	} else if bits := f.Row(1 << 40).Bits(); !reflect.DeepEqual(bits, []uint64{12}) {
+		t.Fatalf("unexpected bits(1<<40): %+v", bits)
+	} else if bits := f.Row(2).Bits(); !reflect.DeepEqual(bits, []uint64{11}) {
+		t.Fatalf("unexpected bits(2): %+v", bits)
+	}
+
+	// Columns outside of the fragment's slice return an error.
+	if _, err := f.SetColumn(SliceWidth, pilosa.NewBitmap(1)); err == nil || err.Error() != "column out of bounds" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a replaced row is written to the op log and snapshotted in the
+// background once MaxOpN is exceeded.
+func TestFragment_SetRow_Snapshot_Background(t *testing.T) {
+	f := NewFragment("i", "f", pilosa.ViewStandard, 0)
+	f.MaxOpN = 10
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	row := pilosa.NewBitmap()
+	for i := uint64(0); i < 100; i++ {
+		row.SetBit(i * 3)
+	}
+	if _, err := f.SetRow(0, row); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(0).Count(); n != 100 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+
+	// Close and reopen the fragment & verify the data.
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(0).Count(); n != 100 {
+		t.Fatalf("unexpected count (reopen): %d", n)
+	} else if _, err := os.Stat(f.Path() + pilosa.SegmentExt); !os.IsNotExist(err) {
+		t.Fatalf("unexpected segment: %v", err)
+	}
+}
+
 // Ensure a fragment can snapshot correctly.
 func TestFragment_Snapshot(t *testing.T) {
 	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
@@ -122,6 +269,216 @@ func TestFragment_Snapshot(t *testing.T) {
 	}
 }
 
+// Ensure a fragment snapshots in the background once MaxOpN is exceeded.
+func TestFragment_Snapshot_Background(t *testing.T) {
+	f := NewFragment("i", "f", pilosa.ViewStandard, 0)
+	f.MaxOpN = 10
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for i := uint64(0); i < 100; i++ {
+		if _, err := f.SetBit(i%3, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := f.Row(0).Count(); n != 34 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+
+	// Close and reopen the fragment & verify the data.
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(0).Count(); n != 34 {
+		t.Fatalf("unexpected count (reopen): %d", n)
+	} else if _, err := os.Stat(f.Path() + pilosa.SegmentExt); !os.IsNotExist(err) {
+		t.Fatalf("unexpected segment: %v", err)
+	}
+}
+
+// Ensure a fragment can be stored in memory instead of in its data file.
+func TestFragment_MemoryBackend(t *testing.T) {
+	f := NewFragment("i", "f", pilosa.ViewStandard, 0)
+	f.Backend = pilosa.NewMemoryBackend()
+	f.MaxOpN = 10
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for i := uint64(0); i < 100; i++ {
+		if _, err := f.SetBit(i%3, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := f.ClearBit(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close and reopen the fragment & verify the data.
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(0).Count(); n != 33 {
+		t.Fatalf("unexpected count (reopen): %d", n)
+	}
+
+	// Snapshot and ensure the data file was never written.
+	if err := f.Snapshot(); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(1).Count(); n != 33 {
+		t.Fatalf("unexpected count (snapshot): %d", n)
+	} else if fi, err := os.Stat(f.Path()); err != nil {
+		t.Fatal(err)
+	} else if fi.Size() != 0 {
+		t.Fatalf("unexpected data file size: %d", fi.Size())
+	}
+}
+
+// Ensure ops in a segment left by an interrupted snapshot are applied on open.
+func TestFragment_Open_Segment(t *testing.T) {
+	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
+	defer f.Close()
+	if _, err := f.SetBit(100, 1); err != nil {
+		t.Fatal(err)
+	} else if err := f.Fragment.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write ops to a segment file.
+	var buf bytes.Buffer
+	bm := roaring.NewBitmap()
+	bm.OpWriter = &buf
+	if _, err := bm.Add(100*SliceWidth+2, 100*SliceWidth+3); err != nil {
+		t.Fatal(err)
+	} else if _, err := bm.Remove(100*SliceWidth + 1); err != nil {
+		t.Fatal(err)
+	} else if err := ioutil.WriteFile(f.Path()+pilosa.SegmentExt, buf.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen and verify the segment was merged.
+	f.Fragment = pilosa.NewFragment(f.Path(), f.Index(), f.Frame(), f.View(), f.Slice())
+	f.Fragment.RowAttrStore = f.RowAttrStore.AttrStore
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	} else if a := f.Row(100).Bits(); !reflect.DeepEqual(a, []uint64{2, 3}) {
+		t.Fatalf("unexpected bits: %+v", a)
+	} else if _, err := os.Stat(f.Path() + pilosa.SegmentExt); !os.IsNotExist(err) {
+		t.Fatalf("unexpected segment: %v", err)
+	}
+}
+
+// Ensure a fragment with a torn op log is truncated and reopened.
+func TestFragment_Open_TornOpLog(t *testing.T) {
+	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
+	defer f.Close()
+
+	if _, err := f.SetBit(1000, 1); err != nil {
+		t.Fatal(err)
+	} else if _, err := f.SetBit(1000, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close fragment and append a partial op to the file.
+	path := f.Path()
+	if err := f.Fragment.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := file.Write([]byte{0, 1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	} else if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen and verify the valid ops are retained.
+	f.Fragment = pilosa.NewFragment(path, f.Index(), f.Frame(), f.View(), f.Slice())
+	f.Fragment.RowAttrStore = f.RowAttrStore.AttrStore
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(1000).Count(); n != 2 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+
+	// Verify the file was truncated and the original was quarantined.
+	if other, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if other.Size() != fi.Size() {
+		t.Fatalf("unexpected size: %d, expected %d", other.Size(), fi.Size())
+	}
+	matches, err := filepath.Glob(path + pilosa.QuarantineExt + ".*")
+	if err != nil {
+		t.Fatal(err)
+	} else if len(matches) != 1 {
+		t.Fatalf("unexpected quarantine files: %v", matches)
+	}
+	os.Remove(matches[0])
+}
+
+// Ensure a fragment persists writes when fsyncing every write.
+func TestFragment_SetBit_DurabilityWrite(t *testing.T) {
+	f := NewFragment("i", "f", pilosa.ViewStandard, 0)
+	f.Durability = pilosa.Durability{Mode: pilosa.DurabilityWrite}
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.SetBit(120, 1); err != nil {
+		t.Fatal(err)
+	} else if _, err := f.ClearBit(120, 1); err != nil {
+		t.Fatal(err)
+	} else if _, err := f.SetBit(120, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if a := f.Row(120).Bits(); !reflect.DeepEqual(a, []uint64{2}) {
+		t.Fatalf("unexpected bits: %+v", a)
+	}
+}
+
+// Ensure concurrent writers are released by a group commit.
+func TestFragment_SetBit_DurabilityGroup(t *testing.T) {
+	f := NewFragment("i", "f", pilosa.ViewStandard, 0)
+	f.Durability = pilosa.Durability{Mode: pilosa.DurabilityGroup, Interval: 5 * time.Millisecond}
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := uint64(0); i < 100; i++ {
+		wg.Add(1)
+		go func(columnID uint64) {
+			defer wg.Done()
+			if _, err := f.SetBit(120, columnID); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if n := f.Row(120).Count(); n != 100 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+}
+
 // Ensure a fragment can iterate over all bits in order.
 func TestFragment_ForEachBit(t *testing.T) {
 	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
@@ -624,6 +981,106 @@ func BenchmarkFragment_IntersectionCount(b *testing.B) {
 	}
 }
 
+// Ensure rows can be read concurrently with writes.
+func TestFragment_Row_Concurrent(t *testing.T) {
+	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
+	defer f.Close()
+
+	// Read rows, blocks & top rows while bits are set.
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				bm := f.Row(1)
+				if n := bm.Count(); uint64(len(bm.Bits())) != n {
+					t.Errorf("row changed while reading: count=%d", n)
+					return
+				}
+				f.Blocks()
+				if _, err := f.Top(pilosa.TopOptions{N: 2}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := uint64(0); i < 1000; i++ {
+		f.MustSetBits(1, i)
+	}
+	close(done)
+	wg.Wait()
+
+	if n := f.Row(1).Count(); n != 1000 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+}
+
+// Benchmarks reading rows from multiple goroutines.
+func BenchmarkFragment_Row_Parallel(b *testing.B) {
+	f := MustOpenBenchmarkFragment(b)
+	defer f.Close()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for rowID := uint64(0); pb.Next(); rowID = (rowID + 1) % 100 {
+			if n := f.Row(rowID).Count(); n == 0 {
+				b.Fatalf("unexpected count: %d", n)
+			}
+		}
+	})
+}
+
+// Benchmarks reading rows from multiple goroutines while one in ten
+// operations sets a bit.
+func BenchmarkFragment_Row_ParallelMixed(b *testing.B) {
+	f := MustOpenBenchmarkFragment(b)
+	defer f.Close()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := uint64(0); pb.Next(); i++ {
+			rowID := i % 100
+			if i%10 == 0 {
+				if _, err := f.SetBit(rowID, (i*7)%SliceWidth); err != nil {
+					b.Fatal(err)
+				}
+				continue
+			}
+			f.Row(rowID).Count()
+		}
+	})
+}
+
+// MustOpenBenchmarkFragment returns a snapshotted fragment with 100 rows of
+// 1000 bits each.
+func MustOpenBenchmarkFragment(b *testing.B) *Fragment {
+	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
+	f.MaxOpN = math.MaxInt32
+
+	rowIDs := make([]uint64, 0, 100*1000)
+	columnIDs := make([]uint64, 0, 100*1000)
+	for rowID := uint64(0); rowID < 100; rowID++ {
+		for i := uint64(0); i < 1000; i++ {
+			rowIDs = append(rowIDs, rowID)
+			columnIDs = append(columnIDs, (i*997)%SliceWidth)
+		}
+	}
+	if err := f.Import(rowIDs, columnIDs); err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
 // Fragment is a test wrapper for pilosa.Fragment.
 type Fragment struct {
 	*pilosa.Fragment
@@ -665,12 +1122,13 @@ func (f *Fragment) Close() error {
 
 // Reopen closes the fragment and reopens it as a new instance.
 func (f *Fragment) Reopen() error {
-	path := f.Path()
+	path, backend := f.Path(), f.Backend
 	if err := f.Fragment.Close(); err != nil {
 		return err
 	}
 
 	f.Fragment = pilosa.NewFragment(path, f.Index(), f.Frame(), f.View(), f.Slice())
+	f.Fragment.Backend = backend
 	f.Fragment.RowAttrStore = f.RowAttrStore.AttrStore
 	if err := f.Open(); err != nil {
 		return err
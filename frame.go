@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,6 +47,7 @@ type Frame struct {
 	index       string
 	name        string
 	timeQuantum TimeQuantum
+	retention   Retention
 
 	views map[string]*View
 
@@ -54,15 +56,36 @@ type Frame struct {
 
 	broadcaster Broadcaster
 	stats       StatsClient
+	durability  Durability
+
+	// Limits concurrent background snapshots. Shared by the holder.
+	snapshotQueue chan struct{}
+
+	// Tracks open fragments for eviction. Shared by the holder.
+	fragmentPool *fragmentPool
+
+	// Row cache limits. The budget is shared by the holder.
+	rowCacheBudget   *bitmapCacheBudget
+	rowCacheMaxBytes int64
+
+	// Number of fragments opened in parallel by each view. Set by the index.
+	openConcurrency int
 
 	// Frame settings.
 	rowLabel       string
 	cacheType      string
 	inverseEnabled bool
+	mutex          bool
 
 	// Cache size for ranked frames
 	cacheSize uint32
 
+	// Number of column IDs in a slice. Set by the index.
+	sliceWidth uint64
+
+	// Storage backend used by fragments. Set by the index.
+	storageBackend string
+
 	LogOutput io.Writer
 }
 
@@ -88,6 +111,8 @@ func NewFrame(path, index, name string) (*Frame, error) {
 		inverseEnabled: DefaultInverseEnabled,
 		cacheType:      DefaultCacheType,
 		cacheSize:      DefaultCacheSize,
+		sliceWidth:     SliceWidth,
+		storageBackend: DefaultStorageBackend,
 
 		LogOutput: ioutil.Discard,
 	}, nil
@@ -99,6 +124,9 @@ func (f *Frame) Name() string { return f.name }
 // Index returns the index name the frame was initialized with.
 func (f *Frame) Index() string { return f.index }
 
+// SliceWidth returns the number of column IDs in a slice of the frame's index.
+func (f *Frame) SliceWidth() uint64 { return f.sliceWidth }
+
 // Path returns the path the frame was initialized with.
 func (f *Frame) Path() string { return f.path }
 
@@ -172,6 +200,11 @@ func (f *Frame) InverseEnabled() bool {
 	return f.inverseEnabled
 }
 
+// Mutex returns true if the frame keeps at most one row set per column.
+func (f *Frame) Mutex() bool {
+	return f.mutex
+}
+
 // SetCacheSize sets the cache size for ranked fames. Persists to meta file on update.
 // defaults to DefaultCacheSize 50000
 func (f *Frame) SetCacheSize(v uint32) error {
@@ -209,6 +242,8 @@ func (f *Frame) Options() FrameOptions {
 		CacheType:      f.cacheType,
 		CacheSize:      f.cacheSize,
 		TimeQuantum:    f.timeQuantum,
+		Retention:      f.retention,
+		Mutex:          f.mutex,
 	}
 	f.mu.Unlock()
 	return opt
@@ -285,9 +320,11 @@ func (f *Frame) loadMeta() error {
 	buf, err := ioutil.ReadFile(filepath.Join(f.path, ".meta"))
 	if os.IsNotExist(err) {
 		f.timeQuantum = ""
+		f.retention = ""
 		f.rowLabel = DefaultRowLabel
 		f.cacheType = DefaultCacheType
 		f.inverseEnabled = DefaultInverseEnabled
+		f.mutex = false
 		f.cacheSize = DefaultCacheSize
 		return nil
 	} else if err != nil {
@@ -300,8 +337,10 @@ func (f *Frame) loadMeta() error {
 
 	// Copy metadata fields.
 	f.timeQuantum = TimeQuantum(pb.TimeQuantum)
+	f.retention = Retention(pb.Retention)
 	f.rowLabel = pb.RowLabel
 	f.inverseEnabled = pb.InverseEnabled
+	f.mutex = pb.Mutex
 	f.cacheSize = pb.CacheSize
 
 	// Copy cache type.
@@ -322,6 +361,8 @@ func (f *Frame) saveMeta() error {
 		CacheType:      f.cacheType,
 		CacheSize:      f.cacheSize,
 		TimeQuantum:    string(f.timeQuantum),
+		Retention:      string(f.retention),
+		Mutex:          f.mutex,
 	})
 	if err != nil {
 		return err
@@ -382,6 +423,79 @@ func (f *Frame) SetTimeQuantum(q TimeQuantum) error {
 	return nil
 }
 
+// Retention returns the retention policy for the frame's time views.
+func (f *Frame) Retention() Retention {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.retention
+}
+
+// SetRetention sets the retention policy for the frame's time views.
+func (f *Frame) SetRetention(r Retention) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Validate input.
+	if !r.Valid() {
+		return ErrInvalidRetention
+	}
+
+	// Update value on frame.
+	f.retention = r
+
+	// Persist meta data to disk.
+	if err := f.saveMeta(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ExpiredViews returns the names of time views past the frame's retention.
+func (f *Frame) ExpiredViews(now time.Time) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.retention == "" {
+		return nil
+	}
+
+	var a []string
+	for name := range f.views {
+		if f.retention.Expired(name, now) {
+			a = append(a, name)
+		}
+	}
+	sort.Strings(a)
+	return a
+}
+
+// timeViewRange returns the time range covered by the frame's time views
+// for base. Returns false if the frame has no time views for base.
+func (f *Frame) timeViewRange(base string) (start, end time.Time, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for name := range f.views {
+		if !strings.HasPrefix(name, base+"_") {
+			continue
+		}
+
+		_, vstart, vend, vok := ParseTimeView(name)
+		if !vok {
+			continue
+		}
+		if !ok || vstart.Before(start) {
+			start = vstart
+		}
+		if !ok || vend.After(end) {
+			end = vend
+		}
+		ok = true
+	}
+	return start, end, ok
+}
+
 // ViewPath returns the path to a view in the frame.
 func (f *Frame) ViewPath(name string) string {
 	return filepath.Join(f.path, "views", name)
@@ -432,13 +546,148 @@ func (f *Frame) CreateViewIfNotExists(name string) (*View, error) {
 	return view, nil
 }
 
+// RollUpViews materializes the coarser time views in the frame's time quantum
+// from finer time views, such as daily views from hourly views, so that time
+// quantum changes apply to existing data. Views are merged by union so a
+// roll-up can be safely repeated. If drop is true, time views with units not
+// in the time quantum are deleted once rolled into a coarser unit.
+// Returns the number of views rolled up into.
+func (f *Frame) RollUpViews(drop bool) (int, error) {
+	q := f.TimeQuantum()
+
+	var n int
+	for _, base := range []string{ViewStandard, ViewInverse} {
+		// Roll up from finest to coarsest so that new views are included
+		// in the next unit.
+		for _, unit := range timeUnits {
+			if !strings.ContainsRune(string(q), unit) {
+				continue
+			}
+
+			// Group finer views by the view covering them.
+			sources := make(map[string][]*View)
+			for _, view := range f.Views() {
+				if !strings.HasPrefix(view.Name(), base+"_") {
+					continue
+				}
+				u, start, _, ok := ParseTimeView(view.Name())
+				if !ok || !nestedTimeUnit(u, unit) {
+					continue
+				}
+				name := ViewByTimeUnit(base, start, unit)
+				sources[name] = append(sources[name], view)
+			}
+
+			names := make([]string, 0, len(sources))
+			for name := range sources {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				if err := f.rollUpView(name, sources[name]); err != nil {
+					return n, fmt.Errorf("roll up view: view=%s, err=%s", name, err)
+				}
+				n++
+			}
+		}
+
+		if !drop {
+			continue
+		}
+
+		// Delete views that have been rolled into a coarser unit.
+		for _, view := range f.Views() {
+			if !strings.HasPrefix(view.Name(), base+"_") {
+				continue
+			}
+			u, _, _, ok := ParseTimeView(view.Name())
+			if !ok || strings.ContainsRune(string(q), u) || !q.hasCoarserUnit(u) {
+				continue
+			}
+			if err := f.DeleteView(view.Name()); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// rollUpView merges the bits of sources into the view with name.
+func (f *Frame) rollUpView(name string, sources []*View) error {
+	target, err := f.CreateViewIfNotExists(name)
+	if err != nil {
+		return err
+	}
+
+	for _, src := range sources {
+		for _, frag := range src.Fragments() {
+			var rowIDs, columnIDs []uint64
+			if err := frag.ForEachBit(func(rowID, columnID uint64) error {
+				rowIDs = append(rowIDs, rowID)
+				columnIDs = append(columnIDs, columnID)
+				return nil
+			}); err != nil {
+				return err
+			} else if len(rowIDs) == 0 {
+				continue
+			}
+
+			other, err := target.CreateFragmentIfNotExists(frag.Slice())
+			if err != nil {
+				return err
+			} else if err := other.Import(rowIDs, columnIDs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteView removes a view and its fragments from the frame.
+func (f *Frame) DeleteView(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Ignore if view doesn't exist.
+	view := f.views[name]
+	if view == nil {
+		return nil
+	}
+
+	// Close view.
+	if err := view.Close(); err != nil {
+		return err
+	}
+
+	// Delete view directory.
+	if err := os.RemoveAll(f.ViewPath(name)); err != nil {
+		return err
+	}
+
+	// Remove reference.
+	delete(f.views, name)
+
+	return nil
+}
+
 func (f *Frame) newView(path, name string) *View {
 	view := NewView(path, f.index, f.name, name, f.cacheSize)
 	view.cacheType = f.cacheType
+	view.sliceWidth = f.sliceWidth
+	view.storageBackend = f.storageBackend
 	view.LogOutput = f.LogOutput
 	view.RowAttrStore = f.rowAttrStore
 	view.stats = f.stats.WithTags(fmt.Sprintf("slice:%s", name))
+	view.durability = f.durability
+	view.snapshotQueue = f.snapshotQueue
+	view.fragmentPool = f.fragmentPool
+	view.rowCacheBudget = f.rowCacheBudget
+	view.rowCacheMaxBytes = f.rowCacheMaxBytes
+	view.mutex = f.mutex
 	view.broadcaster = f.broadcaster
+	view.openConcurrency = f.openConcurrency
 	return view
 }
 
@@ -534,6 +783,12 @@ func (f *Frame) Import(rowIDs, columnIDs []uint64, timestamps []*time.Time) erro
 		return errors.New("time quantum not set in either index or frame")
 	}
 
+	// Keep only the highest row of each column in mutex frames so that the
+	// result does not depend on the order of the bits or of the slices.
+	if f.mutex {
+		rowIDs, columnIDs, timestamps = mutexImportFrameBits(rowIDs, columnIDs, timestamps)
+	}
+
 	// Split import data by fragment.
 	dataByFragment := make(map[importKey]importData)
 	for i := range rowIDs {
@@ -553,7 +808,7 @@ func (f *Frame) Import(rowIDs, columnIDs []uint64, timestamps []*time.Time) erro
 
 		// Attach bit to each standard view.
 		for _, name := range standard {
-			key := importKey{View: name, Slice: columnID / SliceWidth}
+			key := importKey{View: name, Slice: columnID / f.sliceWidth}
 			data := dataByFragment[key]
 			data.RowIDs = append(data.RowIDs, rowID)
 			data.ColumnIDs = append(data.ColumnIDs, columnID)
@@ -563,7 +818,7 @@ func (f *Frame) Import(rowIDs, columnIDs []uint64, timestamps []*time.Time) erro
 		if f.inverseEnabled {
 			// Attach reversed bits to each inverse view.
 			for _, name := range inverse {
-				key := importKey{View: name, Slice: rowID / SliceWidth}
+				key := importKey{View: name, Slice: rowID / f.sliceWidth}
 				data := dataByFragment[key]
 				data.RowIDs = append(data.RowIDs, columnID)    // reversed
 				data.ColumnIDs = append(data.ColumnIDs, rowID) // reversed
@@ -600,11 +855,46 @@ func (f *Frame) Import(rowIDs, columnIDs []uint64, timestamps []*time.Time) erro
 		if err := frag.Import(data.RowIDs, data.ColumnIDs); err != nil {
 			return err
 		}
+
+		// Clear the imported columns from the other slices of mutex
+		// inverse views.
+		if f.mutex && IsInverseView(key.View) {
+			for i, rowID := range data.RowIDs {
+				if i > 0 && rowID == data.RowIDs[i-1] {
+					continue
+				}
+				if _, err := view.clearMutexRow(rowID, key.Slice); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
+// mutexImportFrameBits returns the bits which set the highest row imported
+// for each column. Bits for that row are kept for every timestamp.
+func mutexImportFrameBits(rowIDs, columnIDs []uint64, timestamps []*time.Time) ([]uint64, []uint64, []*time.Time) {
+	max := make(map[uint64]uint64, len(columnIDs))
+	for i, columnID := range columnIDs {
+		if prev, ok := max[columnID]; !ok || rowIDs[i] > prev {
+			max[columnID] = rowIDs[i]
+		}
+	}
+
+	var otherRowIDs, otherColumnIDs []uint64
+	var otherTimestamps []*time.Time
+	for i, columnID := range columnIDs {
+		if rowIDs[i] == max[columnID] {
+			otherRowIDs = append(otherRowIDs, rowIDs[i])
+			otherColumnIDs = append(otherColumnIDs, columnID)
+			otherTimestamps = append(otherTimestamps, timestamps[i])
+		}
+	}
+	return otherRowIDs, otherColumnIDs, otherTimestamps
+}
+
 // encodeFrames converts a into its internal representation.
 func encodeFrames(a []*Frame) []*internal.Frame {
 	other := make([]*internal.Frame, len(a))
@@ -624,6 +914,8 @@ func encodeFrame(f *Frame) *internal.Frame {
 			CacheType:      f.cacheType,
 			CacheSize:      f.cacheSize,
 			TimeQuantum:    string(f.timeQuantum),
+			Retention:      string(f.retention),
+			Mutex:          f.mutex,
 		},
 	}
 }
@@ -653,6 +945,8 @@ type FrameOptions struct {
 	CacheType      string      `json:"cacheType,omitempty"`
 	CacheSize      uint32      `json:"cacheSize,omitempty"`
 	TimeQuantum    TimeQuantum `json:"timeQuantum,omitempty"`
+	Retention      Retention   `json:"retention,omitempty"`
+	Mutex          bool        `json:"mutex,omitempty"`
 }
 
 // Encode converts o into its internal representation.
@@ -663,6 +957,8 @@ func (o *FrameOptions) Encode() *internal.FrameMeta {
 		CacheType:      o.CacheType,
 		CacheSize:      o.CacheSize,
 		TimeQuantum:    string(o.TimeQuantum),
+		Retention:      string(o.Retention),
+		Mutex:          o.Mutex,
 	}
 }
 
@@ -17,6 +17,7 @@ package pilosa_test
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -69,6 +70,179 @@ func TestFrame_SetTimeQuantum(t *testing.T) {
 	}
 }
 
+// Ensure a mutex frame keeps at most one row per column.
+func TestFrame_Mutex(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	f, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrameIfNotExists("f", pilosa.FrameOptions{Mutex: true, InverseEnabled: true})
+	if err != nil {
+		t.Fatal(err)
+	} else if !f.Mutex() {
+		t.Fatal("expected mutex frame")
+	}
+
+	t.Run("SetBit", func(t *testing.T) {
+		f.MustSetBit(pilosa.ViewStandard, 1, 100, nil)
+		f.MustSetBit(pilosa.ViewInverse, 100, 1, nil)
+		f.MustSetBit(pilosa.ViewStandard, 1, 101, nil)
+		f.MustSetBit(pilosa.ViewInverse, 101, 1, nil)
+
+		// Setting a new row for a column clears the previous row.
+		if !f.MustSetBit(pilosa.ViewStandard, 2, 100, nil) {
+			t.Fatal("expected change")
+		} else if !f.MustSetBit(pilosa.ViewInverse, 100, 2, nil) {
+			t.Fatal("expected change")
+		}
+
+		if bits := f.View(pilosa.ViewStandard).Fragment(0).Row(1).Bits(); !reflect.DeepEqual(bits, []uint64{101}) {
+			t.Fatalf("unexpected row 1: %v", bits)
+		} else if bits := f.View(pilosa.ViewStandard).Fragment(0).Row(2).Bits(); !reflect.DeepEqual(bits, []uint64{100}) {
+			t.Fatalf("unexpected row 2: %v", bits)
+		} else if bits := f.View(pilosa.ViewInverse).Fragment(0).Row(100).Bits(); !reflect.DeepEqual(bits, []uint64{2}) {
+			t.Fatalf("unexpected inverse row 100: %v", bits)
+		}
+
+		// Setting the same bit again is not a change.
+		if f.MustSetBit(pilosa.ViewStandard, 2, 100, nil) {
+			t.Fatal("expected no change")
+		}
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		// Column 200 is imported with two rows so the highest row wins.
+		// Column 101 replaces its row from the SetBit test.
+		if err := f.Import([]uint64{3, 5, 4, 3}, []uint64{200, 101, 200, 201}, make([]*time.Time, 4)); err != nil {
+			t.Fatal(err)
+		}
+
+		for view, rows := range map[string]map[uint64][]uint64{
+			pilosa.ViewStandard: {1: {}, 3: {201}, 4: {200}, 5: {101}},
+			pilosa.ViewInverse:  {101: {5}, 200: {4}, 201: {3}},
+		} {
+			for rowID, bits := range rows {
+				if a := f.View(view).Fragment(0).Row(rowID).Bits(); !reflect.DeepEqual(a, bits) {
+					t.Fatalf("unexpected %s row %d: %v", view, rowID, a)
+				}
+			}
+		}
+	})
+
+	// Inverse views clear rows of the column held in other slices.
+	t.Run("InverseSlices", func(t *testing.T) {
+		f.MustSetBit(pilosa.ViewStandard, 1, 400, nil)
+		f.MustSetBit(pilosa.ViewInverse, 400, 1, nil)
+
+		if !f.MustSetBit(pilosa.ViewStandard, 2*SliceWidth, 400, nil) {
+			t.Fatal("expected change")
+		} else if !f.MustSetBit(pilosa.ViewInverse, 400, 2*SliceWidth, nil) {
+			t.Fatal("expected change")
+		}
+
+		if bits := f.View(pilosa.ViewStandard).Fragment(0).Row(1).Bits(); len(bits) != 0 {
+			t.Fatalf("unexpected row 1: %v", bits)
+		} else if bits := f.View(pilosa.ViewInverse).Fragment(0).Row(400).Bits(); len(bits) != 0 {
+			t.Fatalf("unexpected inverse row 400 (slice 0): %v", bits)
+		} else if bits := f.View(pilosa.ViewInverse).Fragment(2).Row(400).Bits(); !reflect.DeepEqual(bits, []uint64{2 * SliceWidth}) {
+			t.Fatalf("unexpected inverse row 400 (slice 2): %v", bits)
+		}
+
+		// Imports keep the highest row of a column across slices.
+		if err := f.Import([]uint64{SliceWidth, 3}, []uint64{400, 401}, make([]*time.Time, 2)); err != nil {
+			t.Fatal(err)
+		} else if err := f.Import([]uint64{3, SliceWidth + 1}, []uint64{401, 401}, make([]*time.Time, 2)); err != nil {
+			t.Fatal(err)
+		}
+
+		for slice, rows := range map[uint64]map[uint64][]uint64{
+			0: {400: {}, 401: {}},
+			1: {400: {SliceWidth}, 401: {SliceWidth + 1}},
+			2: {400: {}},
+		} {
+			for rowID, bits := range rows {
+				if a := f.View(pilosa.ViewInverse).Fragment(slice).Row(rowID).Bits(); !reflect.DeepEqual(a, bits) {
+					t.Fatalf("unexpected inverse row %d (slice %d): %v", rowID, slice, a)
+				}
+			}
+		}
+	})
+
+	// Conflicts are found without scanning every row up to the largest row ID.
+	t.Run("SparseRows", func(t *testing.T) {
+		f.MustSetBit(pilosa.ViewStandard, 9, 299, nil)
+		f.MustSetBit(pilosa.ViewStandard, 10, 302, nil)
+		f.MustSetBit(pilosa.ViewStandard, 1<<40, 300, nil)
+		f.MustSetBit(pilosa.ViewStandard, 1<<40, 301, nil)
+
+		// Setting a small row clears the large row and vice versa.
+		if !f.MustSetBit(pilosa.ViewStandard, 7, 300, nil) {
+			t.Fatal("expected change")
+		} else if !f.MustSetBit(pilosa.ViewStandard, 1<<41, 300, nil) {
+			t.Fatal("expected change")
+		}
+
+		frag := f.View(pilosa.ViewStandard).Fragment(0)
+		for rowID, bits := range map[uint64][]uint64{
+			7:       {},
+			9:       {299},
+			10:      {302},
+			1 << 40: {301},
+			1 << 41: {300},
+		} {
+			if a := frag.Row(rowID).Bits(); !reflect.DeepEqual(a, bits) {
+				t.Fatalf("unexpected row %d: %v", rowID, a)
+			}
+		}
+	})
+}
+
+// Ensure frame can roll up hourly views into coarser views.
+func TestFrame_RollUpViews(t *testing.T) {
+	f := MustOpenFrame()
+	defer f.Close()
+
+	// Write bits to hourly views only.
+	if err := f.SetTimeQuantum(pilosa.TimeQuantum("H")); err != nil {
+		t.Fatal(err)
+	}
+	for i, ts := range []time.Time{
+		time.Date(2017, time.January, 31, 10, 0, 0, 0, time.UTC),
+		time.Date(2017, time.January, 31, 11, 0, 0, 0, time.UTC),
+		time.Date(2017, time.February, 1, 10, 0, 0, 0, time.UTC),
+	} {
+		if _, err := f.SetBit(pilosa.ViewStandard, 1, uint64(i), &ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Add daily & monthly views and roll up the existing hourly views.
+	if err := f.SetTimeQuantum(pilosa.TimeQuantum("MD")); err != nil {
+		t.Fatal(err)
+	} else if n, err := f.RollUpViews(true); err != nil {
+		t.Fatal(err)
+	} else if n != 4 {
+		t.Fatalf("unexpected view count: %d", n)
+	}
+
+	for name, bits := range map[string][]uint64{
+		"standard_20170131": {0, 1},
+		"standard_20170201": {2},
+		"standard_201701":   {0, 1},
+		"standard_201702":   {2},
+	} {
+		if v := f.View(name); v == nil {
+			t.Fatalf("expected view: %s", name)
+		} else if a := v.Fragment(0).Row(1).Bits(); !reflect.DeepEqual(a, bits) {
+			t.Fatalf("unexpected bits for %s: %v", name, a)
+		}
+	}
+
+	// Hourly views are dropped since they are no longer in the quantum.
+	if v := f.View("standard_2017013110"); v != nil {
+		t.Fatal("expected hourly view to be dropped")
+	}
+}
+
 func TestFrame_NameRestriction(t *testing.T) {
 	path, err := ioutil.TempDir("", "pilosa-frame-")
 	if err != nil {
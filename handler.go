@@ -46,9 +46,10 @@ import (
 
 // Handler represents an HTTP handler.
 type Handler struct {
-	Holder        *Holder
-	Broadcaster   Broadcaster
-	StatusHandler StatusHandler
+	Holder             *Holder
+	Broadcaster        Broadcaster
+	StatusHandler      StatusHandler
+	AntiEntropyHandler AntiEntropyHandler
 
 	// Local hostname & cluster configuration.
 	Host    string
@@ -64,6 +65,12 @@ type Handler struct {
 	// The version to report on the /version endpoint.
 	Version string
 
+	// If set, internal endpoints require a verified TLS client certificate.
+	MutualTLS bool
+
+	// If set, requests must be authenticated and authorized per index.
+	Authenticator Authenticator
+
 	// The writer for any logging.
 	LogOutput io.Writer
 }
@@ -82,33 +89,39 @@ func NewRouter(handler *Handler) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/", handler.handleWebUI).Methods("GET")
 	router.HandleFunc("/assets/{file}", handler.handleWebUI).Methods("GET")
-	router.HandleFunc("/index", handler.handleGetIndexes).Methods("GET")
-	router.HandleFunc("/index/{index}", handler.handleGetIndex).Methods("GET")
-	router.HandleFunc("/index/{index}", handler.handlePostIndex).Methods("POST")
-	router.HandleFunc("/index/{index}", handler.handleDeleteIndex).Methods("DELETE")
-	router.HandleFunc("/index/{index}/attr/diff", handler.handlePostIndexAttrDiff).Methods("POST")
+	router.HandleFunc("/index", handler.authorize(PermissionNone, handler.handleGetIndexes)).Methods("GET")
+	router.HandleFunc("/index/{index}", handler.authorize(PermissionRead, handler.handleGetIndex)).Methods("GET")
+	router.HandleFunc("/index/{index}", handler.authorize(PermissionAdmin, handler.handlePostIndex)).Methods("POST")
+	router.HandleFunc("/index/{index}", handler.authorize(PermissionAdmin, handler.handleDeleteIndex)).Methods("DELETE")
+	router.HandleFunc("/index/{index}/attr/diff", handler.authorize(PermissionRead, handler.handlePostIndexAttrDiff)).Methods("POST")
 	//router.HandleFunc("/index/{index}/frame", handler.handleGetFrames).Methods("GET") // Not implemented.
-	router.HandleFunc("/index/{index}/frame/{frame}", handler.handlePostFrame).Methods("POST")
-	router.HandleFunc("/index/{index}/frame/{frame}", handler.handleDeleteFrame).Methods("DELETE")
-	router.HandleFunc("/index/{index}/query", handler.handlePostQuery).Methods("POST")
-	router.HandleFunc("/index/{index}/frame/{frame}/attr/diff", handler.handlePostFrameAttrDiff).Methods("POST")
-	router.HandleFunc("/index/{index}/frame/{frame}/restore", handler.handlePostFrameRestore).Methods("POST")
-	router.HandleFunc("/index/{index}/frame/{frame}/time-quantum", handler.handlePatchFrameTimeQuantum).Methods("PATCH")
-	router.HandleFunc("/index/{index}/frame/{frame}/views", handler.handleGetFrameViews).Methods("GET")
-	router.HandleFunc("/index/{index}/time-quantum", handler.handlePatchIndexTimeQuantum).Methods("PATCH")
-	router.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux).Methods("GET")
-	router.HandleFunc("/debug/vars", handler.handleExpvar).Methods("GET")
-	router.HandleFunc("/export", handler.handleGetExport).Methods("GET")
-	router.HandleFunc("/fragment/block/data", handler.handleGetFragmentBlockData).Methods("GET")
-	router.HandleFunc("/fragment/blocks", handler.handleGetFragmentBlocks).Methods("GET")
-	router.HandleFunc("/fragment/data", handler.handleGetFragmentData).Methods("GET")
-	router.HandleFunc("/fragment/data", handler.handlePostFragmentData).Methods("POST")
-	router.HandleFunc("/fragment/nodes", handler.handleGetFragmentNodes).Methods("GET")
-	router.HandleFunc("/import", handler.handlePostImport).Methods("POST")
-	router.HandleFunc("/hosts", handler.handleGetHosts).Methods("GET")
-	router.HandleFunc("/schema", handler.handleGetSchema).Methods("GET")
-	router.HandleFunc("/slices/max", handler.handleGetSliceMax).Methods("GET")
-	router.HandleFunc("/status", handler.handleGetStatus).Methods("GET")
+	router.HandleFunc("/index/{index}/frame/{frame}", handler.authorize(PermissionAdmin, handler.handlePostFrame)).Methods("POST")
+	router.HandleFunc("/index/{index}/frame/{frame}", handler.authorize(PermissionAdmin, handler.handleDeleteFrame)).Methods("DELETE")
+	router.HandleFunc("/index/{index}/query", handler.authorize(PermissionRead, handler.handlePostQuery)).Methods("POST")
+	router.HandleFunc("/index/{index}/frame/{frame}/attr/diff", handler.authorize(PermissionRead, handler.handlePostFrameAttrDiff)).Methods("POST")
+	router.HandleFunc("/index/{index}/frame/{frame}/restore", handler.authorize(PermissionWrite, handler.handlePostFrameRestore)).Methods("POST")
+	router.HandleFunc("/index/{index}/frame/{frame}/rollup", handler.authorize(PermissionAdmin, handler.handlePostFrameRollUp)).Methods("POST")
+	router.HandleFunc("/index/{index}/frame/{frame}/retention", handler.authorize(PermissionAdmin, handler.handlePatchFrameRetention)).Methods("PATCH")
+	router.HandleFunc("/index/{index}/frame/{frame}/time-quantum", handler.authorize(PermissionAdmin, handler.handlePatchFrameTimeQuantum)).Methods("PATCH")
+	router.HandleFunc("/index/{index}/frame/{frame}/view/{view}", handler.authorize(PermissionAdmin, handler.handleDeleteView)).Methods("DELETE")
+	router.HandleFunc("/index/{index}/frame/{frame}/views", handler.authorize(PermissionRead, handler.handleGetFrameViews)).Methods("GET")
+	router.HandleFunc("/index/{index}/time-quantum", handler.authorize(PermissionAdmin, handler.handlePatchIndexTimeQuantum)).Methods("PATCH")
+	router.PathPrefix("/debug/pprof/").HandlerFunc(handler.authorize(PermissionAdmin, http.DefaultServeMux.ServeHTTP)).Methods("GET")
+	router.HandleFunc("/debug/vars", handler.authorize(PermissionAdmin, handler.handleExpvar)).Methods("GET")
+	router.HandleFunc("/export", handler.authorize(PermissionRead, handler.handleGetExport)).Methods("GET")
+	router.HandleFunc("/fragment/block/data", handler.authorize(PermissionNone, handler.handleGetFragmentBlockData)).Methods("GET")
+	router.HandleFunc("/fragment/blocks", handler.authorize(PermissionRead, handler.handleGetFragmentBlocks)).Methods("GET")
+	router.HandleFunc("/fragment/data", handler.authorize(PermissionRead, handler.handleGetFragmentData)).Methods("GET")
+	router.HandleFunc("/fragment/data", handler.authorize(PermissionWrite, handler.handlePostFragmentData)).Methods("POST")
+	router.HandleFunc("/fragment/nodes", handler.authorize(PermissionRead, handler.handleGetFragmentNodes)).Methods("GET")
+	router.HandleFunc("/import", handler.authorize(PermissionNone, handler.handlePostImport)).Methods("POST")
+	router.HandleFunc("/health", handler.handleGetHealth).Methods("GET")
+	router.HandleFunc("/hosts", handler.authorize(PermissionNone, handler.handleGetHosts)).Methods("GET")
+	router.HandleFunc("/schema", handler.authorize(PermissionNone, handler.handleGetSchema)).Methods("GET")
+	router.HandleFunc("/slices/max", handler.authorize(PermissionNone, handler.handleGetSliceMax)).Methods("GET")
+	router.HandleFunc("/status", handler.authorize(PermissionNone, handler.handleGetStatus)).Methods("GET")
+	router.HandleFunc("/sync", handler.authorize(PermissionAdmin, handler.handlePostSync)).Methods("POST")
+	router.HandleFunc("/sync/status", handler.authorize(PermissionAdmin, handler.handleGetSyncStatus)).Methods("GET")
 	router.HandleFunc("/version", handler.handleGetVersion).Methods("GET")
 
 	// TODO: Apply MethodNotAllowed statuses to all endpoints.
@@ -126,9 +139,56 @@ func (h *Handler) methodNotAllowedHandler(w http.ResponseWriter, r *http.Request
 
 // ServeHTTP handles an HTTP request.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Only other nodes may access internal endpoints when using mutual TLS.
+	if h.MutualTLS && isInternalPath(r.URL.Path) && !hasVerifiedClientCert(r) {
+		http.Error(w, "client certificate required", http.StatusForbidden)
+		return
+	}
+
 	h.Router.ServeHTTP(w, r)
 }
 
+// authorize wraps fn so that it requires an authenticated principal with at
+// least perm on the request's index. The index is read from the route or,
+// for routes without one, from the "index" query parameter. Requests are
+// passed through unchanged when no authenticator is configured.
+func (h *Handler) authorize(perm Permission, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.Authenticator == nil {
+			fn(w, r)
+			return
+		}
+
+		p, err := h.Authenticator.Authenticate(r)
+		if err == ErrUnauthorized {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		index := mux.Vars(r)["index"]
+		if index == "" {
+			index = r.URL.Query().Get("index")
+		}
+		if !p.Allowed(index, perm) {
+			http.Error(w, ErrForbidden.Error(), http.StatusForbidden)
+			return
+		}
+
+		fn(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	}
+}
+
+// allowed returns true if the request's principal has at least perm on index.
+// Always returns true if authentication is disabled.
+func (h *Handler) allowed(r *http.Request, index string, perm Permission) bool {
+	p := PrincipalFromContext(r.Context())
+	return p == nil || p.Allowed(index, perm)
+}
+
 func (h *Handler) handleWebUI(w http.ResponseWriter, r *http.Request) {
 	// If user is using curl, don't chuck HTML at them
 	if strings.HasPrefix(r.UserAgent(), "curl") {
@@ -146,8 +206,16 @@ func (h *Handler) handleWebUI(w http.ResponseWriter, r *http.Request) {
 
 // handleGetSchema handles GET /schema requests.
 func (h *Handler) handleGetSchema(w http.ResponseWriter, r *http.Request) {
+	// Only include indexes which the principal can read.
+	var indexes []*IndexInfo
+	for _, index := range h.Holder.Schema() {
+		if h.allowed(r, index.Name, PermissionRead) {
+			indexes = append(indexes, index)
+		}
+	}
+
 	if err := json.NewEncoder(w).Encode(getSchemaResponse{
-		Indexes: h.Holder.Schema(),
+		Indexes: indexes,
 	}); err != nil {
 		h.logger().Printf("write schema response error: %s", err)
 	}
@@ -162,17 +230,99 @@ func (h *Handler) handleGetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.NewEncoder(w).Encode(getStatusResponse{
 		Status: status,
+		Holder: h.holderStatus(),
 	}); err != nil {
 		h.logger().Printf("write status response error: %s", err)
 	}
 }
 
+// handlePostSync handles POST /sync requests to trigger anti-entropy.
+func (h *Handler) handlePostSync(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opt := SyncOptions{
+		Index: q.Get("index"),
+		Frame: q.Get("frame"),
+	}
+	if opt.Frame != "" && opt.Index == "" {
+		http.Error(w, ErrIndexRequired.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Parse optional slice range.
+	if s := q.Get("start"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid start slice", http.StatusBadRequest)
+			return
+		}
+		opt.SliceStart = v
+	}
+	if s := q.Get("end"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || v <= opt.SliceStart {
+			http.Error(w, "invalid end slice", http.StatusBadRequest)
+			return
+		}
+		opt.SliceEnd = v
+	}
+
+	if err := h.AntiEntropyHandler.TriggerSync(opt); err == ErrSyncPending {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleGetSyncStatus handles GET /sync/status requests.
+func (h *Handler) handleGetSyncStatus(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(h.AntiEntropyHandler.SyncStatus()); err != nil {
+		h.logger().Printf("write sync status response error: %s", err)
+	}
+}
+
 type getSchemaResponse struct {
 	Indexes []*IndexInfo `json:"indexes"`
 }
 
 type getStatusResponse struct {
 	Status proto.Message `json:"status"`
+	Holder holderStatus  `json:"holder"`
+}
+
+// handleGetHealth handles GET /health requests. Returns 503 until the holder
+// has finished preloading fragments. The server only serves requests once the
+// holder's indexes are open, so health checks made before then wait on the
+// listener instead of reporting the starting state.
+func (h *Handler) handleGetHealth(w http.ResponseWriter, r *http.Request) {
+	status := h.holderStatus()
+	if status.State != HolderStateReady {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		h.logger().Printf("write health response error: %s", err)
+	}
+}
+
+// holderStatus returns the load state of the local holder.
+func (h *Handler) holderStatus() holderStatus {
+	if h.Holder == nil {
+		return holderStatus{State: HolderStateReady}
+	}
+	opened, total := h.Holder.LoadProgress()
+	return holderStatus{
+		State:           h.Holder.State(),
+		FragmentsLoaded: opened,
+		FragmentsTotal:  total,
+	}
+}
+
+type holderStatus struct {
+	State           string `json:"state"`
+	FragmentsLoaded int    `json:"fragmentsLoaded"`
+	FragmentsTotal  int    `json:"fragmentsTotal"`
 }
 
 // handlePostQuery handles /query requests.
@@ -187,9 +337,16 @@ func (h *Handler) handlePostQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only other nodes may send remote queries when using mutual TLS.
+	if req.Remote && h.MutualTLS && !hasVerifiedClientCert(r) {
+		http.Error(w, "client certificate required", http.StatusForbidden)
+		return
+	}
+
 	// Build execution options.
 	opt := &ExecOptions{
-		Remote: req.Remote,
+		Remote:     req.Remote,
+		readRepair: req.Remote && req.ReadRepair,
 	}
 
 	// Parse query string.
@@ -200,6 +357,13 @@ func (h *Handler) handlePostQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Queries which modify data require write permission.
+	if hasWriteCalls(q.Calls) && !h.allowed(r, indexName, PermissionWrite) {
+		w.WriteHeader(http.StatusForbidden)
+		h.writeQueryResponse(w, r, &QueryResponse{Err: ErrForbidden})
+		return
+	}
+
 	// Execute the query.
 	results, err := h.Executor.Execute(r.Context(), indexName, q, req.Slices, opt)
 	resp := &QueryResponse{Results: results, Err: err}
@@ -399,6 +563,9 @@ func (h *Handler) handlePostIndex(w http.ResponseWriter, r *http.Request) {
 	if err == ErrIndexExists {
 		http.Error(w, err.Error(), http.StatusConflict)
 		return
+	} else if err == ErrInvalidSliceWidth || err == ErrInvalidStorageBackend {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -548,6 +715,9 @@ func (h *Handler) handlePostFrame(w http.ResponseWriter, r *http.Request) {
 	if err == ErrFrameExists {
 		http.Error(w, err.Error(), http.StatusConflict)
 		return
+	} else if err == ErrInvalidRetention {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -697,6 +867,131 @@ type patchFrameTimeQuantumRequest struct {
 
 type patchFrameTimeQuantumResponse struct{}
 
+// handlePatchFrameRetention handles PATCH /frame/retention request.
+func (h *Handler) handlePatchFrameRetention(w http.ResponseWriter, r *http.Request) {
+	indexName := mux.Vars(r)["index"]
+	frameName := mux.Vars(r)["frame"]
+
+	// Decode request.
+	var req patchFrameRetentionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate retention.
+	retention, err := ParseRetention(req.Retention)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Retrieve frame by name.
+	f := h.Holder.Frame(indexName, frameName)
+	if f == nil {
+		http.Error(w, ErrFrameNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Set retention on frame.
+	if err := f.SetRetention(retention); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Encode response.
+	if err := json.NewEncoder(w).Encode(patchFrameRetentionResponse{}); err != nil {
+		h.logger().Printf("response encoding error: %s", err)
+	}
+}
+
+type patchFrameRetentionRequest struct {
+	Retention string `json:"retention"`
+}
+
+type patchFrameRetentionResponse struct{}
+
+// handlePostFrameRollUp handles POST /frame/rollup request.
+func (h *Handler) handlePostFrameRollUp(w http.ResponseWriter, r *http.Request) {
+	indexName := mux.Vars(r)["index"]
+	frameName := mux.Vars(r)["frame"]
+
+	// Parse drop flag.
+	drop := r.URL.Query().Get("drop") == "true"
+
+	// Retrieve frame by name.
+	f := h.Holder.Frame(indexName, frameName)
+	if f == nil {
+		http.Error(w, ErrFrameNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Roll up local views.
+	n, err := f.RollUpViews(drop)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Send the roll up message so other nodes roll up the slices they own.
+	err = h.Broadcaster.SendAsync(
+		&internal.RollUpViewsMessage{
+			Index: indexName,
+			Frame: frameName,
+			Drop:  drop,
+		})
+	if err != nil {
+		h.logger().Printf("problem sending RollUpViews message: %s", err)
+	}
+
+	// Encode response.
+	if err := json.NewEncoder(w).Encode(postFrameRollUpResponse{ViewN: n}); err != nil {
+		h.logger().Printf("response encoding error: %s", err)
+	}
+}
+
+type postFrameRollUpResponse struct {
+	ViewN int `json:"viewN"`
+}
+
+// handleDeleteView handles DELETE /view request.
+func (h *Handler) handleDeleteView(w http.ResponseWriter, r *http.Request) {
+	indexName := mux.Vars(r)["index"]
+	frameName := mux.Vars(r)["frame"]
+	viewName := mux.Vars(r)["view"]
+
+	// Find frame.
+	f := h.Holder.Frame(indexName, frameName)
+	if f == nil {
+		http.Error(w, ErrFrameNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Delete view from the frame.
+	if err := f.DeleteView(viewName); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Send the delete view message to all nodes.
+	err := h.Broadcaster.SendSync(
+		&internal.DeleteViewMessage{
+			Index: indexName,
+			Frame: frameName,
+			View:  viewName,
+		})
+	if err != nil {
+		h.logger().Printf("problem sending DeleteView message: %s", err)
+	}
+
+	// Encode response.
+	if err := json.NewEncoder(w).Encode(deleteViewResponse{}); err != nil {
+		h.logger().Printf("response encoding error: %s", err)
+	}
+}
+
+type deleteViewResponse struct{}
+
 // handleGetFrameViews handles GET /frame/views request.
 func (h *Handler) handleGetFrameViews(w http.ResponseWriter, r *http.Request) {
 	indexName := mux.Vars(r)["index"]
@@ -917,13 +1212,19 @@ func (h *Handler) handlePostImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Importing requires write permission on the index.
+	if !h.allowed(r, req.Index, PermissionWrite) {
+		http.Error(w, ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+
 	// Convert timestamps to time.Time.
 	timestamps := make([]*time.Time, len(req.Timestamps))
 	for i, ts := range req.Timestamps {
 		if ts == 0 {
 			continue
 		}
-		t := time.Unix(0, ts)
+		t := time.Unix(0, ts).UTC()
 		timestamps[i] = &t
 	}
 
@@ -1118,6 +1419,12 @@ func (h *Handler) handleGetFragmentBlockData(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Index is only known after decoding the request.
+	if !h.allowed(r, req.Index, PermissionRead) {
+		http.Error(w, ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+
 	// Retrieve fragment from holder.
 	f := h.Holder.Fragment(req.Index, req.Frame, req.View, req.Slice)
 	if f == nil {
@@ -1325,6 +1632,10 @@ type QueryRequest struct {
 	// If true, indicates that query is part of a larger distributed query.
 	// If false, this request is on the originating node.
 	Remote bool
+
+	// If true, the slices read by a remote query are compared against the
+	// other replicas. Set by the originating node.
+	ReadRepair bool
 }
 
 func decodeQueryRequest(pb *internal.QueryRequest) *QueryRequest {
@@ -1334,6 +1645,7 @@ func decodeQueryRequest(pb *internal.QueryRequest) *QueryRequest {
 		ColumnAttrs: pb.ColumnAttrs,
 		Quantum:     TimeQuantum(pb.Quantum),
 		Remote:      pb.Remote,
+		ReadRepair:  pb.ReadRepair,
 	}
 
 	return req
@@ -1382,6 +1694,8 @@ func encodeQueryResponse(resp *QueryResponse) *internal.QueryResponse {
 			pb.Results[i].Bitmap = encodeBitmap(result)
 		case []Pair:
 			pb.Results[i].Pairs = encodePairs(result)
+		case []TimeCount:
+			pb.Results[i].TimeCounts = encodeTimeCounts(result)
 		case uint64:
 			pb.Results[i].N = result
 		case bool:
@@ -73,7 +73,7 @@ func TestHandler_Schema(t *testing.T) {
 	h.ServeHTTP(w, MustNewHTTPRequest("GET", "/schema", nil))
 	if w.Code != http.StatusOK {
 		t.Fatalf("unexpected status code: %d", w.Code)
-	} else if body := w.Body.String(); body != `{"indexes":[{"name":"i0","frames":[{"name":"f0"},{"name":"f1","views":[{"name":"inverse"},{"name":"standard"}]}]},{"name":"i1","frames":[{"name":"f0","views":[{"name":"standard"}]}]}]}`+"\n" {
+	} else if body := w.Body.String(); body != `{"indexes":[{"name":"i0","sliceWidth":1048576,"frames":[{"name":"f0"},{"name":"f1","views":[{"name":"inverse"},{"name":"standard"}]}]},{"name":"i1","sliceWidth":1048576,"frames":[{"name":"f0","views":[{"name":"standard"}]}]}]}`+"\n" {
 		t.Fatalf("unexpected body: %s", body)
 	}
 }
@@ -561,6 +561,51 @@ func TestHandler_DeleteFrame(t *testing.T) {
 	}
 }
 
+// Ensure handler can delete a view.
+func TestHandler_DeleteView(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	f := hldr.MustCreateFrameIfNotExists("i0", "f1")
+	if _, err := f.CreateViewIfNotExists("standard_2017"); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler()
+	h.Holder = hldr.Holder
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("DELETE", "/index/i0/frame/f1/view/standard_2017", strings.NewReader("")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if body := w.Body.String(); body != `{}`+"\n" {
+		t.Fatalf("unexpected body: %s", body)
+	} else if v := f.View("standard_2017"); v != nil {
+		t.Fatal("expected nil view")
+	}
+}
+
+// Ensure handler can set the frame retention.
+func TestHandler_SetFrameRetention(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFrameIfNotExists("i0", "f1")
+
+	h := NewHandler()
+	h.Holder = hldr.Holder
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("PATCH", "/index/i0/frame/f1/retention", strings.NewReader(`{"retention":"H=7d"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if r := hldr.Frame("i0", "f1").Retention(); r != pilosa.Retention("H=7d") {
+		t.Fatalf("unexpected retention: %s", r)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("PATCH", "/index/i0/frame/f1/retention", strings.NewReader(`{"retention":"H=bad"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+}
+
 // Ensure handler can set the Index time quantum.
 func TestHandler_SetIndexTimeQuantum(t *testing.T) {
 	hldr := MustOpenHolder()
@@ -766,6 +811,78 @@ func TestHandler_Version(t *testing.T) {
 	}
 }
 
+// Ensure the handler reports the holder's load state.
+func TestHandler_Health(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	h := NewHandler()
+	h.Holder = hldr.Holder
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("GET", "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if w.Body.String() != `{"state":"READY","fragmentsLoaded":0,"fragmentsTotal":0}`+"\n" {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	}
+}
+
+// Ensure the handler can trigger anti-entropy for part of the holder.
+func TestHandler_Sync(t *testing.T) {
+	var opt pilosa.SyncOptions
+	h := NewHandler()
+	h.AntiEntropyHandler = &HandlerAntiEntropy{
+		TriggerSyncFn: func(o pilosa.SyncOptions) error {
+			opt = o
+			return nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/sync?index=i&frame=f&start=2&end=5", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if !reflect.DeepEqual(opt, pilosa.SyncOptions{Index: "i", Frame: "f", SliceStart: 2, SliceEnd: 5}) {
+		t.Fatalf("unexpected options: %+v", opt)
+	}
+}
+
+// Ensure the handler rejects an invalid slice range.
+func TestHandler_Sync_ErrInvalidRange(t *testing.T) {
+	h := NewHandler()
+	h.AntiEntropyHandler = &HandlerAntiEntropy{}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/sync?index=i&start=5&end=2", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if body := w.Body.String(); body != "invalid end slice\n" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
+// Ensure the handler can return the anti-entropy status.
+func TestHandler_SyncStatus(t *testing.T) {
+	h := NewHandler()
+	h.AntiEntropyHandler = &HandlerAntiEntropy{
+		SyncStatusFn: func() pilosa.SyncStatus {
+			return pilosa.SyncStatus{Running: true, Index: "i", FragmentN: 10, FragmentsDone: 3}
+		},
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("GET", "/sync/status", nil))
+	var status pilosa.SyncStatus
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	} else if !status.Running || status.Index != "i" || status.FragmentN != 10 || status.FragmentsDone != 3 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
 // Ensure the handler can return a list of nodes for a fragment.
 func TestHandler_Fragment_Nodes(t *testing.T) {
 	h := NewHandler()
@@ -825,6 +942,15 @@ func (c *HandlerExecutor) Execute(ctx context.Context, index string, query *pql.
 	return c.ExecuteFn(ctx, index, query, slices, opt)
 }
 
+// HandlerAntiEntropy is a mock implementing pilosa.AntiEntropyHandler.
+type HandlerAntiEntropy struct {
+	TriggerSyncFn func(opt pilosa.SyncOptions) error
+	SyncStatusFn  func() pilosa.SyncStatus
+}
+
+func (h *HandlerAntiEntropy) TriggerSync(opt pilosa.SyncOptions) error { return h.TriggerSyncFn(opt) }
+func (h *HandlerAntiEntropy) SyncStatus() pilosa.SyncStatus            { return h.SyncStatusFn() }
+
 // Server represents a test wrapper for httptest.Server.
 type Server struct {
 	*httptest.Server
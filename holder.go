@@ -24,12 +24,44 @@ import (
 	"path/filepath"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/pilosa/pilosa/internal"
+	"golang.org/x/sync/errgroup"
 )
 
 // DefaultCacheFlushInterval is the default value for Fragment.CacheFlushInterval.
 const DefaultCacheFlushInterval = 1 * time.Minute
 
+// DefaultSnapshotConcurrency is the default value for Holder.SnapshotConcurrency.
+const DefaultSnapshotConcurrency = 2
+
+// DefaultMaxOpenFragments is the default value for Holder.MaxOpenFragments.
+const DefaultMaxOpenFragments = 0
+
+// DefaultFragmentIdleTimeout is the default value for Holder.FragmentIdleTimeout.
+const DefaultFragmentIdleTimeout = 0
+
+// DefaultRowCacheMaxBytes is the default value for Holder.RowCacheMaxBytes.
+const DefaultRowCacheMaxBytes = 1 << 30
+
+// DefaultOpenConcurrency is the default value for Holder.OpenConcurrency.
+const DefaultOpenConcurrency = 8
+
+// DefaultRetentionCheckInterval is the default value for Holder.RetentionCheckInterval.
+const DefaultRetentionCheckInterval = 1 * time.Hour
+
+// holderProgressInterval is the interval between progress log messages
+// while the holder is loading fragments.
+const holderProgressInterval = 10 * time.Second
+
+// Holder load states.
+const (
+	HolderStateStarting = "STARTING"
+	HolderStateReady    = "READY"
+)
+
 // Holder represents a container for indexes.
 type Holder struct {
 	mu sync.Mutex
@@ -37,6 +69,20 @@ type Holder struct {
 	// Indexes by name.
 	indexes map[string]*Index
 
+	// Limits the number of concurrent background snapshots.
+	snapshotQueue chan struct{}
+
+	// Tracks open fragments so idle & least recently used ones can be closed.
+	fragmentPool *fragmentPool
+
+	// Tracks bytes used by all fragment row caches.
+	rowCacheBudget *bitmapCacheBudget
+
+	// Fragment loading progress. Accessed atomically.
+	loading      int32
+	loadedN      int64
+	loadingTotal int64
+
 	Broadcaster Broadcaster
 	// Close management
 	wg      sync.WaitGroup
@@ -51,6 +97,38 @@ type Holder struct {
 	// The interval at which the cached row ids are persisted to disk.
 	CacheFlushInterval time.Duration
 
+	// Fsync policy for fragment writes & snapshots.
+	Durability Durability
+
+	// Maximum number of fragments snapshotting in the background at once.
+	// Zero is unlimited.
+	SnapshotConcurrency int
+
+	// Maximum number of fragments open at once. The least recently used
+	// fragments are closed when the limit is exceeded. Zero is unlimited.
+	MaxOpenFragments int
+
+	// Duration after which an unused fragment is closed. Zero never closes.
+	FragmentIdleTimeout time.Duration
+
+	// Maximum number of bytes of rows cached by all fragments and by each
+	// fragment. Zero is unlimited.
+	RowCacheMaxBytes         int64
+	FragmentRowCacheMaxBytes int64
+
+	// Maximum number of indexes, frames or fragments opened in parallel by
+	// each level during Open.
+	OpenConcurrency int
+
+	// If set, all fragments are opened in the background after Open instead
+	// of on first access. The holder reports a starting state until done.
+	// Fragments beyond MaxOpenFragments are not preloaded.
+	PreloadFragments bool
+
+	// Interval between deleting time views past their frame's retention.
+	// Zero disables retention.
+	RetentionCheckInterval time.Duration
+
 	LogOutput io.Writer
 }
 
@@ -64,6 +142,17 @@ func NewHolder() *Holder {
 		Stats:       NopStatsClient,
 
 		CacheFlushInterval: DefaultCacheFlushInterval,
+		Durability:         Durability{Mode: DurabilityNone, Interval: DefaultGroupCommitInterval},
+
+		SnapshotConcurrency: DefaultSnapshotConcurrency,
+		MaxOpenFragments:    DefaultMaxOpenFragments,
+		FragmentIdleTimeout: DefaultFragmentIdleTimeout,
+		OpenConcurrency:     DefaultOpenConcurrency,
+
+		RetentionCheckInterval: DefaultRetentionCheckInterval,
+
+		RowCacheMaxBytes:         DefaultRowCacheMaxBytes,
+		FragmentRowCacheMaxBytes: DefaultFragmentRowCacheMaxBytes,
 
 		LogOutput: os.Stderr,
 	}
@@ -71,6 +160,16 @@ func NewHolder() *Holder {
 
 // Open initializes the root data directory for the holder.
 func (h *Holder) Open() error {
+	if err := h.Durability.Validate(); err != nil {
+		return err
+	}
+
+	if h.SnapshotConcurrency > 0 {
+		h.snapshotQueue = make(chan struct{}, h.SnapshotConcurrency)
+	}
+	h.fragmentPool = newFragmentPool(h.MaxOpenFragments, h.FragmentIdleTimeout)
+	h.rowCacheBudget = newBitmapCacheBudget(h.RowCacheMaxBytes)
+
 	if err := os.MkdirAll(h.Path, 0777); err != nil {
 		return err
 	}
@@ -87,39 +186,174 @@ func (h *Holder) Open() error {
 		return err
 	}
 
+	// Open indexes in parallel.
+	var g errgroup.Group
+	sem := newOpenSemaphore(h.OpenConcurrency)
 	for _, fi := range fis {
 		if !fi.IsDir() {
 			continue
 		}
 
-		h.logger().Printf("opening index: %s", filepath.Base(fi.Name()))
-
-		index, err := h.newIndex(h.IndexPath(filepath.Base(fi.Name())), filepath.Base(fi.Name()))
-		if err == ErrName {
-			h.logger().Printf("ERROR opening index: %s, err=%s", fi.Name(), err)
-			continue
-		} else if err != nil {
-			return err
-		}
-		if err := index.Open(); err != nil {
-			if err == ErrName {
-				h.logger().Printf("ERROR opening index: %s, err=%s", index.Name(), err)
-				continue
-			}
-			return fmt.Errorf("open index: name=%s, err=%s", index.Name(), err)
+		name := filepath.Base(fi.Name())
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			return h.openIndex(name)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		for _, index := range h.indexes {
+			index.Close()
 		}
-		h.indexes[index.Name()] = index
+		h.indexes = make(map[string]*Index)
+		return err
+	}
 
-		h.Stats.Count("indexN", 1)
+	// Open fragments in the background, if enabled.
+	if h.PreloadFragments {
+		atomic.StoreInt32(&h.loading, 1)
+		h.wg.Add(1)
+		go func() { defer h.wg.Done(); h.preloadFragments() }()
 	}
 
 	// Periodically flush cache.
 	h.wg.Add(1)
 	go func() { defer h.wg.Done(); h.monitorCacheFlush() }()
 
+	// Close idle fragments & fragments over the open limit.
+	if h.MaxOpenFragments > 0 || h.FragmentIdleTimeout > 0 {
+		h.wg.Add(1)
+		go func() { defer h.wg.Done(); h.monitorFragmentEviction() }()
+	}
+
+	// Periodically delete expired time views.
+	if h.RetentionCheckInterval > 0 {
+		h.wg.Add(1)
+		go func() { defer h.wg.Done(); h.monitorRetention() }()
+	}
+
 	return nil
 }
 
+// openIndex opens an existing index by name and adds it to the holder.
+// Indexes with invalid names are logged and skipped.
+func (h *Holder) openIndex(name string) error {
+	h.logger().Printf("opening index: %s", name)
+
+	index, err := h.newIndex(h.IndexPath(name), name)
+	if err == ErrName {
+		h.logger().Printf("ERROR opening index: %s, err=%s", name, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := index.Open(); err != nil {
+		if err == ErrName {
+			h.logger().Printf("ERROR opening index: %s, err=%s", index.Name(), err)
+			return nil
+		}
+		return fmt.Errorf("open index: name=%s, err=%s", index.Name(), err)
+	}
+
+	h.mu.Lock()
+	h.indexes[index.Name()] = index
+	h.mu.Unlock()
+
+	h.Stats.Count("indexN", 1)
+	return nil
+}
+
+// preloadFragments opens all fragments in parallel and reports progress.
+// This is run in a goroutine.
+func (h *Holder) preloadFragments() {
+	defer atomic.StoreInt32(&h.loading, 0)
+
+	var frags []*Fragment
+	for _, index := range h.Indexes() {
+		for _, frame := range index.Frames() {
+			for _, view := range frame.Views() {
+				frags = append(frags, view.Fragments()...)
+			}
+		}
+	}
+	if h.MaxOpenFragments > 0 && len(frags) > h.MaxOpenFragments {
+		frags = frags[:h.MaxOpenFragments]
+	}
+	atomic.StoreInt64(&h.loadingTotal, int64(len(frags)))
+	h.Stats.Gauge("loadingTotal", float64(len(frags)))
+
+	h.logger().Printf("loading fragments: n=%d", len(frags))
+	start := time.Now()
+
+	// Report progress periodically until loading is complete.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(holderProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				opened, total := h.LoadProgress()
+				h.logger().Printf("loading fragments: %d/%d", opened, total)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	sem := newOpenSemaphore(h.OpenConcurrency)
+	for _, frag := range frags {
+		select {
+		case <-h.closing:
+			wg.Wait()
+			return
+		default:
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(frag *Fragment) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := frag.ensureOpen(); err != nil {
+				h.logger().Printf("ERROR opening fragment: path=%s, err=%s", frag.Path(), err)
+			}
+			n := atomic.AddInt64(&h.loadedN, 1)
+			h.Stats.Gauge("loadedN", float64(n))
+		}(frag)
+	}
+	wg.Wait()
+
+	h.logger().Printf("loaded fragments: n=%d, elapsed=%s", len(frags), time.Since(start))
+}
+
+// newOpenSemaphore returns a semaphore allowing n parallel opens. At least one
+// open is always allowed.
+func newOpenSemaphore(n int) chan struct{} {
+	if n <= 0 {
+		n = 1
+	}
+	return make(chan struct{}, n)
+}
+
+// State returns HolderStateStarting while fragments are being loaded and
+// HolderStateReady afterward.
+func (h *Holder) State() string {
+	if atomic.LoadInt32(&h.loading) == 1 {
+		return HolderStateStarting
+	}
+	return HolderStateReady
+}
+
+// LoadProgress returns the number of fragments loaded and the total number
+// of fragments to load on startup.
+func (h *Holder) LoadProgress() (opened, total int) {
+	return int(atomic.LoadInt64(&h.loadedN)), int(atomic.LoadInt64(&h.loadingTotal))
+}
+
 // Close closes all open fragments.
 func (h *Holder) Close() error {
 	// Notify goroutines of closing and wait for completion.
@@ -132,6 +366,17 @@ func (h *Holder) Close() error {
 	return nil
 }
 
+// OpenFragmentN returns the number of fragments currently open.
+func (h *Holder) OpenFragmentN() int {
+	if h.fragmentPool == nil {
+		return 0
+	}
+	return h.fragmentPool.Len()
+}
+
+// RowCacheSize returns the number of bytes used by all fragment row caches.
+func (h *Holder) RowCacheSize() int64 { return h.rowCacheBudget.Size() }
+
 // MaxSlices returns MaxSlice map for all indexes.
 func (h *Holder) MaxSlices() map[string]uint64 {
 	a := make(map[string]uint64)
@@ -154,7 +399,7 @@ func (h *Holder) MaxInverseSlices() map[string]uint64 {
 func (h *Holder) Schema() []*IndexInfo {
 	var a []*IndexInfo
 	for _, index := range h.Indexes() {
-		di := &IndexInfo{Name: index.Name()}
+		di := &IndexInfo{Name: index.Name(), SliceWidth: index.SliceWidth()}
 		for _, frame := range index.Frames() {
 			fi := &FrameInfo{Name: frame.Name()}
 			for _, view := range frame.Views() {
@@ -215,8 +460,13 @@ func (h *Holder) CreateIndexIfNotExists(name string, opt IndexOptions) (*Index,
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	// Find index in cache first.
+	// Find index in cache first. The slice width cannot change once created.
 	if index := h.indexes[name]; index != nil {
+		if opt.SliceWidth != 0 && opt.SliceWidth != index.SliceWidth() {
+			return nil, ErrSliceWidthImmutable
+		} else if opt.StorageBackend != "" && opt.StorageBackend != index.StorageBackend() {
+			return nil, ErrStorageBackendImmutable
+		}
 		return index, nil
 	}
 
@@ -231,6 +481,10 @@ func (h *Holder) createIndex(name string, opt IndexOptions) (*Index, error) {
 	// Return index if it exists.
 	if index := h.index(name); index != nil {
 		return index, nil
+	} else if opt.SliceWidth != 0 && !ValidSliceWidth(opt.SliceWidth) {
+		return nil, ErrInvalidSliceWidth
+	} else if opt.StorageBackend != "" && !IsValidStorageBackend(opt.StorageBackend) {
+		return nil, ErrInvalidStorageBackend
 	}
 
 	// Otherwise create a new index.
@@ -246,6 +500,13 @@ func (h *Holder) createIndex(name string, opt IndexOptions) (*Index, error) {
 	// Update options.
 	index.SetColumnLabel(opt.ColumnLabel)
 	index.SetTimeQuantum(opt.TimeQuantum)
+	if err := index.setSliceWidth(opt.SliceWidth); err != nil {
+		index.Close()
+		return nil, err
+	} else if err := index.setStorageBackend(opt.StorageBackend); err != nil {
+		index.Close()
+		return nil, err
+	}
 
 	h.indexes[index.Name()] = index
 
@@ -261,7 +522,13 @@ func (h *Holder) newIndex(path, name string) (*Index, error) {
 	}
 	index.LogOutput = h.LogOutput
 	index.stats = h.Stats.WithTags(fmt.Sprintf("index:%s", index.Name()))
+	index.durability = h.Durability
+	index.snapshotQueue = h.snapshotQueue
+	index.fragmentPool = h.fragmentPool
+	index.rowCacheBudget = h.rowCacheBudget
+	index.rowCacheMaxBytes = h.FragmentRowCacheMaxBytes
 	index.broadcaster = h.Broadcaster
+	index.openConcurrency = h.OpenConcurrency
 	return index, nil
 }
 
@@ -333,10 +600,89 @@ func (h *Holder) monitorCacheFlush() {
 			return
 		case <-ticker.C:
 			h.flushCaches()
+			h.Stats.Gauge("rowCacheBytes", float64(h.RowCacheSize()))
 		}
 	}
 }
 
+// monitorFragmentEviction closes fragments when the number of open fragments
+// exceeds the limit and periodically closes idle fragments.
+// This is run in a goroutine.
+func (h *Holder) monitorFragmentEviction() {
+	var tick <-chan time.Time
+	if h.FragmentIdleTimeout > 0 {
+		ticker := time.NewTicker(h.FragmentIdleTimeout / 2)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-h.closing:
+			return
+		case <-h.fragmentPool.evict:
+		case <-tick:
+		}
+
+		h.fragmentPool.Evict()
+		h.Stats.Gauge("openFragmentN", float64(h.fragmentPool.Len()))
+	}
+}
+
+// monitorRetention periodically deletes time views past their retention.
+// This is run in a goroutine.
+func (h *Holder) monitorRetention() {
+	ticker := time.NewTicker(h.RetentionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.closing:
+			return
+		case <-ticker.C:
+			if _, err := h.DeleteExpiredViews(time.Now().UTC()); err != nil {
+				h.logger().Printf("error deleting expired views: %s", err)
+			}
+		}
+	}
+}
+
+// DeleteExpiredViews deletes the time views of all frames that are past the
+// frame's retention at now and notifies the rest of the cluster.
+// Returns the number of views deleted.
+func (h *Holder) DeleteExpiredViews(now time.Time) (int, error) {
+	var n int
+	for _, index := range h.Indexes() {
+		for _, frame := range index.Frames() {
+			for _, name := range frame.ExpiredViews(now) {
+				select {
+				case <-h.closing:
+					return n, nil
+				default:
+				}
+
+				if err := frame.DeleteView(name); err != nil {
+					return n, fmt.Errorf("delete view: index=%s, frame=%s, view=%s, err=%s", index.Name(), frame.Name(), name, err)
+				}
+				h.logger().Printf("deleted expired view: index=%s, frame=%s, view=%s", index.Name(), frame.Name(), name)
+				h.Stats.Count("expiredViewN", 1)
+				n++
+
+				// Other nodes expire views on their own schedule but are
+				// notified so they don't wait for their next check.
+				if err := h.Broadcaster.SendAsync(&internal.DeleteViewMessage{
+					Index: index.Name(),
+					Frame: frame.Name(),
+					View:  name,
+				}); err != nil {
+					h.logger().Printf("problem sending DeleteView message: %s", err)
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
 func (h *Holder) flushCaches() {
 	for _, index := range h.Indexes() {
 		for _, frame := range index.Frames() {
@@ -367,10 +713,36 @@ type HolderSyncer struct {
 	Host    string
 	Cluster *Cluster
 
+	// Limits the sync to part of the holder.
+	Options SyncOptions
+
+	// Limits the rate of data transferred while syncing blocks. Optional.
+	Limiter *RateLimiter
+
+	// Tracks the progress of the sync. Optional.
+	Progress *SyncProgress
+
 	// Signals that the sync should stop.
 	Closing <-chan struct{}
 }
 
+// SyncOptions limits a holder sync to a subset of the holder.
+type SyncOptions struct {
+	// Index & frame to sync. Empty values match all indexes or frames.
+	Index string
+	Frame string
+
+	// Range of slices to sync, [SliceStart, SliceEnd).
+	// A zero SliceEnd means there is no upper bound.
+	SliceStart uint64
+	SliceEnd   uint64
+}
+
+// matchSlice returns true if slice falls within the option's slice range.
+func (o *SyncOptions) matchSlice(slice uint64) bool {
+	return slice >= o.SliceStart && (o.SliceEnd == 0 || slice < o.SliceEnd)
+}
+
 // IsClosing returns true if the syncer has been marked to close.
 func (s *HolderSyncer) IsClosing() bool {
 	select {
@@ -383,8 +755,26 @@ func (s *HolderSyncer) IsClosing() bool {
 
 // SyncHolder compares the holder on host with the local holder and resolves differences.
 func (s *HolderSyncer) SyncHolder() error {
+	schema := s.schema()
+
+	// Determine the total number of fragments to sync for progress reporting.
+	var total int
+	for _, di := range schema {
+		slices := s.ownedSlices(di.Name)
+		for _, fi := range di.Frames {
+			total += len(fi.Views) * len(slices)
+		}
+	}
+	s.Progress.start(total)
+
+	err := s.syncHolder(schema)
+	s.Progress.finish(err)
+	return err
+}
+
+func (s *HolderSyncer) syncHolder(schema []*IndexInfo) error {
 	// Iterate over schema in sorted order.
-	for _, di := range s.Holder.Schema() {
+	for _, di := range schema {
 		// Verify syncer has not closed.
 		if s.IsClosing() {
 			return nil
@@ -395,6 +785,7 @@ func (s *HolderSyncer) SyncHolder() error {
 			return fmt.Errorf("index sync error: index=%s, err=%s", di.Name, err)
 		}
 
+		slices := s.ownedSlices(di.Name)
 		for _, fi := range di.Frames {
 			// Verify syncer has not closed.
 			if s.IsClosing() {
@@ -407,26 +798,18 @@ func (s *HolderSyncer) SyncHolder() error {
 			}
 
 			for _, vi := range fi.Views {
-				// Verify syncer has not closed.
-				if s.IsClosing() {
-					return nil
-				}
-
-				for slice := uint64(0); slice <= s.Holder.Index(di.Name).MaxSlice(); slice++ {
-					// Ignore slices that this host doesn't own.
-					if !s.Cluster.OwnsFragment(s.Host, di.Name, slice) {
-						continue
-					}
-
+				for _, slice := range slices {
 					// Verify syncer has not closed.
 					if s.IsClosing() {
 						return nil
 					}
 
 					// Sync fragment if own it.
+					s.Progress.setFragment(di.Name, fi.Name, vi.Name, slice)
 					if err := s.syncFragment(di.Name, fi.Name, vi.Name, slice); err != nil {
 						return fmt.Errorf("fragment sync error: index=%s, frame=%s, slice=%d, err=%s", di.Name, fi.Name, slice, err)
 					}
+					s.Progress.fragmentDone()
 				}
 			}
 		}
@@ -435,6 +818,47 @@ func (s *HolderSyncer) SyncHolder() error {
 	return nil
 }
 
+// schema returns the holder schema filtered by the sync options.
+func (s *HolderSyncer) schema() []*IndexInfo {
+	var a []*IndexInfo
+	for _, di := range s.Holder.Schema() {
+		if s.Options.Index != "" && di.Name != s.Options.Index {
+			continue
+		}
+
+		if s.Options.Frame != "" {
+			var frames []*FrameInfo
+			for _, fi := range di.Frames {
+				if fi.Name == s.Options.Frame {
+					frames = append(frames, fi)
+				}
+			}
+			di.Frames = frames
+		}
+		a = append(a, di)
+	}
+	return a
+}
+
+// ownedSlices returns the slices in the sync range that this host owns for an index.
+func (s *HolderSyncer) ownedSlices(index string) []uint64 {
+	idx := s.Holder.Index(index)
+	if idx == nil {
+		return nil
+	}
+
+	var a []uint64
+	for slice := uint64(0); slice <= idx.MaxSlice(); slice++ {
+		if !s.Options.matchSlice(slice) {
+			continue
+		} else if !s.Cluster.OwnsFragment(s.Host, index, slice) {
+			continue
+		}
+		a = append(a, slice)
+	}
+	return a
+}
+
 // syncIndex synchronizes index attributes with the rest of the cluster.
 func (s *HolderSyncer) syncIndex(index string) error {
 	// Retrieve index reference.
@@ -451,7 +875,7 @@ func (s *HolderSyncer) syncIndex(index string) error {
 
 	// Sync with every other host.
 	for _, node := range Nodes(s.Cluster.Nodes).FilterHost(s.Host) {
-		client, err := NewClient(node.Host)
+		client, err := s.Cluster.NodeClient(node.Host)
 		if err != nil {
 			return err
 		}
@@ -496,7 +920,7 @@ func (s *HolderSyncer) syncFrame(index, name string) error {
 
 	// Sync with every other host.
 	for _, node := range Nodes(s.Cluster.Nodes).FilterHost(s.Host) {
-		client, err := NewClient(node.Host)
+		client, err := s.Cluster.NodeClient(node.Host)
 		if err != nil {
 			return err
 		}
@@ -552,6 +976,8 @@ func (s *HolderSyncer) syncFragment(index, frame, view string, slice uint64) err
 		Fragment: frag,
 		Host:     s.Host,
 		Cluster:  s.Cluster,
+		Limiter:  s.Limiter,
+		Progress: s.Progress,
 		Closing:  s.Closing,
 	}
 	if err := fs.SyncFragment(); err != nil {
@@ -560,3 +986,145 @@ func (s *HolderSyncer) syncFragment(index, frame, view string, slice uint64) err
 
 	return nil
 }
+
+// SyncProgress tracks the progress of a holder sync.
+// All methods are safe to call on a nil SyncProgress.
+type SyncProgress struct {
+	mu     sync.Mutex
+	status SyncStatus
+}
+
+// SyncStatus is a point-in-time view of the progress of a holder sync.
+type SyncStatus struct {
+	Running     bool      `json:"running"`
+	StartedAt   time.Time `json:"startedAt,omitempty"`
+	CompletedAt time.Time `json:"completedAt,omitempty"`
+	Error       string    `json:"error,omitempty"`
+
+	// Fragment currently being synced.
+	Index string `json:"index,omitempty"`
+	Frame string `json:"frame,omitempty"`
+	View  string `json:"view,omitempty"`
+	Slice uint64 `json:"slice"`
+
+	FragmentN      int   `json:"fragmentN"`
+	FragmentsDone  int   `json:"fragmentsDone"`
+	BlocksCompared int64 `json:"blocksCompared"`
+	BlocksRepaired int64 `json:"blocksRepaired"`
+	BitsRepaired   int64 `json:"bitsRepaired"`
+}
+
+// Status returns a copy of the current sync status.
+func (p *SyncProgress) Status() SyncStatus {
+	if p == nil {
+		return SyncStatus{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// start resets the progress for a new sync of n fragments.
+func (p *SyncProgress) start(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status = SyncStatus{
+		Running:   true,
+		StartedAt: time.Now(),
+		FragmentN: n,
+	}
+}
+
+// finish marks the sync as complete.
+func (p *SyncProgress) finish(err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Running = false
+	p.status.CompletedAt = time.Now()
+	if err != nil {
+		p.status.Error = err.Error()
+	}
+}
+
+// setFragment records the fragment currently being synced.
+func (p *SyncProgress) setFragment(index, frame, view string, slice uint64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Index, p.status.Frame, p.status.View, p.status.Slice = index, frame, view, slice
+}
+
+// fragmentDone increments the number of synced fragments.
+func (p *SyncProgress) fragmentDone() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.FragmentsDone++
+}
+
+// addBlocks increments the block and bit counters.
+func (p *SyncProgress) addBlocks(compared, repaired, bits int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.BlocksCompared += compared
+	p.status.BlocksRepaired += repaired
+	p.status.BitsRepaired += bits
+}
+
+// RateLimiter limits the throughput of an operation to a number of bytes per second.
+// All methods are safe to call on a nil RateLimiter, which does not limit.
+type RateLimiter struct {
+	mu   sync.Mutex
+	rate float64 // bytes per second
+	next time.Time
+}
+
+// NewRateLimiter returns a limiter allowing n bytes per second.
+// Returns nil if n is not positive.
+func NewRateLimiter(n int) *RateLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return &RateLimiter{rate: float64(n)}
+}
+
+// Wait reserves n bytes and blocks until the reservation can be used or
+// until closing is signaled.
+func (l *RateLimiter) Wait(n int, closing <-chan struct{}) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(float64(n) / l.rate * float64(time.Second)))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-closing:
+	case <-timer.C:
+	}
+}
@@ -20,7 +20,9 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/pilosa/pilosa"
 	"github.com/pilosa/pilosa/pql"
@@ -59,6 +61,305 @@ func TestHolder_DeleteIndex(t *testing.T) {
 	}
 }
 
+// Ensure holder opens fragments on first access.
+func TestHolder_Open_Lazy(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	for _, slice := range []uint64{0, 1, 2} {
+		if _, err := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).SetBit(100, slice*pilosa.SliceWidth+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hldr.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure fragments are not opened but slice metadata is available.
+	if n := hldr.OpenFragmentN(); n != 0 {
+		t.Fatalf("unexpected open fragments: %d", n)
+	} else if m := hldr.MaxSlices(); m["i"] != 2 {
+		t.Fatalf("unexpected max slice: %d", m["i"])
+	}
+
+	// Ensure fragment is opened on access.
+	frag := hldr.Fragment("i", "f", pilosa.ViewStandard, 1)
+	if a := frag.Row(100).Bits(); !reflect.DeepEqual(a, []uint64{pilosa.SliceWidth + 1}) {
+		t.Fatalf("unexpected bits: %+v", a)
+	} else if n := hldr.OpenFragmentN(); n != 1 {
+		t.Fatalf("unexpected open fragments: %d", n)
+	}
+}
+
+// Ensure holder closes the least recently used fragments over the open limit.
+func TestHolder_MaxOpenFragments(t *testing.T) {
+	hldr := NewHolder()
+	hldr.MaxOpenFragments = 2
+	if err := hldr.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer hldr.Close()
+
+	for _, slice := range []uint64{0, 1, 2, 3} {
+		if _, err := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).SetBit(100, slice*pilosa.SliceWidth+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Wait for eviction.
+	for i := 0; hldr.OpenFragmentN() > 2; i++ {
+		if i > 100 {
+			t.Fatalf("unexpected open fragments: %d", hldr.OpenFragmentN())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Ensure evicted fragments are reopened with their data.
+	for _, slice := range []uint64{0, 1, 2, 3} {
+		frag := hldr.Fragment("i", "f", pilosa.ViewStandard, slice)
+		if a := frag.Row(100).Bits(); !reflect.DeepEqual(a, []uint64{slice*pilosa.SliceWidth + 1}) {
+			t.Fatalf("unexpected bits(%d): %+v", slice, a)
+		}
+	}
+}
+
+// Ensure holder opens the frames & fragments of an index in parallel.
+func TestHolder_Open_Parallel(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	frames := []string{"f0", "f1", "f2", "f3"}
+	for _, frame := range frames {
+		for _, slice := range []uint64{0, 1, 2, 3} {
+			if _, err := hldr.MustCreateFragmentIfNotExists("i", frame, pilosa.ViewStandard, slice).SetBit(100, slice*pilosa.SliceWidth+1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	hldr.OpenConcurrency = 2
+	if err := hldr.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, frame := range frames {
+		for _, slice := range []uint64{0, 1, 2, 3} {
+			frag := hldr.Fragment("i", frame, pilosa.ViewStandard, slice)
+			if frag == nil {
+				t.Fatalf("expected fragment: %s/%d", frame, slice)
+			} else if a := frag.Row(100).Bits(); !reflect.DeepEqual(a, []uint64{slice*pilosa.SliceWidth + 1}) {
+				t.Fatalf("unexpected bits(%s/%d): %+v", frame, slice, a)
+			}
+		}
+	}
+}
+
+// Ensure holder can open all fragments in the background on startup.
+func TestHolder_PreloadFragments(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	for _, slice := range []uint64{0, 1, 2} {
+		if _, err := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).SetBit(100, slice*pilosa.SliceWidth+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hldr.PreloadFragments = true
+	if err := hldr.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for loading to complete.
+	for i := 0; hldr.State() != pilosa.HolderStateReady; i++ {
+		if i > 100 {
+			t.Fatalf("unexpected state: %s", hldr.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if opened, total := hldr.LoadProgress(); opened != 3 || total != 3 {
+		t.Fatalf("unexpected progress: %d/%d", opened, total)
+	} else if n := hldr.OpenFragmentN(); n != 3 {
+		t.Fatalf("unexpected open fragments: %d", n)
+	}
+}
+
+// Ensure the rows cached by all fragments stay within the holder's budget.
+func TestHolder_RowCacheMaxBytes(t *testing.T) {
+	hldr := NewHolder()
+	hldr.RowCacheMaxBytes = 1000
+	if err := hldr.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer hldr.Close()
+
+	for _, slice := range []uint64{0, 1} {
+		frag := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice)
+		for rowID := uint64(0); rowID < 200; rowID++ {
+			if _, err := frag.SetBit(rowID, slice*pilosa.SliceWidth+rowID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Read all rows & ensure they are correct and the cache is bounded.
+	for _, slice := range []uint64{0, 1} {
+		frag := hldr.Fragment("i", "f", pilosa.ViewStandard, slice)
+		for rowID := uint64(0); rowID < 200; rowID++ {
+			if a := frag.Row(rowID).Bits(); !reflect.DeepEqual(a, []uint64{slice*pilosa.SliceWidth + rowID}) {
+				t.Fatalf("unexpected bits(%d/%d): %+v", slice, rowID, a)
+			}
+		}
+	}
+	if n := hldr.RowCacheSize(); n == 0 || n > 1000 {
+		t.Fatalf("unexpected row cache size: %d", n)
+	}
+}
+
+// Ensure an index persists its slice width and places bits by that width.
+func TestHolder_CreateIndex_SliceWidth(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	if _, err := hldr.CreateIndex("i", pilosa.IndexOptions{SliceWidth: 1000}); err != pilosa.ErrInvalidSliceWidth {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := hldr.CreateIndex("i", pilosa.IndexOptions{SliceWidth: 1 << 16}); err != nil {
+		t.Fatal(err)
+	} else if _, err := hldr.CreateIndex("j", pilosa.IndexOptions{SliceWidth: 1 << 22}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Narrow slices split columns across more fragments.
+	f := hldr.MustCreateFrameIfNotExists("i", "f")
+	if _, err := f.SetBit(pilosa.ViewStandard, 1, 70000, nil); err != nil {
+		t.Fatal(err)
+	} else if frag := f.View(pilosa.ViewStandard).Fragment(1); frag == nil {
+		t.Fatal("expected fragment for slice 1")
+	}
+
+	// Wide slices hold rows spanning several bitmap segments.
+	g := hldr.MustCreateFrameIfNotExists("j", "f")
+	if _, err := g.SetBit(pilosa.ViewStandard, 1, 5, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := g.SetBit(pilosa.ViewStandard, 1, (3<<20)+1, nil); err != nil {
+		t.Fatal(err)
+	}
+	row := g.View(pilosa.ViewStandard).Fragment(0).Row(1)
+	if a := row.Bits(); !reflect.DeepEqual(a, []uint64{5, (3 << 20) + 1}) {
+		t.Fatalf("unexpected bits: %+v", a)
+	} else if n := row.IntersectionCount(pilosa.NewBitmap((3<<20)+1, 7)); n != 1 {
+		t.Fatalf("unexpected intersection count: %d", n)
+	}
+
+	// Reopen and ensure the slice widths are unchanged.
+	if err := hldr.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if w := hldr.Index("i").SliceWidth(); w != 1<<16 {
+		t.Fatalf("unexpected slice width: %d", w)
+	} else if a := hldr.Fragment("i", "f", pilosa.ViewStandard, 1).Row(1).Bits(); !reflect.DeepEqual(a, []uint64{70000}) {
+		t.Fatalf("unexpected bits: %+v", a)
+	}
+
+	// The slice width cannot be changed after creation.
+	if _, err := hldr.CreateIndexIfNotExists("i", pilosa.IndexOptions{SliceWidth: 1 << 20}); err != pilosa.ErrSliceWidthImmutable {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure an index can keep its fragments in memory.
+func TestHolder_CreateIndex_StorageBackend(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	if _, err := hldr.CreateIndex("i", pilosa.IndexOptions{StorageBackend: "foo"}); err != pilosa.ErrInvalidStorageBackend {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := hldr.CreateIndex("i", pilosa.IndexOptions{StorageBackend: pilosa.StorageBackendMemory}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := hldr.MustCreateFrameIfNotExists("i", "f")
+	if _, err := f.SetBit(pilosa.ViewStandard, 1, 10, nil); err != nil {
+		t.Fatal(err)
+	}
+	frag := f.View(pilosa.ViewStandard).Fragment(0)
+	if a := frag.Row(1).Bits(); !reflect.DeepEqual(a, []uint64{10}) {
+		t.Fatalf("unexpected bits: %+v", a)
+	} else if _, err := os.Stat(frag.Path()); !os.IsNotExist(err) {
+		t.Fatalf("unexpected data file: %v", err)
+	}
+
+	// Reopen and ensure the backend is unchanged but the data is gone.
+	if err := hldr.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if b := hldr.Index("i").StorageBackend(); b != pilosa.StorageBackendMemory {
+		t.Fatalf("unexpected storage backend: %s", b)
+	} else if frag := hldr.Fragment("i", "f", pilosa.ViewStandard, 0); frag != nil {
+		t.Fatalf("unexpected fragment: %+v", frag)
+	}
+
+	// The storage backend cannot be changed after creation.
+	if _, err := hldr.CreateIndexIfNotExists("i", pilosa.IndexOptions{StorageBackend: pilosa.StorageBackendMmap}); err != pilosa.ErrStorageBackendImmutable {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure the holder deletes time views past their frame's retention.
+func TestHolder_DeleteExpiredViews(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	idx := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := idx.CreateFrame("f", pilosa.FrameOptions{TimeQuantum: "YMDH", Retention: "H=24h,D=7d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Date(2017, time.March, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := f.SetBit(pilosa.ViewStandard, 1, 1, &ts); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing expires within the retention.
+	if n, err := hldr.DeleteExpiredViews(ts.Add(24 * time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("unexpected expired view count: %d", n)
+	}
+
+	// Hourly views expire first, then daily views. Yearly & monthly views
+	// and the standard view are kept.
+	if n, err := hldr.DeleteExpiredViews(ts.Add(25 * time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("unexpected expired view count: %d", n)
+	} else if f.View("standard_2017030110") != nil {
+		t.Fatal("expected hourly view to be deleted")
+	} else if _, err := os.Stat(f.ViewPath("standard_2017030110")); !os.IsNotExist(err) {
+		t.Fatalf("unexpected view directory: %v", err)
+	}
+
+	if n, err := hldr.DeleteExpiredViews(ts.AddDate(0, 0, 8)); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("unexpected expired view count: %d", n)
+	}
+
+	var names []string
+	for _, v := range f.Views() {
+		names = append(names, v.Name())
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"standard", "standard_2017", "standard_201703"}) {
+		t.Fatalf("unexpected views: %v", names)
+	}
+
+	// The retention is kept when the holder is reopened.
+	if err := hldr.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if r := hldr.Frame("i", "f").Retention(); r != "H=24h,D=7d" {
+		t.Fatalf("unexpected retention: %s", r)
+	}
+}
+
 // Ensure holder can sync with a remote holder.
 func TestHolderSyncer_SyncHolder(t *testing.T) {
 	cluster := NewCluster(2)
@@ -136,16 +437,27 @@ func TestHolderSyncer_SyncHolder(t *testing.T) {
 	hldr0.Index("y").SetRemoteMaxSlice(3)
 
 	// Set up syncer.
+	var progress pilosa.SyncProgress
 	syncer := pilosa.HolderSyncer{
-		Holder:  hldr0.Holder,
-		Host:    cluster.Nodes[0].Host,
-		Cluster: cluster,
+		Holder:   hldr0.Holder,
+		Host:     cluster.Nodes[0].Host,
+		Cluster:  cluster,
+		Progress: &progress,
 	}
 
 	if err := syncer.SyncHolder(); err != nil {
 		t.Fatal(err)
 	}
 
+	// Verify progress was reported.
+	if status := progress.Status(); status.Running {
+		t.Fatal("expected sync to be complete")
+	} else if status.FragmentN == 0 || status.FragmentsDone != status.FragmentN {
+		t.Fatalf("unexpected fragment progress: %d/%d", status.FragmentsDone, status.FragmentN)
+	} else if status.BlocksRepaired == 0 || status.BitsRepaired == 0 {
+		t.Fatalf("unexpected repairs: blocks=%d, bits=%d", status.BlocksRepaired, status.BitsRepaired)
+	}
+
 	// Verify data is the same on both nodes.
 	for i, hldr := range []*Holder{hldr0, hldr1} {
 		f := hldr.Fragment("i", "f", pilosa.ViewStandard, 0)
@@ -211,6 +523,23 @@ func (h *Holder) Close() error {
 	return h.Holder.Close()
 }
 
+// Reopen closes the holder and reopens it with the same data and settings.
+func (h *Holder) Reopen() error {
+	if err := h.Holder.Close(); err != nil {
+		return err
+	}
+
+	other := pilosa.NewHolder()
+	other.Path = h.Path
+	other.LogOutput = h.Holder.LogOutput
+	other.MaxOpenFragments = h.MaxOpenFragments
+	other.FragmentIdleTimeout = h.FragmentIdleTimeout
+	other.PreloadFragments = h.PreloadFragments
+	other.OpenConcurrency = h.OpenConcurrency
+	h.Holder = other
+	return h.Open()
+}
+
 // MustCreateIndexIfNotExists returns a given index. Panic on error.
 func (h *Holder) MustCreateIndexIfNotExists(index string, opt pilosa.IndexOptions) *Index {
 	idx, err := h.Holder.CreateIndexIfNotExists(index, opt)
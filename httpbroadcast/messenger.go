@@ -16,6 +16,7 @@ package httpbroadcast
 
 import (
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -86,12 +87,18 @@ func (h *HTTPBroadcaster) nodes() ([]*pilosa.Node, error) {
 }
 
 func (h *HTTPBroadcaster) sendNodeMessage(node *pilosa.Node, msg []byte) error {
-	var client *http.Client
-	client = http.DefaultClient
+	// Use the cluster's scheme & client so messages are sent over TLS, if enabled.
+	client, scheme := h.server.Cluster.HTTPClient, h.server.Cluster.Scheme
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if scheme == "" {
+		scheme = "http"
+	}
 
 	// Create HTTP request.
 	req, err := http.NewRequest("POST", (&url.URL{
-		Scheme: "http",
+		Scheme: scheme,
 		Host:   node.InternalHost,
 	}).String(), bytes.NewReader(msg))
 
@@ -125,6 +132,10 @@ type HTTPBroadcastReceiver struct {
 	port      string
 	handler   pilosa.BroadcastHandler
 	logOutput io.Writer
+
+	// If set, messages are received over HTTPS. If the configuration has
+	// client CAs then senders must present a verified client certificate.
+	TLSConfig *tls.Config
 }
 
 // NewHTTPBroadcastReceiver returns a new instance of HTTPBroadcastReceiver.
@@ -139,7 +150,18 @@ func NewHTTPBroadcastReceiver(port string, logOutput io.Writer) *HTTPBroadcastRe
 func (rec *HTTPBroadcastReceiver) Start(b pilosa.BroadcastHandler) error {
 	rec.handler = b
 	go func() {
-		err := http.ListenAndServe(":"+rec.port, rec)
+		var err error
+		if rec.TLSConfig != nil {
+			// Only other nodes may send messages when using mutual TLS.
+			config := rec.TLSConfig.Clone()
+			if config.ClientCAs != nil {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			srv := &http.Server{Addr: ":" + rec.port, Handler: rec, TLSConfig: config}
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = http.ListenAndServe(":"+rec.port, rec)
+		}
 		if err != nil {
 			fmt.Fprintf(rec.logOutput, "Error listening on %v for HTTPBroadcastReceiver: %v\n", ":"+rec.port, err)
 		}
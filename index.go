@@ -27,6 +27,7 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pilosa/pilosa/internal"
+	"golang.org/x/sync/errgroup"
 )
 
 // Default index settings.
@@ -34,6 +35,18 @@ const (
 	DefaultColumnLabel = "columnID"
 )
 
+// Slice width limits. Slice widths must be a power of two so that each
+// row in a fragment begins on a roaring container boundary.
+const (
+	MinSliceWidth = 1 << 16
+	MaxSliceWidth = 1 << 40
+)
+
+// ValidSliceWidth returns true if v can be used as the slice width of an index.
+func ValidSliceWidth(v uint64) bool {
+	return v >= MinSliceWidth && v <= MaxSliceWidth && v&(v-1) == 0
+}
+
 // Index represents a container for frames.
 type Index struct {
 	mu   sync.Mutex
@@ -47,6 +60,12 @@ type Index struct {
 	// Label used for referring to columns in index.
 	columnLabel string
 
+	// Number of column IDs in a slice. Cannot change once data exists.
+	sliceWidth uint64
+
+	// Storage backend used by fragments. Cannot change once data exists.
+	storageBackend string
+
 	// Frames by name.
 	frames map[string]*Frame
 
@@ -59,6 +78,20 @@ type Index struct {
 
 	broadcaster Broadcaster
 	stats       StatsClient
+	durability  Durability
+
+	// Limits concurrent background snapshots. Shared by the holder.
+	snapshotQueue chan struct{}
+
+	// Tracks open fragments for eviction. Shared by the holder.
+	fragmentPool *fragmentPool
+
+	// Row cache limits. The budget is shared by the holder.
+	rowCacheBudget   *bitmapCacheBudget
+	rowCacheMaxBytes int64
+
+	// Number of frames or fragments opened in parallel. Set by the holder.
+	openConcurrency int
 
 	LogOutput io.Writer
 }
@@ -80,7 +113,9 @@ func NewIndex(path, name string) (*Index, error) {
 
 		columnAttrStore: NewAttrStore(filepath.Join(path, ".data")),
 
-		columnLabel: DefaultColumnLabel,
+		columnLabel:    DefaultColumnLabel,
+		sliceWidth:     SliceWidth,
+		storageBackend: DefaultStorageBackend,
 
 		broadcaster: NopBroadcaster,
 		stats:       NopStatsClient,
@@ -130,6 +165,75 @@ func (i *Index) ColumnLabel() string {
 	return v
 }
 
+// SliceWidth returns the number of column IDs in a slice.
+func (i *Index) SliceWidth() uint64 {
+	i.mu.Lock()
+	v := i.sliceWidth
+	i.mu.Unlock()
+	return v
+}
+
+// setSliceWidth sets the slice width of a newly created index. Persists to
+// meta file on update. Returns an error if the index already has frames.
+func (i *Index) setSliceWidth(v uint64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Ignore if no change occurred.
+	if v == 0 || i.sliceWidth == v {
+		return nil
+	}
+
+	if !ValidSliceWidth(v) {
+		return ErrInvalidSliceWidth
+	} else if len(i.frames) > 0 {
+		return ErrSliceWidthImmutable
+	}
+
+	// Persist meta data to disk on change.
+	i.sliceWidth = v
+	if err := i.saveMeta(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// StorageBackend returns the name of the storage backend used by fragments.
+func (i *Index) StorageBackend() string {
+	i.mu.Lock()
+	v := i.storageBackend
+	i.mu.Unlock()
+	return v
+}
+
+// setStorageBackend sets the storage backend of a newly created index.
+// Persists to meta file on update. Returns an error if the index already
+// has frames.
+func (i *Index) setStorageBackend(v string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Ignore if no change occurred.
+	if v == "" || i.storageBackend == v {
+		return nil
+	}
+
+	if !IsValidStorageBackend(v) {
+		return ErrInvalidStorageBackend
+	} else if len(i.frames) > 0 {
+		return ErrStorageBackendImmutable
+	}
+
+	// Persist meta data to disk on change.
+	i.storageBackend = v
+	if err := i.saveMeta(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Open opens and initializes the index.
 func (i *Index) Open() error {
 	// Ensure the path exists.
@@ -166,6 +270,10 @@ func (i *Index) openFrames() error {
 		return err
 	}
 
+	// Open frames in parallel.
+	var mu sync.Mutex
+	var g errgroup.Group
+	sem := newOpenSemaphore(i.openConcurrency)
 	for _, fi := range fis {
 		if !fi.IsDir() {
 			continue
@@ -175,14 +283,22 @@ func (i *Index) openFrames() error {
 		if err != nil {
 			return ErrName
 		}
-		if err := fr.Open(); err != nil {
-			return fmt.Errorf("open frame: name=%s, err=%s", fr.Name(), err)
-		}
-		i.frames[fr.Name()] = fr
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-		i.stats.Count("frameN", 1)
+			if err := fr.Open(); err != nil {
+				return fmt.Errorf("open frame: name=%s, err=%s", fr.Name(), err)
+			}
+			mu.Lock()
+			i.frames[fr.Name()] = fr
+			mu.Unlock()
+
+			i.stats.Count("frameN", 1)
+			return nil
+		})
 	}
-	return nil
+	return g.Wait()
 }
 
 // loadMeta reads meta data for the index, if any.
@@ -194,6 +310,8 @@ func (i *Index) loadMeta() error {
 	if os.IsNotExist(err) {
 		i.timeQuantum = ""
 		i.columnLabel = DefaultColumnLabel
+		i.sliceWidth = SliceWidth
+		i.storageBackend = DefaultStorageBackend
 		return nil
 	} else if err != nil {
 		return err
@@ -207,6 +325,17 @@ func (i *Index) loadMeta() error {
 	i.timeQuantum = TimeQuantum(pb.TimeQuantum)
 	i.columnLabel = pb.ColumnLabel
 
+	// Indexes created before the slice width was configurable use the default.
+	i.sliceWidth = pb.SliceWidth
+	if i.sliceWidth == 0 {
+		i.sliceWidth = SliceWidth
+	}
+
+	i.storageBackend = pb.StorageBackend
+	if i.storageBackend == "" {
+		i.storageBackend = DefaultStorageBackend
+	}
+
 	return nil
 }
 
@@ -214,8 +343,10 @@ func (i *Index) loadMeta() error {
 func (i *Index) saveMeta() error {
 	// Marshal metadata.
 	buf, err := proto.Marshal(&internal.IndexMeta{
-		TimeQuantum: string(i.timeQuantum),
-		ColumnLabel: i.columnLabel,
+		TimeQuantum:    string(i.timeQuantum),
+		ColumnLabel:    i.columnLabel,
+		SliceWidth:     i.sliceWidth,
+		StorageBackend: i.storageBackend,
 	})
 	if err != nil {
 		return err
@@ -380,6 +511,8 @@ func (i *Index) createFrame(name string, opt FrameOptions) (*Frame, error) {
 		return nil, errors.New("frame name required")
 	} else if opt.CacheType != "" && !IsValidCacheType(opt.CacheType) {
 		return nil, ErrInvalidCacheType
+	} else if !opt.Retention.Valid() {
+		return nil, ErrInvalidRetention
 	}
 
 	// Initialize frame.
@@ -417,7 +550,9 @@ func (i *Index) createFrame(name string, opt FrameOptions) (*Frame, error) {
 		f.cacheSize = opt.CacheSize
 	}
 
+	f.retention = opt.Retention
 	f.inverseEnabled = opt.InverseEnabled
+	f.mutex = opt.Mutex
 	if err := f.saveMeta(); err != nil {
 		f.Close()
 		return nil, err
@@ -438,7 +573,15 @@ func (i *Index) newFrame(path, name string) (*Frame, error) {
 	}
 	f.LogOutput = i.LogOutput
 	f.stats = i.stats.WithTags(fmt.Sprintf("frame:%s", name))
+	f.durability = i.durability
+	f.sliceWidth = i.sliceWidth
+	f.storageBackend = i.storageBackend
+	f.snapshotQueue = i.snapshotQueue
+	f.fragmentPool = i.fragmentPool
+	f.rowCacheBudget = i.rowCacheBudget
+	f.rowCacheMaxBytes = i.rowCacheMaxBytes
 	f.broadcaster = i.broadcaster
+	f.openConcurrency = i.openConcurrency
 	return f, nil
 }
 
@@ -479,8 +622,9 @@ func (p indexSlice) Less(i, j int) bool { return p[i].Name() < p[j].Name() }
 
 // IndexInfo represents schema information for an index.
 type IndexInfo struct {
-	Name   string       `json:"name"`
-	Frames []*FrameInfo `json:"frames"`
+	Name       string       `json:"name"`
+	SliceWidth uint64       `json:"sliceWidth,omitempty"`
+	Frames     []*FrameInfo `json:"frames"`
 }
 
 type indexInfoSlice []*IndexInfo
@@ -493,11 +637,15 @@ func (p indexInfoSlice) Less(i, j int) bool { return p[i].Name < p[j].Name }
 func MergeSchemas(a, b []*IndexInfo) []*IndexInfo {
 	// Generate a map from both schemas.
 	m := make(map[string]map[string]map[string]struct{})
+	sliceWidths := make(map[string]uint64)
 	for _, idxs := range [][]*IndexInfo{a, b} {
 		for _, idx := range idxs {
 			if m[idx.Name] == nil {
 				m[idx.Name] = make(map[string]map[string]struct{})
 			}
+			if idx.SliceWidth != 0 {
+				sliceWidths[idx.Name] = idx.SliceWidth
+			}
 			for _, frame := range idx.Frames {
 				if m[idx.Name][frame.Name] == nil {
 					m[idx.Name][frame.Name] = make(map[string]struct{})
@@ -512,7 +660,7 @@ func MergeSchemas(a, b []*IndexInfo) []*IndexInfo {
 	// Generate new schema from map.
 	idxs := make([]*IndexInfo, 0, len(m))
 	for idx, frames := range m {
-		di := &IndexInfo{Name: idx}
+		di := &IndexInfo{Name: idx, SliceWidth: sliceWidths[idx]}
 		for frame, views := range frames {
 			fi := &FrameInfo{Name: frame}
 			for view := range views {
@@ -543,8 +691,10 @@ func encodeIndex(d *Index) *internal.Index {
 	return &internal.Index{
 		Name: d.name,
 		Meta: &internal.IndexMeta{
-			ColumnLabel: d.columnLabel,
-			TimeQuantum: string(d.timeQuantum),
+			ColumnLabel:    d.columnLabel,
+			TimeQuantum:    string(d.timeQuantum),
+			SliceWidth:     d.SliceWidth(),
+			StorageBackend: d.StorageBackend(),
 		},
 		MaxSlice: d.MaxSlice(),
 		Frames:   encodeFrames(d.Frames()),
@@ -553,15 +703,19 @@ func encodeIndex(d *Index) *internal.Index {
 
 // IndexOptions represents options to set when initializing an index.
 type IndexOptions struct {
-	ColumnLabel string      `json:"columnLabel,omitempty"`
-	TimeQuantum TimeQuantum `json:"timeQuantum,omitempty"`
+	ColumnLabel    string      `json:"columnLabel,omitempty"`
+	TimeQuantum    TimeQuantum `json:"timeQuantum,omitempty"`
+	SliceWidth     uint64      `json:"sliceWidth,omitempty"`
+	StorageBackend string      `json:"storageBackend,omitempty"`
 }
 
 // Encode converts o into its internal representation.
 func (o *IndexOptions) Encode() *internal.IndexMeta {
 	return &internal.IndexMeta{
-		ColumnLabel: o.ColumnLabel,
-		TimeQuantum: string(o.TimeQuantum),
+		ColumnLabel:    o.ColumnLabel,
+		TimeQuantum:    string(o.TimeQuantum),
+		SliceWidth:     o.SliceWidth,
+		StorageBackend: o.StorageBackend,
 	}
 }
 
@@ -0,0 +1,107 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	_ "github.com/pilosa/pilosa/internal"
+)
+
+// Ensure the embedded file descriptors match the generated message types.
+func TestFileDescriptors(t *testing.T) {
+	for _, filename := range []string{"private.proto", "public.proto"} {
+		t.Run(filename, func(t *testing.T) {
+			fd := MustDecodeFileDescriptor(proto.FileDescriptor(filename))
+			for i, msg := range fd.MessageType {
+				typ := proto.MessageType(fd.GetPackage() + "." + msg.GetName())
+				if typ == nil {
+					t.Fatalf("message type not registered: %s", msg.GetName())
+				}
+
+				// Verify the message points back to its descriptor.
+				m := reflect.New(typ.Elem()).Interface().(interface {
+					Descriptor() ([]byte, []int)
+				})
+				if _, path := m.Descriptor(); !reflect.DeepEqual(path, []int{i}) {
+					t.Fatalf("unexpected descriptor path for %s: %v", msg.GetName(), path)
+				}
+
+				// Verify struct fields match the descriptor fields.
+				if fields, other := structFields(typ.Elem()), descriptorFields(msg); !reflect.DeepEqual(fields, other) {
+					t.Fatalf("field mismatch for %s: %v != %v", msg.GetName(), fields, other)
+				}
+			}
+		})
+	}
+}
+
+// MustDecodeFileDescriptor decompresses and decodes a file descriptor.
+func MustDecodeFileDescriptor(gz []byte) *descriptor.FileDescriptorProto {
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		panic(err)
+	}
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		panic(err)
+	}
+
+	var fd descriptor.FileDescriptorProto
+	if err := proto.Unmarshal(buf, &fd); err != nil {
+		panic(err)
+	}
+	return &fd
+}
+
+// structFields returns a map of field numbers to names from protobuf tags.
+func structFields(typ reflect.Type) map[int32]string {
+	m := make(map[int32]string)
+	for i := 0; i < typ.NumField(); i++ {
+		tag := typ.Field(i).Tag.Get("protobuf")
+		if tag == "" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		num, err := strconv.Atoi(parts[1])
+		if err != nil {
+			panic(err)
+		}
+		for _, part := range parts {
+			if strings.HasPrefix(part, "name=") {
+				m[int32(num)] = strings.TrimPrefix(part, "name=")
+			}
+		}
+	}
+	return m
+}
+
+// descriptorFields returns a map of field numbers to names from a descriptor.
+func descriptorFields(msg *descriptor.DescriptorProto) map[int32]string {
+	m := make(map[int32]string)
+	for _, field := range msg.Field {
+		m[field.GetNumber()] = field.GetName()
+	}
+	return m
+}
@@ -39,6 +39,8 @@
 		Index
 		NodeStatus
 		ClusterStatus
+		DeleteViewMessage
+		RollUpViewsMessage
 */
 package internal
 
@@ -60,8 +62,10 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type IndexMeta struct {
-	ColumnLabel string `protobuf:"bytes,1,opt,name=ColumnLabel,proto3" json:"ColumnLabel,omitempty"`
-	TimeQuantum string `protobuf:"bytes,2,opt,name=TimeQuantum,proto3" json:"TimeQuantum,omitempty"`
+	ColumnLabel    string `protobuf:"bytes,1,opt,name=ColumnLabel,proto3" json:"ColumnLabel,omitempty"`
+	TimeQuantum    string `protobuf:"bytes,2,opt,name=TimeQuantum,proto3" json:"TimeQuantum,omitempty"`
+	SliceWidth     uint64 `protobuf:"varint,3,opt,name=SliceWidth,proto3" json:"SliceWidth,omitempty"`
+	StorageBackend string `protobuf:"bytes,4,opt,name=StorageBackend,proto3" json:"StorageBackend,omitempty"`
 }
 
 func (m *IndexMeta) Reset()                    { *m = IndexMeta{} }
@@ -75,6 +79,8 @@ type FrameMeta struct {
 	CacheType      string `protobuf:"bytes,3,opt,name=CacheType,proto3" json:"CacheType,omitempty"`
 	CacheSize      uint32 `protobuf:"varint,4,opt,name=CacheSize,proto3" json:"CacheSize,omitempty"`
 	TimeQuantum    string `protobuf:"bytes,5,opt,name=TimeQuantum,proto3" json:"TimeQuantum,omitempty"`
+	Retention      string `protobuf:"bytes,6,opt,name=Retention,proto3" json:"Retention,omitempty"`
+	Mutex          bool   `protobuf:"varint,7,opt,name=Mutex,proto3" json:"Mutex,omitempty"`
 }
 
 func (m *FrameMeta) Reset()                    { *m = FrameMeta{} }
@@ -282,6 +288,28 @@ func (m *ClusterStatus) GetNodes() []*NodeStatus {
 	return nil
 }
 
+type DeleteViewMessage struct {
+	Index string `protobuf:"bytes,1,opt,name=Index,proto3" json:"Index,omitempty"`
+	Frame string `protobuf:"bytes,2,opt,name=Frame,proto3" json:"Frame,omitempty"`
+	View  string `protobuf:"bytes,3,opt,name=View,proto3" json:"View,omitempty"`
+}
+
+func (m *DeleteViewMessage) Reset()                    { *m = DeleteViewMessage{} }
+func (m *DeleteViewMessage) String() string            { return proto.CompactTextString(m) }
+func (*DeleteViewMessage) ProtoMessage()               {}
+func (*DeleteViewMessage) Descriptor() ([]byte, []int) { return fileDescriptorPrivate, []int{16} }
+
+type RollUpViewsMessage struct {
+	Index string `protobuf:"bytes,1,opt,name=Index,proto3" json:"Index,omitempty"`
+	Frame string `protobuf:"bytes,2,opt,name=Frame,proto3" json:"Frame,omitempty"`
+	Drop  bool   `protobuf:"varint,3,opt,name=Drop,proto3" json:"Drop,omitempty"`
+}
+
+func (m *RollUpViewsMessage) Reset()                    { *m = RollUpViewsMessage{} }
+func (m *RollUpViewsMessage) String() string            { return proto.CompactTextString(m) }
+func (*RollUpViewsMessage) ProtoMessage()               {}
+func (*RollUpViewsMessage) Descriptor() ([]byte, []int) { return fileDescriptorPrivate, []int{17} }
+
 func init() {
 	proto.RegisterType((*IndexMeta)(nil), "internal.IndexMeta")
 	proto.RegisterType((*FrameMeta)(nil), "internal.FrameMeta")
@@ -299,6 +327,8 @@ func init() {
 	proto.RegisterType((*Index)(nil), "internal.Index")
 	proto.RegisterType((*NodeStatus)(nil), "internal.NodeStatus")
 	proto.RegisterType((*ClusterStatus)(nil), "internal.ClusterStatus")
+	proto.RegisterType((*DeleteViewMessage)(nil), "internal.DeleteViewMessage")
+	proto.RegisterType((*RollUpViewsMessage)(nil), "internal.RollUpViewsMessage")
 }
 func (m *IndexMeta) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -327,6 +357,17 @@ func (m *IndexMeta) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintPrivate(dAtA, i, uint64(len(m.TimeQuantum)))
 		i += copy(dAtA[i:], m.TimeQuantum)
 	}
+	if m.SliceWidth != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(m.SliceWidth))
+	}
+	if len(m.StorageBackend) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.StorageBackend)))
+		i += copy(dAtA[i:], m.StorageBackend)
+	}
 	return i, nil
 }
 
@@ -378,6 +419,22 @@ func (m *FrameMeta) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintPrivate(dAtA, i, uint64(len(m.TimeQuantum)))
 		i += copy(dAtA[i:], m.TimeQuantum)
 	}
+	if len(m.Retention) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.Retention)))
+		i += copy(dAtA[i:], m.Retention)
+	}
+	if m.Mutex {
+		dAtA[i] = 0x38
+		i++
+		if m.Mutex {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -913,6 +970,82 @@ func (m *ClusterStatus) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *DeleteViewMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeleteViewMessage) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Index) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.Index)))
+		i += copy(dAtA[i:], m.Index)
+	}
+	if len(m.Frame) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.Frame)))
+		i += copy(dAtA[i:], m.Frame)
+	}
+	if len(m.View) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.View)))
+		i += copy(dAtA[i:], m.View)
+	}
+	return i, nil
+}
+
+func (m *RollUpViewsMessage) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RollUpViewsMessage) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Index) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.Index)))
+		i += copy(dAtA[i:], m.Index)
+	}
+	if len(m.Frame) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.Frame)))
+		i += copy(dAtA[i:], m.Frame)
+	}
+	if m.Drop {
+		dAtA[i] = 0x18
+		i++
+		if m.Drop {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
 func encodeFixed64Private(dAtA []byte, offset int, v uint64) int {
 	dAtA[offset] = uint8(v)
 	dAtA[offset+1] = uint8(v >> 8)
@@ -951,6 +1084,13 @@ func (m *IndexMeta) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovPrivate(uint64(l))
 	}
+	if m.SliceWidth != 0 {
+		n += 1 + sovPrivate(uint64(m.SliceWidth))
+	}
+	l = len(m.StorageBackend)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
 	return n
 }
 
@@ -975,6 +1115,13 @@ func (m *FrameMeta) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovPrivate(uint64(l))
 	}
+	l = len(m.Retention)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
+	if m.Mutex {
+		n += 2
+	}
 	return n
 }
 
@@ -1207,6 +1354,41 @@ func (m *ClusterStatus) Size() (n int) {
 	return n
 }
 
+func (m *DeleteViewMessage) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Index)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
+	l = len(m.Frame)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
+	l = len(m.View)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
+	return n
+}
+
+func (m *RollUpViewsMessage) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Index)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
+	l = len(m.Frame)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
+	if m.Drop {
+		n += 2
+	}
+	return n
+}
+
 func sovPrivate(x uint64) (n int) {
 	for {
 		n++
@@ -1307,6 +1489,54 @@ func (m *IndexMeta) Unmarshal(dAtA []byte) error {
 			}
 			m.TimeQuantum = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SliceWidth", wireType)
+			}
+			m.SliceWidth = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SliceWidth |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StorageBackend", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StorageBackend = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPrivate(dAtA[iNdEx:])
@@ -1483,6 +1713,55 @@ func (m *FrameMeta) Unmarshal(dAtA []byte) error {
 			}
 			m.TimeQuantum = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Retention", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Retention = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mutex", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Mutex = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPrivate(dAtA[iNdEx:])
@@ -3314,6 +3593,271 @@ func (m *ClusterStatus) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *DeleteViewMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPrivate
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeleteViewMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeleteViewMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Index = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Frame", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Frame = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field View", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.View = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPrivate(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RollUpViewsMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPrivate
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RollUpViewsMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RollUpViewsMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Index = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Frame", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Frame = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Drop", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Drop = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPrivate(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipPrivate(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -3422,45 +3966,51 @@ var (
 func init() { proto.RegisterFile("private.proto", fileDescriptorPrivate) }
 
 var fileDescriptorPrivate = []byte{
-	// 640 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x54, 0xc1, 0x4e, 0x14, 0x4d,
-	0x10, 0xfe, 0x67, 0x77, 0x96, 0x7f, 0xa7, 0x08, 0x08, 0x2d, 0x31, 0x23, 0x21, 0x9b, 0x4d, 0x1f,
-	0x04, 0x39, 0x70, 0xc0, 0x8b, 0x51, 0x0f, 0x86, 0x5d, 0x0c, 0x93, 0x08, 0xc6, 0x5e, 0xe2, 0xd1,
-	0xa4, 0x81, 0x8a, 0x4e, 0x98, 0x9d, 0x59, 0xa7, 0x7b, 0x80, 0xf5, 0xe0, 0x73, 0x98, 0x78, 0xf2,
-	0x01, 0x7c, 0x0f, 0x8f, 0x3e, 0x82, 0xc1, 0x17, 0x31, 0x5d, 0xdd, 0x33, 0xb3, 0x0e, 0x22, 0xd1,
-	0x5b, 0xd7, 0x57, 0xd5, 0xf5, 0x7d, 0xfd, 0x4d, 0xd5, 0xc0, 0xc2, 0x24, 0x8f, 0xcf, 0xa4, 0xc6,
-	0xad, 0x49, 0x9e, 0xe9, 0x8c, 0x75, 0xe3, 0x54, 0x63, 0x9e, 0xca, 0x84, 0xbf, 0x80, 0x20, 0x4a,
-	0x4f, 0xf0, 0x62, 0x1f, 0xb5, 0x64, 0x7d, 0x98, 0x1f, 0x64, 0x49, 0x31, 0x4e, 0x9f, 0xcb, 0x23,
-	0x4c, 0x42, 0xaf, 0xef, 0x6d, 0x04, 0x62, 0x16, 0x32, 0x15, 0x87, 0xf1, 0x18, 0x5f, 0x16, 0x32,
-	0xd5, 0xc5, 0x38, 0x6c, 0xd9, 0x8a, 0x19, 0x88, 0x7f, 0xf1, 0x20, 0x78, 0x96, 0xcb, 0x31, 0x52,
-	0xc7, 0x55, 0xe8, 0x8a, 0xec, 0x7c, 0xb6, 0x5d, 0x15, 0xb3, 0x7b, 0xb0, 0x18, 0xa5, 0x67, 0x98,
-	0x2b, 0xdc, 0x4d, 0xe5, 0x51, 0x82, 0x27, 0xd4, 0xae, 0x2b, 0x1a, 0x28, 0x5b, 0x83, 0x60, 0x20,
-	0x8f, 0xdf, 0xe2, 0xe1, 0x74, 0x82, 0x61, 0x9b, 0x9a, 0xd4, 0x40, 0x95, 0x1d, 0xc5, 0xef, 0x31,
-	0xf4, 0xfb, 0xde, 0xc6, 0x82, 0xa8, 0x81, 0xa6, 0xde, 0xce, 0x55, 0xbd, 0x1c, 0x16, 0xa3, 0xf1,
-	0x24, 0xcb, 0xb5, 0x40, 0x35, 0xc9, 0x52, 0x85, 0x6c, 0x09, 0xda, 0xbb, 0x79, 0xee, 0xe4, 0x9a,
-	0x23, 0xff, 0x00, 0x4b, 0x3b, 0x49, 0x76, 0x7c, 0x3a, 0x94, 0x5a, 0x0a, 0x7c, 0x57, 0xa0, 0xd2,
-	0x6c, 0x05, 0x3a, 0x64, 0x9c, 0xab, 0xb3, 0x81, 0x41, 0xe9, 0xf1, 0xce, 0x19, 0x1b, 0x18, 0x94,
-	0xee, 0x93, 0x7a, 0x5f, 0xd8, 0xc0, 0xa0, 0xa3, 0x24, 0x3e, 0xb6, 0xaa, 0x7d, 0x61, 0x03, 0xc6,
-	0xc0, 0x7f, 0x15, 0xe3, 0xb9, 0x93, 0x4a, 0x67, 0x1e, 0xc1, 0xf2, 0x0c, 0xbf, 0x93, 0x79, 0x07,
-	0xe6, 0x44, 0x76, 0x1e, 0x0d, 0x55, 0xe8, 0xf5, 0xdb, 0x1b, 0xbe, 0x70, 0x11, 0x19, 0x42, 0x5f,
-	0xcc, 0xa4, 0x5a, 0x94, 0xaa, 0x01, 0x7e, 0x17, 0x3a, 0xe4, 0x8e, 0x79, 0x65, 0x7d, 0xd7, 0x1c,
-	0xf9, 0x27, 0x0f, 0x96, 0xf7, 0xe5, 0x05, 0xc9, 0x50, 0x15, 0xcd, 0x1e, 0x04, 0x15, 0x48, 0xd5,
-	0xf3, 0xdb, 0x9b, 0x5b, 0xe5, 0xf8, 0x6c, 0x5d, 0xa9, 0xaf, 0x91, 0xdd, 0x54, 0xe7, 0x53, 0x51,
-	0x5f, 0x5e, 0x7d, 0x02, 0x8b, 0xbf, 0x26, 0x8d, 0x86, 0x53, 0x9c, 0x96, 0x4e, 0x9f, 0xe2, 0xd4,
-	0x78, 0x72, 0x26, 0x93, 0xc2, 0xfa, 0xe7, 0x0b, 0x1b, 0x3c, 0x6a, 0x3d, 0xf4, 0xf8, 0x6b, 0x60,
-	0x83, 0x1c, 0xa5, 0x46, 0x6a, 0xb0, 0x8f, 0x4a, 0xc9, 0x37, 0x78, 0xfd, 0x57, 0xb0, 0xce, 0xb6,
-	0x66, 0x9d, 0x5d, 0x83, 0x20, 0x52, 0x6e, 0xb6, 0xe8, 0x4b, 0x74, 0x45, 0x0d, 0xf0, 0x4d, 0x60,
-	0x43, 0x4c, 0x50, 0xa3, 0x5b, 0x87, 0x3f, 0xf4, 0xe7, 0xa3, 0x52, 0xcb, 0xcd, 0xb5, 0x6c, 0x1d,
-	0x7c, 0xb3, 0x09, 0x24, 0x65, 0x7e, 0xfb, 0x76, 0x6d, 0x5d, 0xb5, 0x76, 0x82, 0x0a, 0x78, 0x5c,
-	0x36, 0x75, 0xdb, 0x73, 0xc3, 0x03, 0x7f, 0x33, 0x66, 0x25, 0x55, 0xbb, 0x49, 0x55, 0xed, 0xa3,
-	0xa3, 0x7a, 0x5a, 0xbe, 0xf5, 0x5f, 0xa9, 0xf8, 0xd0, 0xa1, 0x66, 0x5c, 0x0f, 0x4c, 0xd6, 0xde,
-	0xa1, 0xf3, 0xf5, 0x4f, 0x6e, 0xea, 0xf8, 0xec, 0x39, 0xca, 0xbf, 0x6b, 0xd3, 0x70, 0xce, 0xfc,
-	0x64, 0xca, 0xc1, 0x72, 0x1b, 0x56, 0xc5, 0x6c, 0x1d, 0xe6, 0x88, 0x55, 0x85, 0x3e, 0xcd, 0xee,
-	0xad, 0x86, 0x1a, 0xe1, 0xd2, 0x66, 0x9d, 0xdc, 0x90, 0x77, 0xec, 0x3a, 0xd9, 0x88, 0x4b, 0x80,
-	0x83, 0xec, 0x04, 0x47, 0x5a, 0xea, 0x42, 0x19, 0x9d, 0x7b, 0x99, 0xd2, 0xa5, 0x4e, 0x73, 0xa6,
-	0x69, 0xd3, 0x52, 0x57, 0x0e, 0x51, 0xc0, 0xee, 0xc3, 0xff, 0xa4, 0x13, 0x55, 0xd8, 0x6e, 0x32,
-	0x53, 0x42, 0x94, 0x79, 0xfe, 0x18, 0x16, 0x06, 0x49, 0xa1, 0x34, 0xe6, 0x8e, 0x65, 0x13, 0x3a,
-	0x86, 0xb3, 0xdc, 0xb7, 0x95, 0xfa, 0x66, 0x2d, 0x45, 0xd8, 0x92, 0x9d, 0xa5, 0xaf, 0x97, 0x3d,
-	0xef, 0xdb, 0x65, 0xcf, 0xfb, 0x7e, 0xd9, 0xf3, 0x3e, 0xfe, 0xe8, 0xfd, 0x77, 0x34, 0x47, 0xff,
-	0xf8, 0x07, 0x3f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x5a, 0x1c, 0x79, 0x08, 0xf4, 0x05, 0x00, 0x00,
+	// 732 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xcd, 0x6e, 0x13, 0x49,
+	0x10, 0xde, 0xb1, 0xc7, 0x8e, 0xa7, 0xa2, 0x64, 0x93, 0xd9, 0x68, 0x35, 0x1b, 0x45, 0x96, 0xd5,
+	0x87, 0x4d, 0x36, 0x87, 0x1c, 0xb2, 0x17, 0x04, 0x1c, 0x50, 0xec, 0xa0, 0x58, 0xc2, 0x91, 0x68,
+	0x07, 0xb8, 0x21, 0x75, 0xec, 0x52, 0x32, 0xf2, 0x78, 0xc6, 0x4c, 0xf7, 0x24, 0x31, 0x07, 0x5e,
+	0x03, 0x24, 0x4e, 0xbc, 0x0d, 0x47, 0x1e, 0x01, 0xc2, 0x8b, 0xa0, 0xae, 0xee, 0xf9, 0xc1, 0x01,
+	0x22, 0x72, 0xeb, 0xef, 0xab, 0xea, 0xaa, 0xaf, 0xaa, 0xab, 0x66, 0x60, 0x65, 0x96, 0x86, 0x17,
+	0x42, 0xe1, 0xde, 0x2c, 0x4d, 0x54, 0xe2, 0xb7, 0xc2, 0x58, 0x61, 0x1a, 0x8b, 0x88, 0xbd, 0x75,
+	0xc0, 0xeb, 0xc7, 0x63, 0xbc, 0x1a, 0xa0, 0x12, 0x7e, 0x07, 0x96, 0xbb, 0x49, 0x94, 0x4d, 0xe3,
+	0x27, 0xe2, 0x14, 0xa3, 0xc0, 0xe9, 0x38, 0x3b, 0x1e, 0xaf, 0x52, 0xda, 0xe3, 0x24, 0x9c, 0xe2,
+	0xd3, 0x4c, 0xc4, 0x2a, 0x9b, 0x06, 0x35, 0xe3, 0x51, 0xa1, 0xfc, 0x36, 0xc0, 0x30, 0x0a, 0x47,
+	0xf8, 0x22, 0x1c, 0xab, 0xf3, 0xa0, 0xde, 0x71, 0x76, 0x5c, 0x5e, 0x61, 0xfc, 0x7f, 0x61, 0x75,
+	0xa8, 0x92, 0x54, 0x9c, 0xe1, 0x81, 0x18, 0x4d, 0x30, 0x1e, 0x07, 0x2e, 0x05, 0x59, 0x60, 0xd9,
+	0x17, 0x07, 0xbc, 0xc7, 0xa9, 0x98, 0x22, 0x29, 0xdb, 0x84, 0x16, 0x4f, 0x2e, 0xab, 0xb2, 0x0a,
+	0xac, 0x23, 0xf6, 0xe3, 0x0b, 0x4c, 0x25, 0x1e, 0xc6, 0xe2, 0x34, 0xc2, 0x31, 0xc9, 0x6a, 0xf1,
+	0x05, 0xd6, 0xdf, 0x02, 0xaf, 0x2b, 0x46, 0xe7, 0x78, 0x32, 0x9f, 0x21, 0x09, 0xf3, 0x78, 0x49,
+	0x14, 0xd6, 0x61, 0xf8, 0x1a, 0x49, 0xd2, 0x0a, 0x2f, 0x89, 0xc5, 0xba, 0x1b, 0x37, 0xeb, 0xde,
+	0x02, 0x8f, 0xa3, 0xc2, 0x58, 0x85, 0x49, 0x1c, 0x34, 0x4d, 0xf4, 0x82, 0xf0, 0x37, 0xa0, 0x31,
+	0xc8, 0x14, 0x5e, 0x05, 0x4b, 0x24, 0xcd, 0x00, 0xc6, 0x60, 0xb5, 0x3f, 0x9d, 0x25, 0xa9, 0xe2,
+	0x28, 0x67, 0x49, 0x2c, 0xd1, 0x5f, 0x83, 0xfa, 0x61, 0x9a, 0xda, 0x12, 0xf5, 0x91, 0xbd, 0x81,
+	0xb5, 0x83, 0x28, 0x19, 0x4d, 0x7a, 0x42, 0x09, 0x8e, 0xaf, 0x32, 0x94, 0x4a, 0x47, 0xa3, 0x47,
+	0xb3, 0x7e, 0x06, 0x68, 0x96, 0x1a, 0x66, 0x5f, 0xc5, 0x00, 0xcd, 0xd2, 0x7d, 0xfb, 0x14, 0x06,
+	0x68, 0x96, 0xde, 0x84, 0x2a, 0x75, 0xb9, 0x01, 0xbe, 0x0f, 0xee, 0xf3, 0x10, 0x2f, 0x6d, 0x79,
+	0x74, 0x66, 0x7d, 0x58, 0xaf, 0xe4, 0xb7, 0x32, 0xff, 0x86, 0x26, 0x4f, 0x2e, 0xfb, 0x3d, 0x19,
+	0x38, 0x9d, 0xfa, 0x8e, 0xcb, 0x2d, 0xa2, 0x26, 0xd2, 0xb4, 0x68, 0x53, 0x8d, 0x4c, 0x25, 0xc1,
+	0xfe, 0x81, 0x06, 0x75, 0x54, 0x57, 0x59, 0xde, 0xd5, 0x47, 0xf6, 0xde, 0x81, 0xf5, 0x81, 0xb8,
+	0x22, 0x19, 0xb2, 0x48, 0x73, 0x04, 0x5e, 0x41, 0x92, 0xf7, 0xf2, 0xfe, 0xee, 0x5e, 0x3e, 0xbb,
+	0x7b, 0x37, 0xfc, 0x4b, 0xe6, 0x30, 0x56, 0xe9, 0x9c, 0x97, 0x97, 0x37, 0x1f, 0xc2, 0xea, 0xf7,
+	0x46, 0xad, 0x61, 0x82, 0xf3, 0xbc, 0xd3, 0x13, 0x9c, 0xeb, 0x9e, 0x5c, 0x88, 0x28, 0x33, 0xfd,
+	0x73, 0xb9, 0x01, 0xf7, 0x6b, 0xf7, 0x1c, 0xf6, 0x12, 0xfc, 0x6e, 0x8a, 0x42, 0x21, 0x05, 0x18,
+	0xa0, 0x94, 0xe2, 0x0c, 0x7f, 0xfe, 0x0a, 0xa6, 0xb3, 0xb5, 0x6a, 0x67, 0xb7, 0xc0, 0xeb, 0x4b,
+	0x3b, 0x8f, 0xf4, 0x12, 0x2d, 0x5e, 0x12, 0x6c, 0x17, 0xfc, 0x1e, 0x46, 0xa8, 0xd0, 0xae, 0xe2,
+	0x2f, 0xe2, 0xb3, 0x61, 0xae, 0xe5, 0x76, 0x5f, 0x7f, 0x1b, 0x5c, 0xbd, 0x3d, 0x24, 0x65, 0x79,
+	0xff, 0xaf, 0xb2, 0x75, 0xc5, 0xca, 0x73, 0x72, 0x60, 0x61, 0x1e, 0xd4, 0x6e, 0xdc, 0x2d, 0x05,
+	0xfe, 0x60, 0xcc, 0xf2, 0x54, 0xf5, 0xc5, 0x54, 0xc5, 0x0e, 0xdb, 0x54, 0x8f, 0xf2, 0x5a, 0xef,
+	0x9a, 0x8a, 0xf5, 0x2c, 0xab, 0xc7, 0xf5, 0x58, 0x5b, 0xcd, 0x1d, 0xf7, 0xb8, 0xaa, 0xa3, 0x76,
+	0x9b, 0x8e, 0x0f, 0x8e, 0x4d, 0xf9, 0x7b, 0x61, 0x16, 0x3a, 0xa7, 0x3f, 0x4c, 0xf9, 0x60, 0xd9,
+	0x0d, 0x2b, 0xb0, 0xbf, 0x0d, 0x4d, 0xca, 0x2a, 0x03, 0x97, 0x66, 0xf7, 0xcf, 0x05, 0x35, 0xdc,
+	0x9a, 0xf5, 0x3a, 0xd9, 0x21, 0x6f, 0x98, 0x75, 0x32, 0x88, 0x09, 0x80, 0xe3, 0x64, 0x8c, 0x43,
+	0x25, 0x54, 0x26, 0xb5, 0xce, 0xa3, 0x44, 0xaa, 0x5c, 0xa7, 0x3e, 0xd3, 0xb4, 0x29, 0xa1, 0x8a,
+	0x0e, 0x11, 0xf0, 0xff, 0x83, 0x25, 0xd2, 0x89, 0x32, 0xa8, 0x2f, 0x66, 0x26, 0x03, 0xcf, 0xed,
+	0xec, 0x01, 0xac, 0x74, 0xa3, 0x4c, 0x2a, 0x4c, 0x6d, 0x96, 0x5d, 0x68, 0xe8, 0x9c, 0xf9, 0xbe,
+	0x6d, 0x94, 0x37, 0x4b, 0x29, 0xdc, 0xb8, 0xb0, 0x21, 0xac, 0x9b, 0xb7, 0xd4, 0x5f, 0x8a, 0xbb,
+	0x4c, 0x4d, 0xfe, 0xc1, 0xa9, 0x57, 0x3e, 0x38, 0x27, 0xe0, 0xf3, 0x24, 0x8a, 0x9e, 0xcd, 0x34,
+	0x92, 0x77, 0x8c, 0xda, 0x4b, 0x93, 0x99, 0xdd, 0x33, 0x3a, 0x1f, 0xac, 0x7d, 0xbc, 0x6e, 0x3b,
+	0x9f, 0xae, 0xdb, 0xce, 0xe7, 0xeb, 0xb6, 0xf3, 0xee, 0x6b, 0xfb, 0x8f, 0xd3, 0x26, 0xfd, 0x0b,
+	0xff, 0xff, 0x36, 0x00, 0xa5, 0xdd, 0xaa, 0x23, 0x1c, 0x07, 0x00, 0x00,
 }
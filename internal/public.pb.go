@@ -33,6 +33,7 @@
 		QueryResponse
 		QueryResult
 		ImportRequest
+		TimeCount
 */
 package internal
 
@@ -144,6 +145,7 @@ type QueryRequest struct {
 	ColumnAttrs bool     `protobuf:"varint,3,opt,name=ColumnAttrs,proto3" json:"ColumnAttrs,omitempty"`
 	Quantum     string   `protobuf:"bytes,4,opt,name=Quantum,proto3" json:"Quantum,omitempty"`
 	Remote      bool     `protobuf:"varint,5,opt,name=Remote,proto3" json:"Remote,omitempty"`
+	ReadRepair  bool     `protobuf:"varint,6,opt,name=ReadRepair,proto3" json:"ReadRepair,omitempty"`
 }
 
 func (m *QueryRequest) Reset()                    { *m = QueryRequest{} }
@@ -177,10 +179,11 @@ func (m *QueryResponse) GetColumnAttrSets() []*ColumnAttrSet {
 }
 
 type QueryResult struct {
-	Bitmap  *Bitmap `protobuf:"bytes,1,opt,name=Bitmap" json:"Bitmap,omitempty"`
-	N       uint64  `protobuf:"varint,2,opt,name=N,proto3" json:"N,omitempty"`
-	Pairs   []*Pair `protobuf:"bytes,3,rep,name=Pairs" json:"Pairs,omitempty"`
-	Changed bool    `protobuf:"varint,4,opt,name=Changed,proto3" json:"Changed,omitempty"`
+	Bitmap     *Bitmap      `protobuf:"bytes,1,opt,name=Bitmap" json:"Bitmap,omitempty"`
+	N          uint64       `protobuf:"varint,2,opt,name=N,proto3" json:"N,omitempty"`
+	Pairs      []*Pair      `protobuf:"bytes,3,rep,name=Pairs" json:"Pairs,omitempty"`
+	Changed    bool         `protobuf:"varint,4,opt,name=Changed,proto3" json:"Changed,omitempty"`
+	TimeCounts []*TimeCount `protobuf:"bytes,5,rep,name=TimeCounts" json:"TimeCounts,omitempty"`
 }
 
 func (m *QueryResult) Reset()                    { *m = QueryResult{} }
@@ -202,6 +205,13 @@ func (m *QueryResult) GetPairs() []*Pair {
 	return nil
 }
 
+func (m *QueryResult) GetTimeCounts() []*TimeCount {
+	if m != nil {
+		return m.TimeCounts
+	}
+	return nil
+}
+
 type ImportRequest struct {
 	Index      string   `protobuf:"bytes,1,opt,name=Index,proto3" json:"Index,omitempty"`
 	Frame      string   `protobuf:"bytes,2,opt,name=Frame,proto3" json:"Frame,omitempty"`
@@ -216,6 +226,16 @@ func (m *ImportRequest) String() string            { return proto.CompactTextStr
 func (*ImportRequest) ProtoMessage()               {}
 func (*ImportRequest) Descriptor() ([]byte, []int) { return fileDescriptorPublic, []int{9} }
 
+type TimeCount struct {
+	Time  int64  `protobuf:"varint,1,opt,name=Time,proto3" json:"Time,omitempty"`
+	Count uint64 `protobuf:"varint,2,opt,name=Count,proto3" json:"Count,omitempty"`
+}
+
+func (m *TimeCount) Reset()                    { *m = TimeCount{} }
+func (m *TimeCount) String() string            { return proto.CompactTextString(m) }
+func (*TimeCount) ProtoMessage()               {}
+func (*TimeCount) Descriptor() ([]byte, []int) { return fileDescriptorPublic, []int{10} }
+
 func init() {
 	proto.RegisterType((*Bitmap)(nil), "internal.Bitmap")
 	proto.RegisterType((*Pair)(nil), "internal.Pair")
@@ -227,6 +247,7 @@ func init() {
 	proto.RegisterType((*QueryResponse)(nil), "internal.QueryResponse")
 	proto.RegisterType((*QueryResult)(nil), "internal.QueryResult")
 	proto.RegisterType((*ImportRequest)(nil), "internal.ImportRequest")
+	proto.RegisterType((*TimeCount)(nil), "internal.TimeCount")
 }
 func (m *Bitmap) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -520,6 +541,16 @@ func (m *QueryRequest) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if m.ReadRepair {
+		dAtA[i] = 0x30
+		i++
+		if m.ReadRepair {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -623,6 +654,18 @@ func (m *QueryResult) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if len(m.TimeCounts) > 0 {
+		for _, msg := range m.TimeCounts {
+			dAtA[i] = 0x2a
+			i++
+			i = encodeVarintPublic(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	return i, nil
 }
 
@@ -713,6 +756,34 @@ func (m *ImportRequest) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *TimeCount) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TimeCount) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Time != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(m.Time))
+	}
+	if m.Count != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(m.Count))
+	}
+	return i, nil
+}
+
 func encodeFixed64Public(dAtA []byte, offset int, v uint64) int {
 	dAtA[offset] = uint8(v)
 	dAtA[offset+1] = uint8(v >> 8)
@@ -863,6 +934,9 @@ func (m *QueryRequest) Size() (n int) {
 	if m.Remote {
 		n += 2
 	}
+	if m.ReadRepair {
+		n += 2
+	}
 	return n
 }
 
@@ -907,6 +981,12 @@ func (m *QueryResult) Size() (n int) {
 	if m.Changed {
 		n += 2
 	}
+	if len(m.TimeCounts) > 0 {
+		for _, e := range m.TimeCounts {
+			l = e.Size()
+			n += 1 + l + sovPublic(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -948,6 +1028,18 @@ func (m *ImportRequest) Size() (n int) {
 	return n
 }
 
+func (m *TimeCount) Size() (n int) {
+	var l int
+	_ = l
+	if m.Time != 0 {
+		n += 1 + sovPublic(uint64(m.Time))
+	}
+	if m.Count != 0 {
+		n += 1 + sovPublic(uint64(m.Count))
+	}
+	return n
+}
+
 func sovPublic(x uint64) (n int) {
 	for {
 		n++
@@ -1853,6 +1945,26 @@ func (m *QueryRequest) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Remote = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadRepair", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ReadRepair = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPublic(dAtA[iNdEx:])
@@ -2147,6 +2259,37 @@ func (m *QueryResult) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Changed = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TimeCounts", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPublic
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TimeCounts = append(m.TimeCounts, &TimeCount{})
+			if err := m.TimeCounts[len(m.TimeCounts)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPublic(dAtA[iNdEx:])
@@ -2481,6 +2624,94 @@ func (m *ImportRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *TimeCount) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPublic
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TimeCount: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TimeCount: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			m.Time = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Time |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPublic(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPublic
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipPublic(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -2589,41 +2820,44 @@ var (
 func init() { proto.RegisterFile("public.proto", fileDescriptorPublic) }
 
 var fileDescriptorPublic = []byte{
-	// 576 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x4b, 0x8e, 0xd3, 0x40,
-	0x10, 0xa5, 0x63, 0xe7, 0x57, 0xf9, 0x28, 0x6a, 0xf1, 0xb1, 0x10, 0x8a, 0x2c, 0x8b, 0x85, 0x57,
-	0x19, 0x69, 0x38, 0x00, 0xc2, 0x49, 0x46, 0xb2, 0x10, 0x23, 0xa6, 0x33, 0xb0, 0xf7, 0xcc, 0xb4,
-	0x06, 0x4b, 0xfe, 0xd1, 0xdd, 0x16, 0xe4, 0x00, 0xec, 0x91, 0xd8, 0x70, 0x03, 0x38, 0x0a, 0x4b,
-	0x8e, 0x80, 0xc2, 0x45, 0x50, 0x75, 0xbb, 0x63, 0x0f, 0x0b, 0xc4, 0xae, 0xdf, 0xab, 0xae, 0x76,
-	0xbd, 0x7a, 0x55, 0x86, 0x69, 0x55, 0x5f, 0x65, 0xe9, 0xf5, 0xaa, 0x12, 0xa5, 0x2a, 0xe9, 0x28,
-	0x2d, 0x14, 0x17, 0x45, 0x92, 0x05, 0x11, 0x0c, 0xa2, 0x54, 0xe5, 0x49, 0x45, 0x29, 0xb8, 0x51,
-	0xaa, 0xa4, 0x47, 0x7c, 0x27, 0x74, 0x99, 0x3e, 0xd3, 0xa7, 0xd0, 0x7f, 0xa1, 0x94, 0x90, 0x5e,
-	0xcf, 0x77, 0xc2, 0xc9, 0xe9, 0x7c, 0x65, 0xf3, 0x56, 0x48, 0x33, 0x13, 0x0c, 0x56, 0xe0, 0xbe,
-	0x4e, 0x52, 0x41, 0x17, 0xe0, 0xbc, 0xe4, 0x7b, 0x8f, 0xf8, 0x24, 0x74, 0x19, 0x1e, 0xe9, 0x7d,
-	0xe8, 0xaf, 0xcb, 0xba, 0x50, 0x5e, 0x4f, 0x73, 0x06, 0x04, 0x6f, 0xc0, 0x89, 0x52, 0x85, 0x41,
-	0x56, 0x7e, 0x88, 0x37, 0x4d, 0x82, 0x01, 0xf4, 0x31, 0x8c, 0xd6, 0x65, 0x56, 0xe7, 0x45, 0xbc,
-	0x69, 0xb2, 0x8e, 0x98, 0x3e, 0x81, 0xf1, 0x65, 0x9a, 0x73, 0xa9, 0x92, 0xbc, 0xf2, 0x1c, 0x9f,
-	0x84, 0x0e, 0x6b, 0x89, 0x60, 0x0b, 0x33, 0x73, 0x13, 0xab, 0xda, 0x71, 0x45, 0xe7, 0xd0, 0x3b,
-	0xbe, 0xde, 0x8b, 0x37, 0xff, 0xa9, 0xe6, 0x3b, 0x01, 0x17, 0x4f, 0x5d, 0x39, 0x63, 0x23, 0x87,
-	0x82, 0x7b, 0xb9, 0xaf, 0x78, 0x53, 0x97, 0x3e, 0x53, 0x1f, 0x26, 0x3b, 0x25, 0xd2, 0xe2, 0xf6,
-	0x6d, 0x92, 0xd5, 0x5c, 0x57, 0x35, 0x66, 0x5d, 0x0a, 0x15, 0xc5, 0x85, 0x32, 0x61, 0x57, 0x17,
-	0x7d, 0xc4, 0xa8, 0x28, 0x2a, 0xcb, 0xcc, 0x04, 0xfb, 0x3e, 0x09, 0x47, 0xac, 0x25, 0xe8, 0x12,
-	0xe0, 0x2c, 0x2b, 0x93, 0x26, 0x77, 0xe0, 0x93, 0x90, 0xb0, 0x0e, 0x13, 0x9c, 0xc0, 0x10, 0x2b,
-	0x7d, 0x95, 0x54, 0xad, 0x36, 0xf2, 0x2f, 0x6d, 0x9f, 0x09, 0x4c, 0x2f, 0x6a, 0x2e, 0xf6, 0x8c,
-	0xbf, 0xaf, 0xb9, 0xd4, 0x1e, 0x68, 0xdc, 0xa8, 0x34, 0x80, 0x3e, 0x84, 0xc1, 0x2e, 0x4b, 0xaf,
-	0xb9, 0xe9, 0x94, 0xcb, 0x1a, 0x84, 0x5a, 0xdb, 0x0e, 0x4b, 0xad, 0x75, 0xc4, 0xba, 0x14, 0xf5,
-	0x60, 0x78, 0x51, 0x27, 0x85, 0xaa, 0x73, 0x2d, 0x75, 0xcc, 0x2c, 0xc4, 0x37, 0x19, 0xcf, 0x4b,
-	0x65, 0x65, 0x36, 0x28, 0xf8, 0x42, 0x60, 0xd6, 0x94, 0x24, 0xab, 0xb2, 0x90, 0x1c, 0xfb, 0xbe,
-	0x15, 0xc2, 0xf6, 0x7d, 0x2b, 0x04, 0x3d, 0x81, 0x21, 0xe3, 0xb2, 0xce, 0x94, 0xb5, 0xee, 0x41,
-	0x2b, 0xcf, 0xe6, 0xd6, 0x99, 0x62, 0xf6, 0x16, 0x7d, 0x0e, 0xf3, 0x3b, 0xa3, 0x80, 0xb5, 0x62,
-	0xde, 0xa3, 0x36, 0xef, 0x4e, 0x9c, 0xfd, 0x75, 0x3d, 0xf8, 0x44, 0x60, 0xd2, 0x79, 0x99, 0x86,
-	0x76, 0x4d, 0x74, 0x59, 0x93, 0xd3, 0x45, 0xfb, 0x90, 0xe1, 0x99, 0x5d, 0xa3, 0x29, 0x90, 0xf3,
-	0x66, 0x40, 0xc8, 0x39, 0xda, 0x82, 0xab, 0x61, 0xbf, 0xdf, 0xb1, 0x05, 0x69, 0x66, 0x82, 0xd8,
-	0xb5, 0xf5, 0xbb, 0xa4, 0xb8, 0xe5, 0x37, 0xba, 0x6b, 0x23, 0x66, 0x61, 0xf0, 0x8d, 0xc0, 0x2c,
-	0xce, 0xab, 0x52, 0xa8, 0x8e, 0x63, 0x71, 0x71, 0xc3, 0x3f, 0x5a, 0xc7, 0x34, 0x40, 0xf6, 0x4c,
-	0x24, 0xb9, 0x19, 0xcd, 0x31, 0x33, 0x00, 0x59, 0xed, 0x9c, 0x76, 0xca, 0x65, 0x06, 0x68, 0x27,
-	0x70, 0xd5, 0xa4, 0xe7, 0x1a, 0x77, 0x0d, 0xc2, 0x59, 0xb4, 0x9b, 0x26, 0xbd, 0xbe, 0x0e, 0xb5,
-	0x04, 0xce, 0xe2, 0x71, 0xd5, 0xa4, 0x37, 0xf0, 0x9d, 0xd0, 0x61, 0x1d, 0x26, 0x5a, 0xfc, 0x38,
-	0x2c, 0xc9, 0xcf, 0xc3, 0x92, 0xfc, 0x3a, 0x2c, 0xc9, 0xd7, 0xdf, 0xcb, 0x7b, 0x57, 0x03, 0xfd,
-	0xaf, 0x79, 0xf6, 0x27, 0x00, 0x00, 0xff, 0xff, 0x48, 0x20, 0x4b, 0xe7, 0x7b, 0x04, 0x00, 0x00,
+	// 623 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x6e, 0xd4, 0x30,
+	0x10, 0xc6, 0x9b, 0xec, 0x76, 0x77, 0xb6, 0xad, 0x2a, 0xf3, 0x17, 0x21, 0xb4, 0x8a, 0x22, 0x0e,
+	0x39, 0x6d, 0xa5, 0x56, 0x9c, 0x11, 0xdb, 0x1f, 0x29, 0x42, 0x54, 0x74, 0x5a, 0xb8, 0xbb, 0xad,
+	0x55, 0x22, 0xe5, 0x0f, 0xc7, 0x11, 0xf4, 0x39, 0xb8, 0xf0, 0x06, 0x70, 0xe4, 0xcc, 0x13, 0x70,
+	0xe4, 0x11, 0xd0, 0xf2, 0x22, 0x68, 0xec, 0x38, 0x49, 0x11, 0x42, 0xdc, 0xfc, 0x7d, 0xe3, 0x99,
+	0x7c, 0x9f, 0x67, 0x26, 0xb0, 0x59, 0x35, 0x17, 0x59, 0x7a, 0xb9, 0xac, 0x54, 0xa9, 0x4b, 0x3e,
+	0x4d, 0x0b, 0x2d, 0x55, 0x21, 0xb2, 0x68, 0x05, 0x93, 0x55, 0xaa, 0x73, 0x51, 0x71, 0x0e, 0xfe,
+	0x2a, 0xd5, 0x75, 0xc0, 0x42, 0x2f, 0xf6, 0xd1, 0x9c, 0xf9, 0x13, 0x18, 0x3f, 0xd7, 0x5a, 0xd5,
+	0xc1, 0x28, 0xf4, 0xe2, 0xf9, 0xde, 0xf6, 0xd2, 0xe5, 0x2d, 0x89, 0x46, 0x1b, 0x8c, 0x96, 0xe0,
+	0xbf, 0x12, 0xa9, 0xe2, 0x3b, 0xe0, 0xbd, 0x90, 0x37, 0x01, 0x0b, 0x59, 0xec, 0x23, 0x1d, 0xf9,
+	0x3d, 0x18, 0x1f, 0x94, 0x4d, 0xa1, 0x83, 0x91, 0xe1, 0x2c, 0x88, 0x5e, 0x83, 0xb7, 0x4a, 0x35,
+	0x05, 0xb1, 0x7c, 0x9f, 0x1c, 0xb6, 0x09, 0x16, 0xf0, 0x47, 0x30, 0x3d, 0x28, 0xb3, 0x26, 0x2f,
+	0x92, 0xc3, 0x36, 0xab, 0xc3, 0xfc, 0x31, 0xcc, 0xce, 0xd3, 0x5c, 0xd6, 0x5a, 0xe4, 0x55, 0xe0,
+	0x85, 0x2c, 0xf6, 0xb0, 0x27, 0xa2, 0x23, 0xd8, 0xb2, 0x37, 0x49, 0xd5, 0x99, 0xd4, 0x7c, 0x1b,
+	0x46, 0x5d, 0xf5, 0x51, 0x72, 0xf8, 0x9f, 0x6e, 0xbe, 0x30, 0xf0, 0xe9, 0x34, 0xb4, 0x33, 0xb3,
+	0x76, 0x38, 0xf8, 0xe7, 0x37, 0x95, 0x6c, 0x75, 0x99, 0x33, 0x0f, 0x61, 0x7e, 0xa6, 0x55, 0x5a,
+	0x5c, 0xbf, 0x11, 0x59, 0x23, 0x8d, 0xaa, 0x19, 0x0e, 0x29, 0x72, 0x94, 0x14, 0xda, 0x86, 0x7d,
+	0x23, 0xba, 0xc3, 0xe4, 0x68, 0x55, 0x96, 0x99, 0x0d, 0x8e, 0x43, 0x16, 0x4f, 0xb1, 0x27, 0xf8,
+	0x02, 0xe0, 0x38, 0x2b, 0x45, 0x9b, 0x3b, 0x09, 0x59, 0xcc, 0x70, 0xc0, 0x44, 0xbb, 0xb0, 0x41,
+	0x4a, 0x5f, 0x8a, 0xaa, 0xf7, 0xc6, 0xfe, 0xe5, 0xed, 0x2b, 0x83, 0xcd, 0xd3, 0x46, 0xaa, 0x1b,
+	0x94, 0xef, 0x1a, 0x59, 0x9b, 0x1e, 0x18, 0xdc, 0xba, 0xb4, 0x80, 0x3f, 0x80, 0xc9, 0x59, 0x96,
+	0x5e, 0x4a, 0xfb, 0x52, 0x3e, 0xb6, 0x88, 0xbc, 0xf6, 0x2f, 0x5c, 0x1b, 0xaf, 0x53, 0x1c, 0x52,
+	0x3c, 0x80, 0x8d, 0xd3, 0x46, 0x14, 0xba, 0xc9, 0x8d, 0xd5, 0x19, 0x3a, 0x48, 0x35, 0x51, 0xe6,
+	0xa5, 0x76, 0x36, 0x5b, 0x44, 0x1e, 0x51, 0x8a, 0x2b, 0x94, 0x95, 0x48, 0x95, 0xf1, 0x38, 0xc5,
+	0x01, 0x13, 0x7d, 0x64, 0xb0, 0xd5, 0x4a, 0xae, 0xab, 0xb2, 0xa8, 0x25, 0xf5, 0xe5, 0x48, 0x29,
+	0xd7, 0x97, 0x23, 0xa5, 0xf8, 0x2e, 0x6c, 0xa0, 0xac, 0x9b, 0x4c, 0xbb, 0xd6, 0xde, 0xef, 0xed,
+	0xbb, 0xdc, 0x26, 0xd3, 0xe8, 0x6e, 0xf1, 0x67, 0xb0, 0x7d, 0x6b, 0x54, 0xc8, 0x0b, 0xe5, 0x3d,
+	0xec, 0xf3, 0x6e, 0xc5, 0xf1, 0x8f, 0xeb, 0xd1, 0x37, 0x06, 0xf3, 0x41, 0x65, 0x1e, 0xbb, 0x35,
+	0x32, 0xb2, 0xe6, 0x7b, 0x3b, 0x7d, 0x21, 0xcb, 0xa3, 0x5b, 0xb3, 0x4d, 0x60, 0x27, 0xed, 0x00,
+	0xb1, 0x13, 0x6a, 0x1b, 0xad, 0x8e, 0xfb, 0xfe, 0xa0, 0x6d, 0x44, 0xa3, 0x0d, 0xd2, 0xab, 0x1e,
+	0xbc, 0x15, 0xc5, 0xb5, 0xbc, 0x32, 0xaf, 0x3a, 0x45, 0x07, 0xf9, 0x3e, 0x00, 0x2d, 0x80, 0xd9,
+	0xab, 0x3a, 0x18, 0x9b, 0x22, 0x77, 0xfb, 0x22, 0x5d, 0x0c, 0x07, 0xd7, 0xa2, 0xcf, 0x0c, 0xb6,
+	0x92, 0xbc, 0x2a, 0x95, 0x1e, 0x8c, 0x41, 0x52, 0x5c, 0xc9, 0x0f, 0x6e, 0x0c, 0x0c, 0x20, 0xf6,
+	0x58, 0x89, 0xdc, 0xce, 0xfb, 0x0c, 0x2d, 0x20, 0xd6, 0x8c, 0x83, 0x69, 0xbf, 0x8f, 0x16, 0x98,
+	0xf6, 0xd2, 0xfe, 0xd6, 0x81, 0x6f, 0x47, 0xc6, 0x22, 0x1a, 0x70, 0xb7, 0xbe, 0x56, 0x9f, 0x8f,
+	0x3d, 0x41, 0xcd, 0xef, 0xf6, 0xb7, 0x0e, 0x26, 0xa1, 0x17, 0x7b, 0x38, 0x60, 0xa2, 0xa7, 0x30,
+	0xeb, 0x74, 0x9b, 0xed, 0x4b, 0x73, 0x69, 0x34, 0x7a, 0x68, 0xce, 0x7f, 0xff, 0xc1, 0xac, 0x76,
+	0xbe, 0xaf, 0x17, 0xec, 0xc7, 0x7a, 0xc1, 0x7e, 0xae, 0x17, 0xec, 0xd3, 0xaf, 0xc5, 0x9d, 0x8b,
+	0x89, 0xf9, 0xef, 0xed, 0xff, 0x1e, 0x00, 0x46, 0x02, 0x13, 0x41, 0x07, 0x05, 0x00, 0x00,
 }
@@ -173,22 +173,24 @@ func (itr *SliceIterator) Next() (rowID, columnID uint64, eof bool) {
 
 // RoaringIterator converts a roaring.Iterator to output column/row pairs.
 type RoaringIterator struct {
-	itr *roaring.Iterator
+	itr        *roaring.Iterator
+	sliceWidth uint64
 }
 
-// NewRoaringIterator returns a new iterator wrapping itr.
-func NewRoaringIterator(itr *roaring.Iterator) *RoaringIterator {
-	return &RoaringIterator{itr: itr}
+// NewRoaringIterator returns a new iterator wrapping itr. Positions are
+// split into row/column pairs using sliceWidth.
+func NewRoaringIterator(itr *roaring.Iterator, sliceWidth uint64) *RoaringIterator {
+	return &RoaringIterator{itr: itr, sliceWidth: sliceWidth}
 }
 
 // Seek moves the cursor to a pair matching bseek/pseek.
 // If the pair is not found then it moves to the next pair.
 func (itr *RoaringIterator) Seek(bseek, pseek uint64) {
-	itr.itr.Seek((bseek * SliceWidth) + pseek)
+	itr.itr.Seek((bseek * itr.sliceWidth) + pseek)
 }
 
 // Next returns the next column/row ID pair.
 func (itr *RoaringIterator) Next() (rowID, columnID uint64, eof bool) {
 	v, eof := itr.itr.Next()
-	return v / SliceWidth, v % SliceWidth, eof
+	return v / itr.sliceWidth, v % itr.sliceWidth, eof
 }
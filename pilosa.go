@@ -29,6 +29,21 @@ var (
 	ErrIndexExists   = errors.New("index already exists")
 	ErrIndexNotFound = errors.New("index not found")
 
+	// ErrInvalidSliceWidth is returned when a slice width is out of range or
+	// not a power of two.
+	ErrInvalidSliceWidth = errors.New("invalid slice width")
+
+	// ErrSliceWidthImmutable is returned when changing the slice width of
+	// an existing index.
+	ErrSliceWidthImmutable = errors.New("slice width cannot be changed")
+
+	// ErrInvalidStorageBackend is returned when a storage backend is unknown.
+	ErrInvalidStorageBackend = errors.New("invalid storage backend")
+
+	// ErrStorageBackendImmutable is returned when changing the storage
+	// backend of an index that already has frames.
+	ErrStorageBackendImmutable = errors.New("storage backend cannot be changed")
+
 	// ErrFrameRequired is returned when no frame is specified.
 	ErrFrameRequired        = errors.New("frame required")
 	ErrFrameExists          = errors.New("frame already exists")
@@ -44,6 +59,9 @@ var (
 	// ErrFragmentNotFound is returned when a fragment does not exist.
 	ErrFragmentNotFound = errors.New("fragment not found")
 	ErrQueryRequired    = errors.New("query required")
+
+	// ErrSyncPending is returned when an anti-entropy run is already queued.
+	ErrSyncPending = errors.New("sync already pending")
 )
 
 // Regular expression to validate index and frame names.
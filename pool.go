@@ -0,0 +1,138 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// fragmentPool tracks the open fragments in a holder in least recently used
+// order so that idle fragments and fragments over the open limit can be closed.
+// Closed fragments are reopened on their next access.
+type fragmentPool struct {
+	mu    sync.Mutex
+	lru   *list.List // open fragments, most recently used at the front
+	elems map[*Fragment]*list.Element
+
+	// Maximum number of open fragments. Zero is unlimited.
+	maxOpen int
+
+	// Duration after which an unused fragment is closed. Zero never closes.
+	idleTimeout time.Duration
+
+	// Signals that the pool is over its limit.
+	evict chan struct{}
+
+	// Returns the current time. Used for testing.
+	now func() time.Time
+}
+
+// poolEntry is an open fragment and the time of its last access.
+type poolEntry struct {
+	frag       *Fragment
+	accessedAt time.Time
+}
+
+// newFragmentPool returns a new instance of fragmentPool.
+func newFragmentPool(maxOpen int, idleTimeout time.Duration) *fragmentPool {
+	return &fragmentPool{
+		lru:         list.New(),
+		elems:       make(map[*Fragment]*list.Element),
+		maxOpen:     maxOpen,
+		idleTimeout: idleTimeout,
+		evict:       make(chan struct{}, 1),
+		now:         time.Now,
+	}
+}
+
+// touch marks f as open and most recently used.
+func (p *fragmentPool) touch(f *Fragment) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if elem := p.elems[f]; elem != nil {
+		elem.Value.(*poolEntry).accessedAt = p.now()
+		p.lru.MoveToFront(elem)
+		return
+	}
+	p.elems[f] = p.lru.PushFront(&poolEntry{frag: f, accessedAt: p.now()})
+
+	// Notify the holder if the pool is over its limit.
+	if p.maxOpen > 0 && p.lru.Len() > p.maxOpen {
+		select {
+		case p.evict <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// remove removes f from the pool once it has been closed.
+func (p *fragmentPool) remove(f *Fragment) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if elem := p.elems[f]; elem != nil {
+		p.lru.Remove(elem)
+		delete(p.elems, f)
+	}
+}
+
+// Len returns the number of open fragments.
+func (p *fragmentPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lru.Len()
+}
+
+// victims returns the least recently used fragments over the open limit
+// and any fragments which have been idle longer than the idle timeout.
+func (p *fragmentPool) victims() []*Fragment {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var a []*Fragment
+	now := p.now()
+	for elem := p.lru.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*poolEntry)
+		if p.maxOpen > 0 && p.lru.Len()-len(a) > p.maxOpen {
+			a = append(a, entry.frag)
+		} else if p.idleTimeout > 0 && now.Sub(entry.accessedAt) > p.idleTimeout {
+			a = append(a, entry.frag)
+		} else {
+			break
+		}
+	}
+	return a
+}
+
+// Evict closes fragments which are over the open limit or idle.
+// Returns the number of fragments closed.
+func (p *fragmentPool) Evict() int {
+	a := p.victims()
+	for _, f := range a {
+		f.evict()
+	}
+	return len(a)
+}
@@ -22,16 +22,26 @@ import (
 	"hash/fnv"
 	"io"
 	"sort"
+	"time"
 	"unsafe"
 )
 
 const (
-	// cookie is the first four bytes in a roaring bitmap file.
+	// cookie is the low 16 bits of the first four bytes in a roaring bitmap
+	// file. The high 16 bits contain the file format version.
 	cookie = uint32(12346)
 
 	// headerSize is the size of the cookie and key count at the beginning of a file.
 	headerSize = 4 + 4
 
+	// headerSizeV1 is the size of the cookie, key count & creation time at
+	// the beginning of a version 1 file.
+	headerSizeV1 = 4 + 4 + 8
+
+	// keyHeaderSizeV1 is the size of the key, container type, reserved
+	// bytes & count for each container in a version 1 file.
+	keyHeaderSizeV1 = 8 + 2 + 2 + 4
+
 	// bitmapN is the number of values in a container.bitmap.
 	bitmapN = (1 << 16) / 64
 
@@ -39,6 +49,26 @@ const (
 	manualAlloc = 524288
 )
 
+// File format versions.
+const (
+	// FormatVersion0 is a cookie & key count followed by key headers,
+	// offsets & containers. The container type is inferred from its count.
+	FormatVersion0 = 0
+
+	// FormatVersion1 adds the format version, creation time and container
+	// types to the header and aligns bitmap containers to 8 bytes.
+	FormatVersion1 = 1
+
+	// FormatVersion is the version written by WriteTo.
+	FormatVersion = FormatVersion1
+)
+
+// Container types stored in version 1 files.
+const (
+	containerArray  = 1
+	containerBitmap = 2
+)
+
 // Bitmap represents a roaring bitmap.
 type Bitmap struct {
 	keys       []uint64     // keys for containers
@@ -47,6 +77,10 @@ type Bitmap struct {
 	// Number of operations written to the writer.
 	opN int
 
+	// File format version & creation time read by UnmarshalBinary.
+	version   int
+	createdAt time.Time
+
 	// Writer where operations are appended to.
 	OpWriter io.Writer
 }
@@ -69,6 +103,8 @@ func (b *Bitmap) Clone() *Bitmap {
 	other := &Bitmap{
 		keys:       make([]uint64, len(b.keys)),
 		containers: make([]*container, len(b.containers)),
+		version:    b.version,
+		createdAt:  b.createdAt,
 	}
 
 	// Copy keys & clone containers.
@@ -80,6 +116,40 @@ func (b *Bitmap) Clone() *Bitmap {
 	return other
 }
 
+// Freeze returns a read-only copy of the bitmap which shares container data
+// with b. Containers in both bitmaps become copy-on-write so that changes to
+// b after freezing are not visible in the copy.
+// Note: The OpWriter IS NOT copied to the new bitmap.
+func (b *Bitmap) Freeze() *Bitmap {
+	if b == nil {
+		return nil
+	}
+
+	other := &Bitmap{
+		keys:       make([]uint64, len(b.keys)),
+		containers: make([]*container, len(b.containers)),
+		version:    b.version,
+		createdAt:  b.createdAt,
+	}
+
+	copy(other.keys, b.keys)
+	for i, c := range b.containers {
+		// Marking the container as mapped causes its data to be copied
+		// before the next modification.
+		if c.n > 0 {
+			c.mapped = true
+		}
+		other.containers[i] = &container{
+			n:      c.n,
+			array:  c.array,
+			bitmap: c.bitmap,
+			mapped: c.mapped,
+		}
+	}
+
+	return other
+}
+
 // Add adds values to the bitmap.
 func (b *Bitmap) Add(a ...uint64) (changed bool, err error) {
 	changed = false
@@ -471,43 +541,58 @@ func (b *Bitmap) countEmptyContainers() int {
 	return result
 }
 
-// WriteTo writes b to w.
+// WriteTo writes b to w using the current file format version.
 func (b *Bitmap) WriteTo(w io.Writer) (n int64, err error) {
 	// Remove empty containers before persisting.
 	//b.removeEmptyContainers()
 	containerCount := len(b.keys) - b.countEmptyContainers()
 
+	// Keep the original creation time when rewriting an existing file.
+	createdAt := b.createdAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+
 	// Build header before writing individual container blocks.
-	buf := make([]byte, headerSize+(containerCount*(4+8+4)))
-	binary.LittleEndian.PutUint32(buf[0:], cookie)
+	buf := make([]byte, headerSizeV1+(containerCount*(keyHeaderSizeV1+4)))
+	binary.LittleEndian.PutUint32(buf[0:], cookie|(FormatVersion<<16))
 	binary.LittleEndian.PutUint32(buf[4:], uint32(containerCount))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(createdAt.UnixNano()))
+
+	// Encode keys, container types and cardinality.
 	empty := 0
-	// Encode keys and cardinality.
 	for i, key := range b.keys {
 		c := b.containers[i]
-
-		// Verify container count before writing.
-		// TODO: instead of commenting this out, we need to make it a configuration option
-		//count := c.count()
-		//assert(c.count() == c.n, "cannot write container count, mismatch: count=%d, n=%d", count, c.n)
-		if c.n > 0 {
-			binary.LittleEndian.PutUint64(buf[headerSize+(i-empty)*12:], uint64(key))
-			binary.LittleEndian.PutUint32(buf[headerSize+(i-empty)*12+8:], uint32(c.n-1))
-		} else {
+		if c.n == 0 {
 			empty++
+			continue
 		}
+
+		typ := uint16(containerArray)
+		if !c.isArray() {
+			typ = containerBitmap
+		}
+
+		hdr := buf[headerSizeV1+(i-empty)*keyHeaderSizeV1:]
+		binary.LittleEndian.PutUint64(hdr[0:], uint64(key))
+		binary.LittleEndian.PutUint16(hdr[8:], typ)
+		binary.LittleEndian.PutUint32(hdr[12:], uint32(c.n-1))
 	}
 
-	// Write the offset for each container block.
+	// Write the offset for each container block. Bitmap containers are
+	// aligned to 8 bytes so they can be mapped directly.
 	offset := uint32(len(buf))
 	empty = 0
 	for i, c := range b.containers {
-
-		if c.n > 0 {
-			binary.LittleEndian.PutUint32(buf[headerSize+(containerCount*12)+((i-empty)*4):], uint32(offset))
-		} else {
+		if c.n == 0 {
 			empty++
+			continue
+		}
+
+		if !c.isArray() {
+			offset += bitmapPadding(offset)
 		}
+		binary.LittleEndian.PutUint32(buf[headerSizeV1+(containerCount*keyHeaderSizeV1)+((i-empty)*4):], uint32(offset))
 		offset += uint32(c.size())
 	}
 
@@ -519,32 +604,103 @@ func (b *Bitmap) WriteTo(w io.Writer) (n int64, err error) {
 	}
 
 	// Write each container block.
+	var pad [8]byte
 	for _, c := range b.containers {
-		if c.n > 0 {
-			nn, err := c.WriteTo(w)
-			n += nn
+		if c.n == 0 {
+			continue
+		}
+
+		if !c.isArray() {
+			i, err := w.Write(pad[:bitmapPadding(uint32(n))])
+			n += int64(i)
 			if err != nil {
 				return n, err
 			}
 		}
+
+		nn, err := c.WriteTo(w)
+		n += nn
+		if err != nil {
+			return n, err
+		}
 	}
 
 	return n, nil
 }
 
+// bitmapPadding returns the number of bytes required to align a bitmap
+// container at offset to 8 bytes.
+func bitmapPadding(offset uint32) uint32 {
+	return (8 - offset%8) % 8
+}
+
 // UnmarshalBinary decodes b from a binary-encoded byte slice.
+// All supported file format versions can be decoded.
 func (b *Bitmap) UnmarshalBinary(data []byte) error {
 	if len(data) < headerSize {
 		return errors.New("data too small")
 	}
 
-	// Verify the first 4 bytes are the correct cookie.
-	if v := binary.LittleEndian.Uint32(data[0:4]); v != cookie {
+	// Verify the low 16 bits of the first 4 bytes are the correct cookie.
+	v := binary.LittleEndian.Uint32(data[0:4])
+	if v&0xFFFF != cookie {
 		return errors.New("invalid roaring file")
 	}
 
+	var opsOffset int
+	var err error
+	switch version := int(v >> 16); version {
+	case FormatVersion0:
+		opsOffset, err = b.unmarshalV0(data)
+	case FormatVersion1:
+		opsOffset, err = b.unmarshalV1(data)
+	default:
+		return fmt.Errorf("unsupported roaring file version: %d", version)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Read ops log until the end of the file.
+	buf := data[opsOffset:]
+	for {
+		// Exit when there are no more ops to parse.
+		if len(buf) == 0 {
+			break
+		}
+
+		// Unmarshal the op and apply it.
+		// On failure, return the position so the file can be trimmed.
+		var op op
+		if err := op.UnmarshalBinary(buf); err != nil {
+			return &OpLogError{
+				Offset: len(data) - len(buf),
+				Size:   len(data),
+				Err:    err,
+			}
+		}
+		op.apply(b)
+
+		// Increase the op count.
+		b.opN++
+
+		// Move the buffer forward.
+		buf = buf[op.size():]
+	}
+
+	return nil
+}
+
+// unmarshalV0 decodes the containers of a version 0 file and returns the
+// offset of the op log.
+func (b *Bitmap) unmarshalV0(data []byte) (int, error) {
+	b.version, b.createdAt = FormatVersion0, time.Time{}
+
 	// Read key count.
 	keyN := binary.LittleEndian.Uint32(data[4:8])
+	if len(data) < headerSize+int(keyN)*(12+4) {
+		return 0, errors.New("data too small")
+	}
 	b.keys = make([]uint64, keyN)
 	b.containers = make([]*container, keyN)
 
@@ -564,7 +720,7 @@ func (b *Bitmap) UnmarshalBinary(data []byte) error {
 
 		// Verify the offset is within the bounds of the input data.
 		if int(offset) >= len(data) {
-			return fmt.Errorf("offset out of bounds: off=%d, len=%d", offset, len(data))
+			return 0, fmt.Errorf("offset out of bounds: off=%d, len=%d", offset, len(data))
 		}
 
 		// Map byte slice directly to the container data.
@@ -587,30 +743,94 @@ func (b *Bitmap) UnmarshalBinary(data []byte) error {
 		//assert(c.count() == c.n, "container count mismatch: count=%d, n=%d", count, c.n)
 	}
 
-	// Read ops log until the end of the file.
-	buf := data[opsOffset:]
-	for {
-		// Exit when there are no more ops to parse.
-		if len(buf) == 0 {
-			break
+	return opsOffset, nil
+}
+
+// unmarshalV1 decodes the containers of a version 1 file and returns the
+// offset of the op log.
+func (b *Bitmap) unmarshalV1(data []byte) (int, error) {
+	if len(data) < headerSizeV1 {
+		return 0, errors.New("data too small")
+	}
+
+	// Read key count & creation time.
+	keyN := binary.LittleEndian.Uint32(data[4:8])
+	b.version = FormatVersion1
+	b.createdAt = time.Unix(0, int64(binary.LittleEndian.Uint64(data[8:16]))).UTC()
+
+	opsOffset := headerSizeV1 + int(keyN)*keyHeaderSizeV1
+	if len(data) < opsOffset+int(keyN)*4 {
+		return 0, errors.New("data too small")
+	}
+	b.keys = make([]uint64, keyN)
+	b.containers = make([]*container, keyN)
+
+	// Read container key headers.
+	types := make([]uint16, keyN)
+	for i, buf := 0, data[headerSizeV1:]; i < int(keyN); i, buf = i+1, buf[keyHeaderSizeV1:] {
+		b.keys[i] = binary.LittleEndian.Uint64(buf[0:8])
+		types[i] = binary.LittleEndian.Uint16(buf[8:10])
+		b.containers[i] = &container{
+			n:      int(binary.LittleEndian.Uint32(buf[12:16])) + 1,
+			mapped: true,
 		}
+	}
 
-		// Unmarshal the op and apply it.
-		var op op
-		if err := op.UnmarshalBinary(buf); err != nil {
-			// FIXME(benbjohnson): return error with position so file can be trimmed.
-			return err
+	// Read container offsets and attach data.
+	for i, buf := 0, data[opsOffset:]; i < int(keyN); i, buf = i+1, buf[4:] {
+		offset := int(binary.LittleEndian.Uint32(buf[0:4]))
+
+		// Determine the size of the container data from its type.
+		c := b.containers[i]
+		var size int
+		switch types[i] {
+		case containerArray:
+			size = c.n * 4
+		case containerBitmap:
+			size = bitmapN * 8
+		default:
+			return 0, fmt.Errorf("unknown container type: key=%d, type=%d", b.keys[i], types[i])
 		}
-		op.apply(b)
 
-		// Increase the op count.
-		b.opN++
+		// Verify the container is within the bounds of the input data.
+		if offset+size > len(data) {
+			return 0, fmt.Errorf("offset out of bounds: off=%d, len=%d", offset, len(data))
+		}
 
-		// Move the buffer forward.
-		buf = buf[op.size():]
+		// Map byte slice directly to the container data.
+		if types[i] == containerArray {
+			c.array = (*[0xFFFFFFF]uint32)(unsafe.Pointer(&data[offset]))[:c.n]
+		} else {
+			c.bitmap = (*[0xFFFFFFF]uint64)(unsafe.Pointer(&data[offset]))[:bitmapN]
+		}
 	}
 
-	return nil
+	// The op log begins after the last container.
+	if keyN > 0 {
+		opsOffset = int(binary.LittleEndian.Uint32(data[opsOffset+int(keyN-1)*4:])) + b.containers[keyN-1].size()
+	}
+
+	return opsOffset, nil
+}
+
+// OpLogError is returned when the op log contains an invalid op, such as
+// from a torn write. All ops before Offset have been applied to the bitmap.
+type OpLogError struct {
+	Offset int // offset of the first invalid op
+	Size   int // size of the data
+	Err    error
+}
+
+// Error returns the error message.
+func (e *OpLogError) Error() string {
+	return fmt.Sprintf("invalid op: offset=%d, err=%s", e.Offset, e.Err)
+}
+
+// LostOpN returns the number of ops discarded by trimming at Offset.
+// A partially written op is counted as a lost op.
+func (e *OpLogError) LostOpN() int {
+	sz := (&op{}).size()
+	return (e.Size - e.Offset + sz - 1) / sz
 }
 
 // writeOp writes op to the OpWriter, if available.
@@ -637,6 +857,8 @@ func (b *Bitmap) Iterator() *Iterator {
 // Info returns stats for the bitmap.
 func (b *Bitmap) Info() BitmapInfo {
 	info := BitmapInfo{
+		Version:    b.version,
+		CreatedAt:  b.createdAt,
 		OpN:        b.opN,
 		Containers: make([]ContainerInfo, len(b.containers)),
 	}
@@ -650,6 +872,16 @@ func (b *Bitmap) Info() BitmapInfo {
 	return info
 }
 
+// Size returns the approximate number of bytes used by the bitmap's
+// keys & containers.
+func (b *Bitmap) Size() int {
+	n := len(b.keys) * 8
+	for _, c := range b.containers {
+		n += len(c.array)*4 + len(c.bitmap)*8
+	}
+	return n
+}
+
 // Check performs a consistency check on the bitmap. Returns nil if consistent.
 func (b *Bitmap) Check() error {
 	var a ErrorList
@@ -675,6 +907,8 @@ func (b *Bitmap) Check() error {
 
 // BitmapInfo represents a point-in-time snapshot of bitmap stats.
 type BitmapInfo struct {
+	Version    int
+	CreatedAt  time.Time
 	OpN        int
 	Containers []ContainerInfo
 }
@@ -250,6 +250,137 @@ func TestBitmap_Marshal_Quick_Bitmap_Sorted(t *testing.T) {
 	testBitmapMarshalQuick(t, 10000, 0, 10000, true)
 }
 
+// Ensure a frozen bitmap is not affected by changes to the original.
+func TestBitmap_Freeze(t *testing.T) {
+	a := make([]uint64, 0, 5000)
+	for i := uint64(0); i < 5000; i++ {
+		a = append(a, i*2)
+	}
+	bm := roaring.NewBitmap(a...)
+	bm.Add(1 << 20)
+
+	other := bm.Freeze()
+	bm.Add(1, 3, (1<<20)+1)
+	bm.Remove(0, 1<<20)
+
+	if n := other.Count(); n != 5001 {
+		t.Fatalf("unexpected frozen count: %d", n)
+	} else if !other.Contains(0) || other.Contains(1) || !other.Contains(1<<20) || other.Contains((1<<20)+1) {
+		t.Fatal("unexpected frozen values")
+	}
+	if n := bm.Count(); n != 5002 {
+		t.Fatalf("unexpected count: %d", n)
+	} else if bm.Contains(0) || !bm.Contains(1) || bm.Contains(1<<20) || !bm.Contains((1<<20)+1) {
+		t.Fatal("unexpected values")
+	}
+}
+
+// Ensure a torn op log returns the offset of the first invalid op.
+func TestBitmap_UnmarshalBinary_ErrOpLog(t *testing.T) {
+	bm := roaring.NewBitmap(1, 2)
+	var buf bytes.Buffer
+	if _, err := bm.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	snapshotN := buf.Len()
+
+	// Append two valid ops and a partial third op.
+	bm.OpWriter = &buf
+	if _, err := bm.Add(3); err != nil {
+		t.Fatal(err)
+	} else if _, err := bm.Add(4); err != nil {
+		t.Fatal(err)
+	} else if _, err := bm.Add(5); err != nil {
+		t.Fatal(err)
+	}
+	opsOffset := buf.Len() - 13
+	data := buf.Bytes()[:buf.Len()-4]
+
+	bm2 := roaring.NewBitmap()
+	err := bm2.UnmarshalBinary(data)
+	if err, ok := err.(*roaring.OpLogError); !ok {
+		t.Fatalf("unexpected error: %#v", err)
+	} else if err.Offset != opsOffset {
+		t.Fatalf("unexpected offset: %d (snapshot=%d)", err.Offset, snapshotN)
+	} else if n := err.LostOpN(); n != 1 {
+		t.Fatalf("unexpected lost op count: %d", n)
+	}
+
+	// Verify valid ops were applied.
+	if got := bm2.Slice(); !reflect.DeepEqual(got, []uint64{1, 2, 3, 4}) {
+		t.Fatalf("unexpected values: %+v", got)
+	}
+
+	// Verify a trimmed op log unmarshals cleanly.
+	if err := roaring.NewBitmap().UnmarshalBinary(data[:opsOffset]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ensure a bitmap is written with the current file format version.
+func TestBitmap_WriteTo_Version(t *testing.T) {
+	bm := roaring.NewBitmap(1, 2, 1<<20)
+	for i := uint64(0); i < 5000; i++ {
+		bm.Add((2 << 16) + i*2)
+	}
+
+	var buf bytes.Buffer
+	if _, err := bm.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	bm2 := roaring.NewBitmap()
+	if err := bm2.UnmarshalBinary(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if info := bm2.Info(); info.Version != roaring.FormatVersion {
+		t.Fatalf("unexpected version: %d", info.Version)
+	} else if info.CreatedAt.IsZero() {
+		t.Fatal("expected creation time")
+	} else if n := bm2.Count(); n != 5003 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+
+	// Rewriting the bitmap should keep the original creation time.
+	var buf2 bytes.Buffer
+	if _, err := bm2.WriteTo(&buf2); err != nil {
+		t.Fatal(err)
+	}
+	bm3 := roaring.NewBitmap()
+	if err := bm3.UnmarshalBinary(buf2.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if !bm3.Info().CreatedAt.Equal(bm2.Info().CreatedAt) {
+		t.Fatalf("unexpected creation time: %s", bm3.Info().CreatedAt)
+	}
+}
+
+// Ensure a version 0 file can be read.
+func TestBitmap_UnmarshalBinary_Version0(t *testing.T) {
+	// Cookie, key count, key header, offset & a single array container.
+	data := []byte{
+		0x3A, 0x30, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		0x18, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00,
+	}
+
+	bm := roaring.NewBitmap()
+	if err := bm.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	} else if info := bm.Info(); info.Version != roaring.FormatVersion0 {
+		t.Fatalf("unexpected version: %d", info.Version)
+	} else if got := bm.Slice(); !reflect.DeepEqual(got, []uint64{1, 5}) {
+		t.Fatalf("unexpected values: %+v", got)
+	}
+}
+
+// Ensure an unknown file format version returns an error.
+func TestBitmap_UnmarshalBinary_UnsupportedVersion(t *testing.T) {
+	data := []byte{0x3A, 0x30, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if err := roaring.NewBitmap().UnmarshalBinary(data); err == nil || err.Error() != "unsupported roaring file version: 255" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure a bitmap can be marshaled and unmarshaled.
 func testBitmapMarshalQuick(t *testing.T, n int, min, max uint64, sorted bool) {
 	if testing.Short() {
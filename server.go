@@ -15,6 +15,7 @@
 package pilosa
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -46,6 +47,10 @@ type Server struct {
 	wg      sync.WaitGroup
 	closing chan struct{}
 
+	// Anti-entropy requests & progress.
+	syncRequests chan SyncOptions
+	syncProgress SyncProgress
+
 	// Data storage and HTTP interface.
 	Holder            *Holder
 	Handler           *Handler
@@ -57,17 +62,36 @@ type Server struct {
 	Host    string
 	Cluster *Cluster
 
+	// TLS configuration. If set then HTTPS is served and used between nodes.
+	// If the configuration has client CAs then internal endpoints require
+	// a verified client certificate.
+	TLS *tls.Config
+
+	// Authenticates client requests. If nil then authentication is disabled.
+	Authenticator Authenticator
+
+	// Token sent as a bearer token on requests to other nodes.
+	// Required if Authenticator is set.
+	InternalToken string
+
 	// Background monitoring intervals.
 	AntiEntropyInterval time.Duration
 	PollingInterval     time.Duration
 
+	// Maximum bytes per second transferred by anti-entropy. Zero is unlimited.
+	AntiEntropyRateLimit int
+
+	// Fraction of queries which trigger a read repair. Zero disables read repair.
+	ReadRepairRate float64
+
 	LogOutput io.Writer
 }
 
 // NewServer returns a new instance of Server.
 func NewServer() *Server {
 	s := &Server{
-		closing: make(chan struct{}),
+		closing:      make(chan struct{}),
+		syncRequests: make(chan SyncOptions, 1),
 
 		Holder:            NewHolder(),
 		Handler:           NewHandler(),
@@ -87,6 +111,11 @@ func NewServer() *Server {
 
 // Open opens and initializes the server.
 func (s *Server) Open() error {
+	// Other nodes must be able to authenticate when authentication is enabled.
+	if s.Authenticator != nil && s.InternalToken == "" {
+		return ErrInternalTokenRequired
+	}
+
 	// Require a port in the hostname.
 	host, port, err := net.SplitHostPort(s.Host)
 	if err != nil {
@@ -102,6 +131,20 @@ func (s *Server) Open() error {
 	}
 	s.ln = ln
 
+	// Serve HTTPS and communicate with other nodes over TLS, if enabled.
+	if s.TLS != nil {
+		ln = tls.NewListener(ln, s.TLS)
+		s.Cluster.Scheme = "https"
+		if s.Cluster.HTTPClient == nil {
+			s.Cluster.HTTPClient = NewTLSHTTPClient(s.TLS)
+		}
+	}
+
+	// Authenticate requests to other nodes, if enabled.
+	if s.InternalToken != "" {
+		s.Cluster.HTTPClient = NewAuthHTTPClient(s.Cluster.httpClient(), s.InternalToken)
+	}
+
 	// Determine hostname based on listening port.
 	s.Host = net.JoinHostPort(host, strconv.Itoa(s.ln.Addr().(*net.TCPAddr).Port))
 
@@ -110,7 +153,9 @@ func (s *Server) Open() error {
 		s.Cluster.Nodes = []*Node{{Host: s.Host}}
 	}
 
-	// Open holder.
+	// Open holder. Requests wait on the listener until the indexes are open
+	// and fragments are then preloaded in the background while /health
+	// reports the holder as starting.
 	if err := s.Holder.Open(); err != nil {
 		return err
 	}
@@ -129,14 +174,19 @@ func (s *Server) Open() error {
 	e.Holder = s.Holder
 	e.Host = s.Host
 	e.Cluster = s.Cluster
+	e.HTTPClient = s.Cluster.httpClient()
+	e.ReadRepairRate = s.ReadRepairRate
 
 	// Initialize HTTP handler.
 	s.Handler.Broadcaster = s.Broadcaster
 	s.Handler.StatusHandler = s
+	s.Handler.AntiEntropyHandler = s
 	s.Handler.Host = s.Host
 	s.Handler.Cluster = s.Cluster
 	s.Handler.Executor = e
 	s.Handler.LogOutput = s.LogOutput
+	s.Handler.MutualTLS = s.TLS != nil && s.TLS.ClientCAs != nil
+	s.Handler.Authenticator = s.Authenticator
 
 	// Initialize Holder.
 	s.Holder.Broadcaster = s.Broadcaster
@@ -146,9 +196,10 @@ func (s *Server) Open() error {
 	go func() { http.Serve(ln, s.Handler) }()
 
 	// Start background monitoring.
-	s.wg.Add(2)
+	s.wg.Add(3)
 	go func() { defer s.wg.Done(); s.monitorAntiEntropy() }()
 	go func() { defer s.wg.Done(); s.monitorMaxSlices() }()
+	go func() { defer s.wg.Done(); e.ProcessReadRepairs(s.closing) }()
 
 	return nil
 }
@@ -162,6 +213,11 @@ func (s *Server) Close() error {
 	if s.ln != nil {
 		s.ln.Close()
 	}
+	if s.Cluster != nil {
+		if closer, ok := s.Cluster.NodeSet.(io.Closer); ok {
+			closer.Close()
+		}
+	}
 	if s.Holder != nil {
 		s.Holder.Close()
 	}
@@ -186,20 +242,25 @@ func (s *Server) monitorAntiEntropy() {
 	s.logger().Printf("holder sync monitor initializing (%s interval)", s.AntiEntropyInterval)
 
 	for {
-		// Wait for tick or a close.
+		// Wait for tick, an on-demand request, or a close.
+		var opt SyncOptions
 		select {
 		case <-s.closing:
 			return
 		case <-ticker.C:
+		case opt = <-s.syncRequests:
 		}
 
-		s.logger().Printf("holder sync beginning")
+		s.logger().Printf("holder sync beginning: index=%q, frame=%q, slices=[%d,%d)", opt.Index, opt.Frame, opt.SliceStart, opt.SliceEnd)
 
 		// Initialize syncer with local holder and remote client.
 		var syncer HolderSyncer
 		syncer.Holder = s.Holder
 		syncer.Host = s.Host
 		syncer.Cluster = s.Cluster
+		syncer.Options = opt
+		syncer.Limiter = NewRateLimiter(s.AntiEntropyRateLimit)
+		syncer.Progress = &s.syncProgress
 		syncer.Closing = s.closing
 
 		// Sync holders.
@@ -209,10 +270,30 @@ func (s *Server) monitorAntiEntropy() {
 		}
 
 		// Record successful sync in log.
-		s.logger().Printf("holder sync complete")
+		status := s.syncProgress.Status()
+		s.logger().Printf("holder sync complete: fragments=%d, blocks=%d, repaired=%d, bits=%d",
+			status.FragmentsDone, status.BlocksCompared, status.BlocksRepaired, status.BitsRepaired)
 	}
 }
 
+// TriggerSync queues an anti-entropy run limited by opt.
+// Returns ErrSyncPending if a run has already been requested but not started.
+// Server implements AntiEntropyHandler.
+func (s *Server) TriggerSync(opt SyncOptions) error {
+	select {
+	case s.syncRequests <- opt:
+		return nil
+	default:
+		return ErrSyncPending
+	}
+}
+
+// SyncStatus returns the progress of the current or most recent anti-entropy run.
+// Server implements AntiEntropyHandler.
+func (s *Server) SyncStatus() SyncStatus {
+	return s.syncProgress.Status()
+}
+
 // monitorMaxSlices periodically pulls the highest slice from each node in the cluster.
 func (s *Server) monitorMaxSlices() {
 	// Ignore if only one node in the cluster.
@@ -233,7 +314,7 @@ func (s *Server) monitorMaxSlices() {
 		oldmaxslices := s.Holder.MaxSlices()
 		for _, node := range s.Cluster.Nodes {
 			if s.Host != node.Host {
-				maxSlices, _ := checkMaxSlices(node.Host)
+				maxSlices, _ := s.checkMaxSlices(node.Host)
 				for index, newmax := range maxSlices {
 					// if we don't know about an index locally, log an error because
 					// indexes should be created and synced prior to slice creation
@@ -266,8 +347,10 @@ func (s *Server) ReceiveMessage(pb proto.Message) error {
 		}
 	case *internal.CreateIndexMessage:
 		opt := IndexOptions{
-			ColumnLabel: obj.Meta.ColumnLabel,
-			TimeQuantum: TimeQuantum(obj.Meta.TimeQuantum),
+			ColumnLabel:    obj.Meta.ColumnLabel,
+			TimeQuantum:    TimeQuantum(obj.Meta.TimeQuantum),
+			SliceWidth:     obj.Meta.SliceWidth,
+			StorageBackend: obj.Meta.StorageBackend,
 		}
 		_, err := s.Holder.CreateIndex(obj.Index, opt)
 		if err != nil {
@@ -285,6 +368,8 @@ func (s *Server) ReceiveMessage(pb proto.Message) error {
 			CacheType:      obj.Meta.CacheType,
 			CacheSize:      obj.Meta.CacheSize,
 			TimeQuantum:    TimeQuantum(obj.Meta.TimeQuantum),
+			Retention:      Retention(obj.Meta.Retention),
+			Mutex:          obj.Meta.Mutex,
 		}
 		_, err := index.CreateFrame(obj.Frame, opt)
 		if err != nil {
@@ -295,6 +380,22 @@ func (s *Server) ReceiveMessage(pb proto.Message) error {
 		if err := index.DeleteFrame(obj.Frame); err != nil {
 			return err
 		}
+	case *internal.DeleteViewMessage:
+		f := s.Holder.Frame(obj.Index, obj.Frame)
+		if f == nil {
+			return nil
+		}
+		if err := f.DeleteView(obj.View); err != nil {
+			return err
+		}
+	case *internal.RollUpViewsMessage:
+		f := s.Holder.Frame(obj.Index, obj.Frame)
+		if f == nil {
+			return nil
+		}
+		if _, err := f.RollUpViews(obj.Drop); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -362,8 +463,10 @@ func (s *Server) mergeRemoteStatus(ns *internal.NodeStatus) error {
 	// Create indexes that don't exist.
 	for _, index := range ns.Indexes {
 		opt := IndexOptions{
-			ColumnLabel: index.Meta.ColumnLabel,
-			TimeQuantum: TimeQuantum(index.Meta.TimeQuantum),
+			ColumnLabel:    index.Meta.ColumnLabel,
+			TimeQuantum:    TimeQuantum(index.Meta.TimeQuantum),
+			SliceWidth:     index.Meta.SliceWidth,
+			StorageBackend: index.Meta.StorageBackend,
 		}
 		idx, err := s.Holder.CreateIndexIfNotExists(index.Name, opt)
 		if err != nil {
@@ -375,6 +478,8 @@ func (s *Server) mergeRemoteStatus(ns *internal.NodeStatus) error {
 				RowLabel:    f.Meta.RowLabel,
 				TimeQuantum: TimeQuantum(f.Meta.TimeQuantum),
 				CacheSize:   f.Meta.CacheSize,
+				Retention:   Retention(f.Meta.Retention),
+				Mutex:       f.Meta.Mutex,
 			}
 			_, err := idx.CreateFrameIfNotExists(f.Name, opt)
 			if err != nil {
@@ -386,10 +491,10 @@ func (s *Server) mergeRemoteStatus(ns *internal.NodeStatus) error {
 	return nil
 }
 
-func checkMaxSlices(hostport string) (map[string]uint64, error) {
+func (s *Server) checkMaxSlices(hostport string) (map[string]uint64, error) {
 	// Create HTTP request.
 	req, err := http.NewRequest("GET", (&url.URL{
-		Scheme: "http",
+		Scheme: s.Cluster.scheme(),
 		Host:   hostport,
 		Path:   "/slices/max",
 	}).String(), nil)
@@ -403,7 +508,7 @@ func checkMaxSlices(hostport string) (map[string]uint64, error) {
 	req.Header.Set("Content-Type", "application/x-protobuf")
 
 	// Send request to remote node.
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.Cluster.httpClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -430,6 +535,13 @@ func checkMaxSlices(hostport string) (map[string]uint64, error) {
 	return pb.MaxSlices, nil
 }
 
+// AntiEntropyHandler specifies methods to trigger anti-entropy on demand and
+// to report its progress.
+type AntiEntropyHandler interface {
+	TriggerSync(opt SyncOptions) error
+	SyncStatus() SyncStatus
+}
+
 // StatusHandler specifies two methods which an object must implement to share
 // state in the cluster. These are used by the GossipNodeSet to implement the
 // LocalState and MergeRemoteState methods of memberlist.Delegate
@@ -99,12 +99,16 @@ func (m *Command) Run(args ...string) (err error) {
 	if err = m.Server.Open(); err != nil {
 		return fmt.Errorf("server.Open: %v", err)
 	}
-	fmt.Fprintf(m.Stderr, "Listening as http://%s\n", m.Server.Host)
+	fmt.Fprintf(m.Stderr, "Listening as %s://%s\n", m.Server.Cluster.Scheme, m.Server.Host)
 	return nil
 }
 
 // SetupServer use the cluster configuration to setup this server
 func (m *Command) SetupServer() error {
+	if err := m.Config.Validate(); err != nil {
+		return err
+	}
+
 	cluster := pilosa.NewCluster()
 	cluster.ReplicaN = m.Config.Cluster.ReplicaN
 
@@ -134,6 +138,21 @@ func (m *Command) SetupServer() error {
 	fmt.Fprintf(m.Stderr, "Using data from: %s\n", m.Config.DataDir)
 	m.Server.Holder.Path = m.Config.DataDir
 	m.Server.Holder.Stats = pilosa.NewExpvarStatsClient()
+	m.Server.Holder.Durability = pilosa.Durability{
+		Mode:     m.Config.Storage.Durability,
+		Interval: time.Duration(m.Config.Storage.GroupCommitInterval),
+	}
+	if err := m.Server.Holder.Durability.Validate(); err != nil {
+		return err
+	}
+	m.Server.Holder.SnapshotConcurrency = m.Config.Storage.SnapshotConcurrency
+	m.Server.Holder.MaxOpenFragments = m.Config.Storage.MaxOpenFragments
+	m.Server.Holder.FragmentIdleTimeout = time.Duration(m.Config.Storage.FragmentIdleTimeout)
+	m.Server.Holder.OpenConcurrency = m.Config.Storage.OpenConcurrency
+	m.Server.Holder.PreloadFragments = m.Config.Storage.PreloadFragments
+	m.Server.Holder.RetentionCheckInterval = time.Duration(m.Config.Storage.RetentionCheckInterval)
+	m.Server.Holder.RowCacheMaxBytes = m.Config.Storage.RowCacheMaxBytes
+	m.Server.Holder.FragmentRowCacheMaxBytes = m.Config.Storage.FragmentRowCacheMaxBytes
 
 	var err error
 	m.Server.Host, err = normalizeHost(m.Config.Host)
@@ -141,6 +160,26 @@ func (m *Command) SetupServer() error {
 		return err
 	}
 
+	// Configure TLS for client-facing and internal traffic.
+	cluster.Scheme = "http"
+	if m.Config.TLS.CertificatePath != "" {
+		tlsConfig, err := pilosa.NewTLSConfig(m.Config.TLS.CertificatePath, m.Config.TLS.CertificateKeyPath, m.Config.TLS.CACertificatePath, m.Config.TLS.SkipVerify)
+		if err != nil {
+			return err
+		}
+		m.Server.TLS = tlsConfig
+		cluster.Scheme = "https"
+		cluster.HTTPClient = pilosa.NewTLSHTTPClient(tlsConfig)
+	}
+
+	// Configure authentication.
+	auth, err := newAuthenticator(m.Config)
+	if err != nil {
+		return err
+	}
+	m.Server.Authenticator = auth
+	m.Server.InternalToken = m.Config.Auth.InternalToken
+
 	// Set internal port (string).
 	internalPortStr := pilosa.DefaultInternalPort
 	if m.Config.Cluster.InternalPort != "" {
@@ -150,7 +189,9 @@ func (m *Command) SetupServer() error {
 	switch m.Config.Cluster.Type {
 	case "http":
 		m.Server.Broadcaster = httpbroadcast.NewHTTPBroadcaster(m.Server, internalPortStr)
-		m.Server.BroadcastReceiver = httpbroadcast.NewHTTPBroadcastReceiver(internalPortStr, m.Stderr)
+		receiver := httpbroadcast.NewHTTPBroadcastReceiver(internalPortStr, m.Stderr)
+		receiver.TLSConfig = m.Server.TLS
+		m.Server.BroadcastReceiver = receiver
 		m.Server.Cluster.NodeSet = httpbroadcast.NewHTTPNodeSet()
 		err := m.Server.Cluster.NodeSet.(*httpbroadcast.HTTPNodeSet).Join(m.Server.Cluster.Nodes)
 		if err != nil {
@@ -176,10 +217,17 @@ func (m *Command) SetupServer() error {
 		m.Server.BroadcastReceiver = gossipNodeSet
 	case "static", "":
 		m.Server.Broadcaster = pilosa.NopBroadcaster
-		m.Server.Cluster.NodeSet = pilosa.NewStaticNodeSet()
+		nodeSet := pilosa.NewStaticNodeSet()
+		nodeSet.HealthCheckInterval = time.Duration(m.Config.Cluster.HealthCheckInterval)
+		nodeSet.HealthCheckTimeout = time.Duration(m.Config.Cluster.HealthCheckTimeout)
+		nodeSet.Scheme = cluster.Scheme
+		if cluster.HTTPClient != nil {
+			nodeSet.HTTPClient = cluster.HTTPClient
+		}
+		nodeSet.LogOutput = m.Server.LogOutput
+		m.Server.Cluster.NodeSet = nodeSet
 		m.Server.BroadcastReceiver = pilosa.NopBroadcastReceiver
-		err := m.Server.Cluster.NodeSet.(*pilosa.StaticNodeSet).Join(m.Server.Cluster.Nodes)
-		if err != nil {
+		if err := nodeSet.Join(m.Server.Cluster.Nodes); err != nil {
 			return err
 		}
 	default:
@@ -188,6 +236,8 @@ func (m *Command) SetupServer() error {
 
 	// Set configuration options.
 	m.Server.AntiEntropyInterval = time.Duration(m.Config.AntiEntropy.Interval)
+	m.Server.AntiEntropyRateLimit = m.Config.AntiEntropy.RateLimit
+	m.Server.ReadRepairRate = m.Config.AntiEntropy.ReadRepairRate
 	return nil
 }
 
@@ -197,13 +247,60 @@ func normalizeHost(host string) (string, error) {
 	} else if strings.Contains(host, "://") {
 		if strings.HasPrefix(host, "http://") {
 			host = host[7:]
+		} else if strings.HasPrefix(host, "https://") {
+			host = host[8:]
 		} else {
-			return "", fmt.Errorf("invalid scheme or host: '%s'. use the format [http://]<host>:<port>", host)
+			return "", fmt.Errorf("invalid scheme or host: '%s'. use the format [http[s]://]<host>:<port>", host)
 		}
 	}
 	return host, nil
 }
 
+// newAuthenticator returns an authenticator for the tokens and JWT key in c.
+// Returns nil if authentication is not configured.
+func newAuthenticator(c *pilosa.Config) (pilosa.Authenticator, error) {
+	var a pilosa.MultiAuthenticator
+
+	// Register static tokens, including the token used between nodes.
+	tokens := pilosa.NewTokenAuthenticator()
+	tokenN := 0
+	for _, t := range c.Auth.Tokens {
+		perms, err := pilosa.ParsePermissions(t.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("token %q: %s", t.Name, err)
+		} else if err := tokens.AddToken(t.Token, &pilosa.Principal{Name: t.Name, Permissions: perms}); err != nil {
+			return nil, fmt.Errorf("token %q: %s", t.Name, err)
+		}
+		tokenN++
+	}
+	if c.Auth.InternalToken != "" {
+		if err := tokens.AddToken(c.Auth.InternalToken, &pilosa.Principal{
+			Name:        "internal",
+			Permissions: map[string]pilosa.Permission{pilosa.AllIndexes: pilosa.PermissionAdmin},
+		}); err != nil {
+			return nil, err
+		}
+		tokenN++
+	}
+	if tokenN > 0 {
+		a = append(a, tokens)
+	}
+
+	// Verify JWTs, if a key is provided.
+	if c.Auth.JWTKeyPath != "" {
+		jwt, err := pilosa.NewJWTAuthenticator(c.Auth.JWTKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, jwt)
+	}
+
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return a, nil
+}
+
 // Close shuts down the server.
 func (m *Command) Close() error {
 	var logErr error
@@ -371,6 +371,24 @@ path = "/path/to/plugins"
 	}
 }
 
+// Ensure the read repair rate is validated.
+func TestConfig_Validate_ReadRepairRate(t *testing.T) {
+	for _, rate := range []string{"0.0", "0.5", "1.0"} {
+		if c, err := ParseConfig("[anti-entropy]\nread-repair-rate = " + rate); err != nil {
+			t.Fatal(err)
+		} else if err := c.Validate(); err != nil {
+			t.Fatalf("unexpected error for %s: %s", rate, err)
+		}
+	}
+	for _, rate := range []string{"-0.1", "1.5"} {
+		if c, err := ParseConfig("[anti-entropy]\nread-repair-rate = " + rate); err != nil {
+			t.Fatal(err)
+		} else if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "invalid read repair rate") {
+			t.Fatalf("unexpected error for %s: %v", rate, err)
+		}
+	}
+}
+
 // Ensure program can send/receive broadcast messages.
 func TestMain_SendReceiveMessage(t *testing.T) {
 	m0 := MustRunMain()
@@ -0,0 +1,612 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Storage backends.
+const (
+	StorageBackendMmap   = "mmap"
+	StorageBackendMemory = "memory"
+)
+
+// DefaultStorageBackend is the storage backend used by new indexes.
+const DefaultStorageBackend = StorageBackendMmap
+
+// IsValidStorageBackend returns true if name is a valid storage backend.
+func IsValidStorageBackend(name string) bool {
+	switch name {
+	case StorageBackendMmap, StorageBackendMemory:
+		return true
+	default:
+		return false
+	}
+}
+
+// StorageBackend persists the data beneath a fragment: a serialized roaring
+// bitmap followed by an append-only op log. Methods are called while holding
+// the fragment's lock except for StorageSnapshot.Write.
+type StorageBackend interface {
+	// Open opens the backend, creating it if it does not exist, and returns
+	// its contents. The data must not change until the backend is closed.
+	Open() ([]byte, error)
+
+	// AppendOp appends an encoded op to the op log.
+	AppendOp(op []byte) error
+
+	// Sync makes all appended ops durable.
+	Sync() error
+
+	// Snapshot replaces the contents with the bitmap written by fn and closes
+	// the backend. Ops appended during a background snapshot are discarded.
+	Snapshot(fn func(w io.Writer) error) error
+
+	// BeginSnapshot starts a snapshot of the current contents. Ops appended
+	// afterward are kept in a separate log until the snapshot is committed.
+	// If the backend is closed first, the log is applied on the next open.
+	BeginSnapshot() (StorageSnapshot, error)
+
+	// ReadAll returns a reader over the current contents, including ops
+	// appended during a background snapshot, and the number of bytes in it.
+	ReadAll() (io.ReadCloser, int64, error)
+
+	// Truncate discards the contents after size, such as a torn op, and
+	// closes the backend.
+	Truncate(size int64) error
+
+	// Close closes the backend. It can be reopened.
+	Close() error
+}
+
+// StorageSnapshot represents a snapshot started by StorageBackend.BeginSnapshot.
+type StorageSnapshot interface {
+	// Write writes the bitmap using fn. It is called without the fragment
+	// lock so the data returned by Open stays valid until Commit or Discard.
+	Write(fn func(w io.Writer) error) error
+
+	// Commit replaces the contents with the snapshot followed by the ops
+	// appended since it began and closes the backend.
+	Commit() error
+
+	// Discard abandons the snapshot.
+	Discard()
+}
+
+// storageOpWriter appends ops written by a roaring bitmap to a backend.
+type storageOpWriter struct {
+	backend StorageBackend
+}
+
+func (w storageOpWriter) Write(p []byte) (int, error) {
+	if err := w.backend.AppendOp(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// MmapBackend stores fragment data in a locked local file which is memory
+// mapped on open.
+type MmapBackend struct {
+	path       string
+	durability Durability
+
+	file    *os.File
+	segment *os.File
+	data    []byte
+
+	// In-flight background snapshot. It unmaps data if the backend is
+	// closed before the snapshot completes.
+	snapshot *mmapSnapshot
+}
+
+// NewMmapBackend returns a backend for the file at path. Snapshots are synced
+// to disk if durability is enabled.
+func NewMmapBackend(path string, durability Durability) *MmapBackend {
+	return &MmapBackend{
+		path:       path,
+		durability: durability,
+	}
+}
+
+// Open opens and locks the data file and returns its memory mapped contents.
+func (b *MmapBackend) Open() ([]byte, error) {
+	file, err := os.OpenFile(b.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %s", err)
+	}
+	b.file = file
+
+	// Lock the underlying file.
+	if err := syscall.Flock(int(b.file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("flock: %s", err)
+	}
+
+	// Append ops from an interrupted background snapshot.
+	if err := b.mergeSegment(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("merge segment: %s", err)
+	}
+
+	fi, err := b.file.Stat()
+	if err != nil {
+		b.Close()
+		return nil, err
+	} else if fi.Size() == 0 {
+		return nil, nil
+	}
+
+	// Mmap the underlying file so it can be zero copied.
+	data, err := syscall.Mmap(int(b.file.Fd()), 0, int(fi.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		b.Close()
+		return nil, fmt.Errorf("mmap: %s", err)
+	}
+	b.data = data
+
+	// Advise the kernel that the mmap is accessed randomly.
+	if err := madvise(b.data, syscall.MADV_RANDOM); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("madvise: %s", err)
+	}
+
+	return b.data, nil
+}
+
+// mergeSegment appends the segment file, if one exists, to the data file.
+// A segment is only left behind if the fragment closed during a background
+// snapshot, in which case its ops follow the ops in the data file.
+func (b *MmapBackend) mergeSegment() error {
+	path := b.path + SegmentExt
+	segment, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer segment.Close()
+
+	if _, err := io.Copy(b.file, segment); err != nil {
+		return err
+	} else if err := b.file.Sync(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// AppendOp appends op to the segment during a background snapshot and to the
+// data file otherwise.
+func (b *MmapBackend) AppendOp(op []byte) error {
+	_, err := b.opFile().Write(op)
+	return err
+}
+
+// Sync fsyncs the file that ops are currently appended to.
+func (b *MmapBackend) Sync() error {
+	return b.opFile().Sync()
+}
+
+// opFile returns the file that ops are currently appended to.
+func (b *MmapBackend) opFile() *os.File {
+	if b.segment != nil {
+		return b.segment
+	}
+	return b.file
+}
+
+// Snapshot writes fn to a temporary file and moves it over the data file.
+func (b *MmapBackend) Snapshot(fn func(w io.Writer) error) error {
+	snapshotPath := b.path + SnapshotExt
+	file, err := os.Create(snapshotPath)
+	if err != nil {
+		return fmt.Errorf("create snapshot file: %s", err)
+	}
+	defer file.Close()
+
+	bw := bufio.NewWriter(file)
+	if err := fn(bw); err != nil {
+		return fmt.Errorf("snapshot write to: %s", err)
+	} else if err := bw.Flush(); err != nil {
+		return fmt.Errorf("flush: %s", err)
+	}
+
+	// Ensure snapshot is on disk before it replaces the data file.
+	if b.durability.enabled() {
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("sync snapshot: %s", err)
+		}
+	}
+
+	if err := b.Close(); err != nil {
+		return fmt.Errorf("close: %s", err)
+	}
+
+	// Move snapshot to data file location.
+	// The snapshot includes any ops written to the segment.
+	if err := os.Rename(snapshotPath, b.path); err != nil {
+		return fmt.Errorf("rename snapshot: %s", err)
+	} else if err := b.syncDir(); err != nil {
+		return fmt.Errorf("sync dir: %s", err)
+	} else if err := os.Remove(b.path + SegmentExt); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove segment: %s", err)
+	}
+	return nil
+}
+
+// BeginSnapshot opens a new segment file for ops appended during the snapshot.
+func (b *MmapBackend) BeginSnapshot() (StorageSnapshot, error) {
+	segment, err := os.OpenFile(b.path+SegmentExt, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("open segment: %s", err)
+	}
+	b.segment = segment
+
+	// The path is unique per snapshot since a discarded snapshot may still
+	// be writing when the next one starts.
+	b.snapshot = &mmapSnapshot{
+		backend: b,
+		path:    fmt.Sprintf("%s%s.%d", b.path, BackgroundSnapshotExt, time.Now().UnixNano()),
+		data:    b.data,
+	}
+	return b.snapshot, nil
+}
+
+// ReadAll opens separate file descriptors to read the data file and segment
+// up to their current sizes. Since the files are append-only, the reader can
+// be used after the fragment lock is released.
+func (b *MmapBackend) ReadAll() (io.ReadCloser, int64, error) {
+	file, sz, err := openSized(b.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	r := &multiFileReader{files: []*os.File{file}}
+	readers := []io.Reader{io.LimitReader(file, sz)}
+
+	if b.segment != nil {
+		segment, segmentSz, err := openSized(b.path + SegmentExt)
+		if err != nil {
+			r.Close()
+			return nil, 0, err
+		}
+		r.files = append(r.files, segment)
+		readers = append(readers, io.LimitReader(segment, segmentSz))
+		sz += segmentSz
+	}
+
+	r.Reader = io.MultiReader(readers...)
+	return r, sz, nil
+}
+
+// Truncate closes the backend, copies the data file to a quarantine file so
+// the discarded ops can be inspected, and trims it to size.
+func (b *MmapBackend) Truncate(size int64) error {
+	if err := b.Close(); err != nil {
+		return fmt.Errorf("close: %s", err)
+	}
+
+	quarantinePath := fmt.Sprintf("%s%s.%d", b.path, QuarantineExt, time.Now().UnixNano())
+	if err := copyFile(b.path, quarantinePath); err != nil {
+		return fmt.Errorf("quarantine: %s", err)
+	} else if err := os.Truncate(b.path, size); err != nil {
+		return fmt.Errorf("truncate: %s", err)
+	}
+	return nil
+}
+
+// Close syncs, unlocks and closes the data file. The mmap is left to an
+// in-flight background snapshot, if any, to release once it is done.
+func (b *MmapBackend) Close() error {
+	if b.segment != nil {
+		if err := b.segment.Sync(); err != nil {
+			return fmt.Errorf("sync segment: %s", err)
+		} else if err := b.segment.Close(); err != nil {
+			return fmt.Errorf("close segment: %s", err)
+		}
+		b.segment = nil
+	}
+
+	// Unmap the file.
+	if b.snapshot != nil {
+		b.snapshot.orphaned = true
+		b.snapshot = nil
+	} else if b.data != nil {
+		if err := syscall.Munmap(b.data); err != nil {
+			return fmt.Errorf("munmap: %s", err)
+		}
+	}
+	b.data = nil
+
+	// Flush file, unlock & close.
+	if b.file != nil {
+		file := b.file
+		b.file = nil
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return fmt.Errorf("sync: %s", err)
+		} else if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+			file.Close()
+			return fmt.Errorf("unlock: %s", err)
+		} else if err := file.Close(); err != nil {
+			return fmt.Errorf("close file: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// syncDir fsyncs the data file's directory so a renamed file is durable.
+func (b *MmapBackend) syncDir() error {
+	if !b.durability.enabled() {
+		return nil
+	}
+	return syncDir(filepath.Dir(b.path))
+}
+
+// mmapSnapshot is a background snapshot written to a temporary file.
+type mmapSnapshot struct {
+	backend *MmapBackend
+	path    string
+	file    *os.File
+
+	// Mapped data referenced by the bitmap being written. Unmapped by the
+	// snapshot if the backend was closed while it was running.
+	data     []byte
+	orphaned bool
+}
+
+// Write writes the snapshot to a temporary file.
+func (s *mmapSnapshot) Write(fn func(w io.Writer) error) error {
+	file, err := os.Create(s.path)
+	if err != nil {
+		return fmt.Errorf("create snapshot file: %s", err)
+	}
+	s.file = file
+
+	bw := bufio.NewWriter(file)
+	if err := fn(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Commit appends the segment to the snapshot file and replaces the data
+// file with it.
+func (s *mmapSnapshot) Commit() error {
+	defer s.Discard()
+	b := s.backend
+
+	// Append ops written since the snapshot began.
+	if _, err := b.segment.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek segment: %s", err)
+	} else if _, err := io.Copy(s.file, b.segment); err != nil {
+		return fmt.Errorf("copy segment: %s", err)
+	}
+
+	// Ensure snapshot is on disk before it replaces the data file.
+	if b.durability.enabled() {
+		if err := s.file.Sync(); err != nil {
+			return fmt.Errorf("sync snapshot: %s", err)
+		}
+	}
+
+	if err := b.Close(); err != nil {
+		return fmt.Errorf("close: %s", err)
+	} else if err := os.Rename(s.path, b.path); err != nil {
+		return fmt.Errorf("rename snapshot: %s", err)
+	} else if err := b.syncDir(); err != nil {
+		return fmt.Errorf("sync dir: %s", err)
+	} else if err := os.Remove(b.path + SegmentExt); err != nil {
+		return fmt.Errorf("remove segment: %s", err)
+	}
+	return nil
+}
+
+// Discard removes the temporary file and releases the mapped data if the
+// backend no longer owns it.
+func (s *mmapSnapshot) Discard() {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	os.Remove(s.path)
+
+	if s.backend.snapshot == s {
+		s.backend.snapshot = nil
+	} else if s.orphaned && s.data != nil {
+		syscall.Munmap(s.data)
+		s.data = nil
+	}
+}
+
+// multiFileReader reads from a set of files and closes them all on Close.
+type multiFileReader struct {
+	io.Reader
+	files []*os.File
+}
+
+func (r *multiFileReader) Close() error {
+	var err error
+	for _, f := range r.files {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// openSized opens the file at path for reading and returns its current size.
+func openSized(path string) (*os.File, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, fi.Size(), nil
+}
+
+// copyFile copies the file at src to a new file at dst.
+func copyFile(src, dst string) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	} else if err := w.Sync(); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func madvise(b []byte, advice int) (err error) {
+	_, _, e1 := syscall.Syscall(syscall.SYS_MADVISE, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), uintptr(advice))
+	if e1 != 0 {
+		err = e1
+	}
+	return
+}
+
+// MemoryBackend stores fragment data on the heap. Data is kept when the
+// backend is closed but is lost when the process exits, which makes it
+// suitable for tests and ephemeral indexes.
+type MemoryBackend struct {
+	// Contents & ops appended during a background snapshot. Bytes are never
+	// modified once written so that opened data stays valid.
+	data    []byte
+	segment []byte
+
+	snapshotting bool
+	snapshot     *memorySnapshot
+}
+
+// NewMemoryBackend returns a new, empty in-memory backend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+// Open returns the contents with any ops from an interrupted snapshot appended.
+func (b *MemoryBackend) Open() ([]byte, error) {
+	if len(b.segment) > 0 {
+		b.data = append(b.data[:len(b.data):len(b.data)], b.segment...)
+		b.segment = nil
+	}
+	return b.data[:len(b.data):len(b.data)], nil
+}
+
+// AppendOp appends op to the contents or to the snapshot segment.
+func (b *MemoryBackend) AppendOp(op []byte) error {
+	if b.snapshotting {
+		b.segment = append(b.segment, op...)
+	} else {
+		b.data = append(b.data, op...)
+	}
+	return nil
+}
+
+// Sync is a no-op since data is never written to disk.
+func (b *MemoryBackend) Sync() error { return nil }
+
+// Snapshot replaces the contents with the bitmap written by fn.
+func (b *MemoryBackend) Snapshot(fn func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		return fmt.Errorf("snapshot write to: %s", err)
+	}
+
+	b.Close()
+	b.data, b.segment = buf.Bytes(), nil
+	return nil
+}
+
+// BeginSnapshot redirects appended ops to a separate segment.
+func (b *MemoryBackend) BeginSnapshot() (StorageSnapshot, error) {
+	b.snapshotting = true
+	b.segment = nil
+	b.snapshot = &memorySnapshot{backend: b}
+	return b.snapshot, nil
+}
+
+// ReadAll returns a reader over the contents and snapshot segment.
+func (b *MemoryBackend) ReadAll() (io.ReadCloser, int64, error) {
+	data, segment := b.data[:len(b.data):len(b.data)], b.segment[:len(b.segment):len(b.segment)]
+	r := io.MultiReader(bytes.NewReader(data), bytes.NewReader(segment))
+	return ioutil.NopCloser(r), int64(len(data) + len(segment)), nil
+}
+
+// Truncate copies the contents up to size.
+func (b *MemoryBackend) Truncate(size int64) error {
+	b.Close()
+	b.data = append([]byte(nil), b.data[:size]...)
+	return nil
+}
+
+// Close ends any background snapshot. Ops appended during the snapshot are
+// applied on the next open.
+func (b *MemoryBackend) Close() error {
+	b.snapshotting = false
+	b.snapshot = nil
+	return nil
+}
+
+// memorySnapshot is a background snapshot written to a buffer.
+type memorySnapshot struct {
+	backend *MemoryBackend
+	buf     bytes.Buffer
+}
+
+// Write writes the snapshot to a buffer.
+func (s *memorySnapshot) Write(fn func(w io.Writer) error) error {
+	return fn(&s.buf)
+}
+
+// Commit replaces the contents with the snapshot and its segment.
+func (s *memorySnapshot) Commit() error {
+	b := s.backend
+	data := append(s.buf.Bytes(), b.segment...)
+	b.Close()
+	b.data, b.segment = data, nil
+	return nil
+}
+
+// Discard abandons the snapshot.
+func (s *memorySnapshot) Discard() {
+	if s.backend.snapshot == s {
+		s.backend.snapshot = nil
+	}
+}
@@ -17,13 +17,21 @@ package pilosa
 import (
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/pilosa/pilosa/internal"
 )
 
 // ErrInvalidTimeQuantum is returned when parsing a time quantum.
 var ErrInvalidTimeQuantum = errors.New("invalid time quantum")
 
+// ErrInvalidRetention is returned when parsing a retention policy.
+var ErrInvalidRetention = errors.New("invalid retention")
+
 // TimeQuantum represents a time granularity for time-based bitmaps.
 type TimeQuantum string
 
@@ -39,18 +47,39 @@ func (q TimeQuantum) HasDay() bool { return strings.ContainsRune(string(q), 'D')
 // HasHour returns true if the quantum contains a 'H' unit.
 func (q TimeQuantum) HasHour() bool { return strings.ContainsRune(string(q), 'H') }
 
-// Valid returns true if q is a valid time quantum value.
+// HasWeek returns true if the quantum contains a 'W' unit.
+func (q TimeQuantum) HasWeek() bool { return strings.ContainsRune(string(q), 'W') }
+
+// HasMinute returns true if the quantum contains a 'T' unit.
+func (q TimeQuantum) HasMinute() bool { return strings.ContainsRune(string(q), 'T') }
+
+// timeQuantumSequences lists the units that may be combined in a quantum from
+// coarsest to finest. Weeks do not align with months or years so they cannot
+// be combined with them.
+var timeQuantumSequences = []string{"YMDHT", "WDHT"}
+
+// Valid returns true if q is a valid time quantum value. A valid quantum is
+// a run of consecutive units from "YMDHT" or "WDHT".
 func (q TimeQuantum) Valid() bool {
-	switch q {
-	case "Y", "YM", "YMD", "YMDH",
-		"M", "MD", "MDH",
-		"D", "DH",
-		"H",
-		"":
+	if q == "" {
 		return true
-	default:
-		return false
 	}
+	for _, seq := range timeQuantumSequences {
+		if strings.Contains(seq, string(q)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCoarserUnit returns true if q contains a unit which unit nests within.
+func (q TimeQuantum) hasCoarserUnit(unit rune) bool {
+	for _, u := range q {
+		if nestedTimeUnit(unit, u) {
+			return true
+		}
+	}
+	return false
 }
 
 // ParseTimeQuantum parses v into a time quantum.
@@ -69,10 +98,15 @@ func ViewByTimeUnit(name string, t time.Time, unit rune) string {
 		return fmt.Sprintf("%s_%s", name, t.Format("2006"))
 	case 'M':
 		return fmt.Sprintf("%s_%s", name, t.Format("200601"))
+	case 'W':
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%s_%04dW%02d", name, year, week)
 	case 'D':
 		return fmt.Sprintf("%s_%s", name, t.Format("20060102"))
 	case 'H':
 		return fmt.Sprintf("%s_%s", name, t.Format("2006010215"))
+	case 'T':
+		return fmt.Sprintf("%s_%s", name, t.Format("200601021504"))
 	default:
 		return ""
 	}
@@ -92,104 +126,341 @@ func ViewsByTime(name string, t time.Time, q TimeQuantum) []string {
 }
 
 // ViewsByTimeRange returns a list of views to traverse to query a time range.
+// The range is covered using the coarsest views that fit entirely within it.
+// A partial unit at either end of the range uses the finest view in q.
 func ViewsByTimeRange(name string, start, end time.Time, q TimeQuantum) []string {
-	t := start
-
-	// Save flags for performance.
-	hasYear := q.HasYear()
-	hasMonth := q.HasMonth()
-	hasDay := q.HasDay()
-	hasHour := q.HasHour()
+	// Order units from coarsest to finest.
+	var units []rune
+	for i := len(timeUnits) - 1; i >= 0; i-- {
+		if unit := rune(timeUnits[i]); strings.ContainsRune(string(q), unit) {
+			units = append(units, unit)
+		}
+	}
+	if len(units) == 0 {
+		return nil
+	}
+	finest := units[len(units)-1]
 
 	var results []string
+	for t := start; t.Before(end); {
+		// Use the coarsest unit which starts at t and ends within the range.
+		unit := finest
+		for _, u := range units {
+			if truncateTimeUnit(t, u).Equal(t) && !addTimeUnit(t, u).After(end) {
+				unit = u
+				break
+			}
+		}
 
-	// Walk up from smallest units to largest units.
-	if hasHour || hasDay || hasMonth {
-		for t.Before(end) {
-			if hasHour {
-				if !nextDayGTE(t, end) {
-					break
-				} else if t.Hour() != 0 {
-					results = append(results, ViewByTimeUnit(name, t, 'H'))
-					t = t.Add(time.Hour)
-					continue
-				}
+		results = append(results, ViewByTimeUnit(name, t, unit))
+		t = addTimeUnit(truncateTimeUnit(t, unit), unit)
+	}
+	return results
+}
 
-			}
+// viewTimeLayouts are the time formats used in view names by unit.
+// Week views use the ISO year & week, such as "2017W05".
+var viewTimeLayouts = map[rune]string{
+	'Y': "2006",
+	'M': "200601",
+	'D': "20060102",
+	'H': "2006010215",
+	'T': "200601021504",
+}
 
-			if hasDay {
-				if !nextMonthGTE(t, end) {
-					break
-				} else if t.Day() != 1 {
-					results = append(results, ViewByTimeUnit(name, t, 'D'))
-					t = t.AddDate(0, 0, 1)
-					continue
-				}
-			}
+// ParseTimeView returns the time unit and the time range covered by a time
+// view, such as "standard_2017010215". Returns false if name is not a time view.
+func ParseTimeView(name string) (unit rune, start, end time.Time, ok bool) {
+	i := strings.LastIndex(name, "_")
+	if i == -1 {
+		return 0, time.Time{}, time.Time{}, false
+	} else if base := name[:i]; base != ViewStandard && base != ViewInverse {
+		return 0, time.Time{}, time.Time{}, false
+	}
 
-			if hasMonth {
-				if !nextYearGTE(t, end) {
-					break
-				} else if t.Month() != 1 {
-					results = append(results, ViewByTimeUnit(name, t, 'M'))
-					t = t.AddDate(0, 1, 0)
-					continue
-				}
-			}
+	suffix := name[i+1:]
+	if t, ok := parseWeekView(suffix); ok {
+		return 'W', t, addTimeUnit(t, 'W'), true
+	}
 
-			// If a unit exists but isn't set and there are no larger units
-			// available then we need to exit the loop because we are no longer
-			// making progress.
-			break
+	for u, layout := range viewTimeLayouts {
+		if len(suffix) != len(layout) {
+			continue
 		}
+
+		t, err := time.Parse(layout, suffix)
+		if err != nil {
+			return 0, time.Time{}, time.Time{}, false
+		}
+		return u, t, addTimeUnit(t, u), true
+	}
+	return 0, time.Time{}, time.Time{}, false
+}
+
+// parseWeekView returns the start of the ISO week in a view suffix, such as "2017W05".
+func parseWeekView(suffix string) (time.Time, bool) {
+	if len(suffix) != 7 || suffix[4] != 'W' {
+		return time.Time{}, false
 	}
 
-	// Walk back down from largest units to smallest units.
-	for t.Before(end) {
-		if hasYear && nextYearGTE(t, end) {
-			results = append(results, ViewByTimeUnit(name, t, 'Y'))
-			t = t.AddDate(1, 0, 0)
-		} else if hasMonth && nextMonthGTE(t, end) {
-			results = append(results, ViewByTimeUnit(name, t, 'M'))
-			t = t.AddDate(0, 1, 0)
-		} else if hasDay && nextDayGTE(t, end) {
-			results = append(results, ViewByTimeUnit(name, t, 'D'))
-			t = t.AddDate(0, 0, 1)
-		} else if hasHour {
-			results = append(results, ViewByTimeUnit(name, t, 'H'))
-			t = t.Add(time.Hour)
-		} else {
-			break
+	year, err := strconv.Atoi(suffix[:4])
+	if err != nil {
+		return time.Time{}, false
+	}
+	week, err := strconv.Atoi(suffix[5:])
+	if err != nil || week < 1 || week > 53 {
+		return time.Time{}, false
+	}
+
+	// January 4th is always in the first ISO week of the year.
+	t := truncateTimeUnit(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC), 'W')
+	return t.AddDate(0, 0, (week-1)*7), true
+}
+
+// ParseTime parses a time value from a query. The value may be a timestamp in
+// TimeFormat or RFC3339, "now", or a duration relative to now such as "-7d"
+// or "+12h". The returned time is in UTC.
+func ParseTime(v string, now time.Time) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	switch {
+	case v == "now":
+		return now.UTC(), nil
+	case strings.HasPrefix(v, "-"), strings.HasPrefix(v, "+"):
+		d, err := parseDuration(v[1:])
+		if err != nil {
+			return time.Time{}, err
+		} else if v[0] == '-' {
+			d = -d
 		}
+		return now.Add(d).UTC(), nil
 	}
 
-	return results
+	if t, err := time.Parse(TimeFormat, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
 }
 
-func nextYearGTE(t time.Time, end time.Time) bool {
-	next := t.AddDate(1, 0, 0)
-	if next.Year() == end.Year() {
-		return true
+// IsRelativeTime returns true if v is a time relative to now, such as "-7d".
+func IsRelativeTime(v string) bool {
+	v = strings.TrimSpace(v)
+	return v == "now" || strings.HasPrefix(v, "-") || strings.HasPrefix(v, "+")
+}
+
+// decayWeight returns the weight of a time view at t which halves for every
+// halfLife that the middle of the view is older than t. Views which are not
+// time views have a weight of one.
+func decayWeight(name string, t time.Time, halfLife time.Duration) float64 {
+	_, start, end, ok := ParseTimeView(name)
+	if !ok {
+		return 1
 	}
-	return end.After(next)
+
+	age := t.Sub(start.Add(end.Sub(start) / 2))
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
 }
 
-func nextMonthGTE(t time.Time, end time.Time) bool {
-	next := t.AddDate(0, 1, 0)
-	y1, m1, _ := next.Date()
-	y2, m2, _ := end.Date()
-	if (y1 == y2) && (m1 == m2) {
-		return true
+// timeIntervals maps interval names to time units.
+var timeIntervals = map[string]rune{
+	"minute": 'T',
+	"hour":   'H',
+	"day":    'D',
+	"week":   'W',
+	"month":  'M',
+	"year":   'Y',
+}
+
+// parseTimeInterval returns the time unit for an interval name, such as "day".
+func parseTimeInterval(v string) (rune, error) {
+	unit, ok := timeIntervals[strings.ToLower(v)]
+	if !ok {
+		return 0, fmt.Errorf("invalid time interval: %q", v)
 	}
-	return end.After(next)
+	return unit, nil
 }
 
-func nextDayGTE(t time.Time, end time.Time) bool {
-	next := t.AddDate(0, 0, 1)
-	y1, m1, d1 := next.Date()
-	y2, m2, d2 := end.Date()
-	if (y1 == y2) && (m1 == m2) && (d1 == d2) {
-		return true
+// TimeCount represents the count for the time interval starting at Time.
+type TimeCount struct {
+	Time  time.Time `json:"time"`
+	Count uint64    `json:"count"`
+}
+
+// TimeCounts represents a series of counts by time interval.
+type TimeCounts []TimeCount
+
+// Add merges the counts of other into p by time and returns a new slice
+// sorted by time.
+func (p TimeCounts) Add(other []TimeCount) []TimeCount {
+	m := make(map[int64]TimeCount, len(p))
+	for _, a := range [][]TimeCount{p, other} {
+		for _, tc := range a {
+			prev := m[tc.Time.UnixNano()]
+			m[tc.Time.UnixNano()] = TimeCount{Time: tc.Time, Count: prev.Count + tc.Count}
+		}
+	}
+
+	a := make(TimeCounts, 0, len(m))
+	for _, tc := range m {
+		a = append(a, tc)
+	}
+	sort.Sort(a)
+	return a
+}
+
+func (p TimeCounts) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p TimeCounts) Len() int           { return len(p) }
+func (p TimeCounts) Less(i, j int) bool { return p[i].Time.Before(p[j].Time) }
+
+func encodeTimeCounts(a []TimeCount) []*internal.TimeCount {
+	other := make([]*internal.TimeCount, len(a))
+	for i := range a {
+		other[i] = &internal.TimeCount{Time: a[i].Time.UnixNano(), Count: a[i].Count}
+	}
+	return other
+}
+
+func decodeTimeCounts(a []*internal.TimeCount) []TimeCount {
+	other := make([]TimeCount, len(a))
+	for i := range a {
+		other[i] = TimeCount{Time: time.Unix(0, a[i].Time).UTC(), Count: a[i].Count}
+	}
+	return other
+}
+
+// timeUnits lists the time quantum units from finest to coarsest.
+const timeUnits = "THDWMY"
+
+// nestedTimeUnit returns true if every unit a lies entirely within a unit b.
+func nestedTimeUnit(a, b rune) bool {
+	if a == 'W' && (b == 'M' || b == 'Y') {
+		return false
+	}
+	return strings.IndexRune(timeUnits, a) < strings.IndexRune(timeUnits, b)
+}
+
+// truncateTimeUnit returns t rounded down to the start of its time unit.
+// Weeks start on Monday.
+func truncateTimeUnit(t time.Time, unit rune) time.Time {
+	switch unit {
+	case 'Y':
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	case 'M':
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case 'W':
+		t = truncateTimeUnit(t, 'D')
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case 'D':
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case 'H':
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	}
+}
+
+// addTimeUnit returns t advanced by one time unit.
+func addTimeUnit(t time.Time, unit rune) time.Time {
+	switch unit {
+	case 'Y':
+		return t.AddDate(1, 0, 0)
+	case 'M':
+		return t.AddDate(0, 1, 0)
+	case 'W':
+		return t.AddDate(0, 0, 7)
+	case 'D':
+		return t.AddDate(0, 0, 1)
+	case 'H':
+		return t.Add(time.Hour)
+	default:
+		return t.Add(time.Minute)
+	}
+}
+
+// Retention represents how long time views are kept for each time unit,
+// such as "H=7d,D=90d". Views of units without a duration are kept forever.
+type Retention string
+
+// ParseRetention parses v into a retention policy.
+func ParseRetention(v string) (Retention, error) {
+	r := Retention(strings.TrimSpace(v))
+	if _, err := r.Durations(); err != nil {
+		return "", err
+	}
+	return r, nil
+}
+
+// Valid returns true if r is a valid retention policy.
+func (r Retention) Valid() bool {
+	_, err := r.Durations()
+	return err == nil
+}
+
+// Durations returns the retention duration for each time unit in r.
+func (r Retention) Durations() (map[rune]time.Duration, error) {
+	m := make(map[rune]time.Duration)
+	if r == "" {
+		return m, nil
+	}
+
+	for _, item := range strings.Split(string(r), ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 || len(kv[0]) != 1 {
+			return nil, ErrInvalidRetention
+		}
+
+		unit := rune(strings.ToUpper(kv[0])[0])
+		if !strings.ContainsRune(timeUnits, unit) {
+			return nil, ErrInvalidRetention
+		} else if _, ok := m[unit]; ok {
+			return nil, ErrInvalidRetention
+		}
+
+		d, err := parseDuration(kv[1])
+		if err != nil || d <= 0 {
+			return nil, ErrInvalidRetention
+		}
+		m[unit] = d
+	}
+	return m, nil
+}
+
+// Expired returns true if the time view name is past its retention at now.
+// A view expires once all of the time it covers is older than the retention.
+func (r Retention) Expired(name string, now time.Time) bool {
+	unit, _, end, ok := ParseTimeView(name)
+	if !ok {
+		return false
+	}
+
+	durations, err := r.Durations()
+	if err != nil {
+		return false
+	}
+
+	d, ok := durations[unit]
+	if !ok {
+		return false
+	}
+	return !end.Add(d).After(now)
+}
+
+// parseDuration parses a duration which may also be a whole number of days,
+// such as "90d".
+func parseDuration(v string) (time.Duration, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if strings.HasSuffix(v, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
 	}
-	return end.After(next)
+	return time.ParseDuration(v)
 }
@@ -32,9 +32,19 @@ func TestParseTimeQuantum(t *testing.T) {
 		}
 	})
 
+	t.Run("Minute", func(t *testing.T) {
+		for _, v := range []string{"YMDHT", "dht", "T", "W", "WDHT"} {
+			if _, err := pilosa.ParseTimeQuantum(v); err != nil {
+				t.Fatalf("unexpected error for %q: %s", v, err)
+			}
+		}
+	})
+
 	t.Run("ErrInvalidTimeQuantum", func(t *testing.T) {
-		if _, err := pilosa.ParseTimeQuantum("BADQUANTUM"); err != pilosa.ErrInvalidTimeQuantum {
-			t.Fatalf("unexpected error: %s", err)
+		for _, v := range []string{"BADQUANTUM", "YD", "YMW", "WM", "TH", "YMDHTT"} {
+			if _, err := pilosa.ParseTimeQuantum(v); err != pilosa.ErrInvalidTimeQuantum {
+				t.Fatalf("unexpected error for %q: %v", v, err)
+			}
 		}
 	})
 }
@@ -63,6 +73,17 @@ func TestViewByTimeUnit(t *testing.T) {
 			t.Fatalf("unexpected name: %s", s)
 		}
 	})
+	t.Run("T", func(t *testing.T) {
+		if s := pilosa.ViewByTimeUnit("F", ts, 'T'); s != "F_200001020304" {
+			t.Fatalf("unexpected name: %s", s)
+		}
+	})
+	t.Run("W", func(t *testing.T) {
+		// January 2nd, 2000 is a Sunday in the last ISO week of 1999.
+		if s := pilosa.ViewByTimeUnit("F", ts, 'W'); s != "F_1999W52" {
+			t.Fatalf("unexpected name: %s", s)
+		}
+	})
 }
 
 // Ensure all applicable frame names can be generated when mutating a time bit.
@@ -146,6 +167,67 @@ func TestViewsByTimeRange(t *testing.T) {
 			t.Fatalf("unexpected frames: %#v", a)
 		}
 	})
+	t.Run("T", func(t *testing.T) {
+		a := pilosa.ViewsByTimeRange("F", MustParseTime("2000-01-01 23:58"), MustParseTime("2000-01-02 00:02"), MustParseTimeQuantum("T"))
+		if !reflect.DeepEqual(a, []string{"F_200001012358", "F_200001012359", "F_200001020000", "F_200001020001"}) {
+			t.Fatalf("unexpected frames: %#v", a)
+		}
+	})
+	t.Run("HT", func(t *testing.T) {
+		a := pilosa.ViewsByTimeRange("F", MustParseTime("2000-01-01 22:58"), MustParseTime("2000-01-02 01:01"), MustParseTimeQuantum("HT"))
+		if !reflect.DeepEqual(a, []string{"F_200001012258", "F_200001012259", "F_2000010123", "F_2000010200", "F_200001020100"}) {
+			t.Fatalf("unexpected frames: %#v", a)
+		}
+	})
+	t.Run("YMDHT", func(t *testing.T) {
+		a := pilosa.ViewsByTimeRange("F", MustParseTime("1999-12-31 23:59"), MustParseTime("2001-02-01 00:01"), MustParseTimeQuantum("YMDHT"))
+		if !reflect.DeepEqual(a, []string{"F_199912312359", "F_2000", "F_200101", "F_200102010000"}) {
+			t.Fatalf("unexpected frames: %#v", a)
+		}
+	})
+	t.Run("WD", func(t *testing.T) {
+		// January 3rd, 2000 is a Monday.
+		a := pilosa.ViewsByTimeRange("F", MustParseTime("2000-01-01 00:00"), MustParseTime("2000-01-19 00:00"), MustParseTimeQuantum("WD"))
+		if !reflect.DeepEqual(a, []string{"F_20000101", "F_20000102", "F_2000W01", "F_2000W02", "F_20000117", "F_20000118"}) {
+			t.Fatalf("unexpected frames: %#v", a)
+		}
+	})
+	t.Run("PartialStart", func(t *testing.T) {
+		a := pilosa.ViewsByTimeRange("F", MustParseTime("2000-01-01 10:30"), MustParseTime("2000-01-01 12:00"), MustParseTimeQuantum("DH"))
+		if !reflect.DeepEqual(a, []string{"F_2000010110", "F_2000010111"}) {
+			t.Fatalf("unexpected frames: %#v", a)
+		}
+	})
+}
+
+// Ensure the unit & time range of a time view can be parsed from its name.
+func TestParseTimeView(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		unit       rune
+		start, end string
+	}{
+		{"standard_2000", 'Y', "2000-01-01 00:00", "2001-01-01 00:00"},
+		{"inverse_200002", 'M', "2000-02-01 00:00", "2000-03-01 00:00"},
+		{"standard_1999W52", 'W', "1999-12-27 00:00", "2000-01-03 00:00"},
+		{"standard_2000W01", 'W', "2000-01-03 00:00", "2000-01-10 00:00"},
+		{"standard_20000102", 'D', "2000-01-02 00:00", "2000-01-03 00:00"},
+		{"standard_2000010203", 'H', "2000-01-02 03:00", "2000-01-02 04:00"},
+		{"standard_200001020304", 'T', "2000-01-02 03:04", "2000-01-02 03:05"},
+	} {
+		unit, start, end, ok := pilosa.ParseTimeView(tt.name)
+		if !ok {
+			t.Errorf("%s: expected time view", tt.name)
+		} else if unit != tt.unit || !start.Equal(MustParseTime(tt.start)) || !end.Equal(MustParseTime(tt.end)) {
+			t.Errorf("%s: unexpected view: unit=%c start=%s end=%s", tt.name, unit, start, end)
+		}
+	}
+
+	for _, name := range []string{"standard", "standard_2000W54", "other_2000", "standard_20000"} {
+		if _, _, _, ok := pilosa.ParseTimeView(name); ok {
+			t.Errorf("%s: unexpected time view", name)
+		}
+	}
 }
 
 // DefaultTimeLayout is the time layout used by the tests.
@@ -174,3 +256,82 @@ func MustParseTimeQuantum(v string) pilosa.TimeQuantum {
 	}
 	return q
 }
+
+// Ensure string can be parsed into a retention policy.
+func TestParseRetention(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		r, err := pilosa.ParseRetention("h=7d, D=2160h")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		m, err := r.Durations()
+		if err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(m, map[rune]time.Duration{'H': 7 * 24 * time.Hour, 'D': 90 * 24 * time.Hour}) {
+			t.Fatalf("unexpected durations: %#v", m)
+		}
+	})
+
+	t.Run("ErrInvalidRetention", func(t *testing.T) {
+		for _, v := range []string{"H", "X=7d", "H=0d", "H=-1h", "H=7d,H=8d", "D=abc"} {
+			if _, err := pilosa.ParseRetention(v); err != pilosa.ErrInvalidRetention {
+				t.Fatalf("unexpected error for %q: %v", v, err)
+			}
+		}
+	})
+}
+
+// Ensure time views expire once all of their time is past the retention.
+func TestRetention_Expired(t *testing.T) {
+	r := pilosa.Retention("H=24h,D=7d")
+	now := time.Date(2017, time.March, 10, 12, 30, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name    string
+		expired bool
+	}{
+		{"standard_2017030911", true},
+		{"standard_2017030912", false},
+		{"inverse_2017030911", true},
+		{"standard_20170302", true},
+		{"standard_20170303", false},
+		{"standard_201701", false},
+		{"standard_2015", false},
+		{"standard", false},
+		{"other_2017030911", false},
+	} {
+		if v := r.Expired(tt.name, now); v != tt.expired {
+			t.Errorf("%s: expired=%v, expected %v", tt.name, v, tt.expired)
+		}
+	}
+}
+
+// Ensure absolute, zoned & relative times can be parsed from a query.
+func TestParseTime(t *testing.T) {
+	now := time.Date(2017, time.March, 10, 12, 30, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		v        string
+		expected time.Time
+	}{
+		{"2017-01-02T03:04", time.Date(2017, time.January, 2, 3, 4, 0, 0, time.UTC)},
+		{"2017-01-02T03:04:05Z", time.Date(2017, time.January, 2, 3, 4, 5, 0, time.UTC)},
+		{"2017-01-02T03:04:05-05:00", time.Date(2017, time.January, 2, 8, 4, 5, 0, time.UTC)},
+		{"now", now},
+		{"-7d", now.AddDate(0, 0, -7)},
+		{"+90m", now.Add(90 * time.Minute)},
+	} {
+		if v, err := pilosa.ParseTime(tt.v, now); err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.v, err)
+		} else if !v.Equal(tt.expected) || v.Location() != time.UTC {
+			t.Errorf("%s: got %s, expected %s", tt.v, v, tt.expected)
+		}
+	}
+
+	for _, v := range []string{"", "yesterday", "-7x", "2017-01-02"} {
+		if _, err := pilosa.ParseTime(v, now); err == nil {
+			t.Errorf("%s: expected error", v)
+		}
+	}
+}
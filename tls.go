@@ -0,0 +1,131 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// NewTLSConfig returns a TLS configuration which serves and presents the
+// certificate & key at certPath and keyPath.
+//
+// If caPath is set then remote nodes are verified against that certificate
+// authority and client certificates signed by it are accepted, which enables
+// mutual TLS between nodes. If skipVerify is set then server certificates
+// are not verified by clients.
+func NewTLSConfig(certPath, keyPath, caPath string, skipVerify bool) (*tls.Config, error) {
+	if certPath == "" || keyPath == "" {
+		return nil, errors.New("tls certificate and key required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		InsecureSkipVerify: skipVerify,
+	}
+
+	// Verify peers against a certificate authority, if provided.
+	if caPath != "" {
+		pool, err := readCertPool(caPath)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// NewClientTLSConfig returns a TLS configuration for clients which verifies
+// servers against the certificate authority at caPath, if set. The
+// certificate & key are optional and are only presented to servers which
+// request a client certificate.
+func NewClientTLSConfig(certPath, keyPath, caPath string, skipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: skipVerify}
+
+	if certPath != "" || keyPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if caPath != "" {
+		pool, err := readCertPool(caPath)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// readCertPool returns a certificate pool containing the PEM encoded
+// certificates in the file at path.
+func readCertPool(path string) (*x509.CertPool, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(buf) {
+		return nil, errors.New("invalid tls ca certificate")
+	}
+	return pool, nil
+}
+
+// NewTLSHTTPClient returns an HTTP client which uses config for connections.
+// The client presents the config's certificates to servers which request them.
+func NewTLSHTTPClient(config *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: config,
+		},
+	}
+}
+
+// isInternalPath returns true if path is an endpoint used only for
+// communication between nodes. Remote queries are checked separately since
+// they share the query endpoint with clients.
+func isInternalPath(path string) bool {
+	switch path {
+	case "/fragment/blocks", "/fragment/block/data":
+		return true
+	}
+
+	// Attribute diffs are only requested by the anti-entropy syncer.
+	return strings.HasPrefix(path, "/index/") && strings.HasSuffix(path, "/attr/diff")
+}
+
+// hasVerifiedClientCert returns true if the request was made over TLS with a
+// client certificate verified against the server's certificate authority.
+func hasVerifiedClientCert(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
@@ -0,0 +1,232 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pilosa/pilosa"
+	"github.com/pilosa/pilosa/internal"
+)
+
+// Ensure internal endpoints require a client certificate when using mutual TLS.
+func TestHandler_MutualTLS(t *testing.T) {
+	certs := MustGenerateCerts()
+	defer certs.Close()
+
+	config, err := pilosa.NewTLSConfig(certs.CertPath, certs.KeyPath, certs.CAPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	h := NewHandler()
+	h.Holder = hldr.Holder
+	h.Cluster = NewCluster(1)
+	h.MutualTLS = true
+	s := httptest.NewUnstartedServer(h)
+	s.TLS = config
+	s.StartTLS()
+	defer s.Close()
+
+	// A client that only trusts the CA can access public endpoints.
+	anon := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: config.RootCAs}}}
+	if resp, err := anon.Get(s.URL + "/version"); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Endpoints used by the CLI are also public.
+	if resp, err := anon.Get(s.URL + "/fragment/nodes?index=i&slice=0"); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Internal endpoints are forbidden without a client certificate.
+	if resp, err := anon.Get(s.URL + "/fragment/blocks?index=i&frame=f&view=standard&slice=0"); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Remote queries are forbidden without a client certificate.
+	buf, err := proto.Marshal(&internal.QueryRequest{Query: `Count(Bitmap(rowID=1))`, Remote: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := anon.Post(s.URL+"/index/i/query", "application/x-protobuf", bytes.NewReader(buf)); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Other nodes present their certificate and are allowed.
+	node := pilosa.NewTLSHTTPClient(config)
+	if resp, err := node.Get(s.URL + "/fragment/blocks?index=i&frame=f&view=standard&slice=0"); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode == http.StatusForbidden {
+		t.Fatalf("unexpected status code: %d", resp.StatusCode)
+	}
+}
+
+// Ensure a client configured for TLS uses HTTPS for all requests.
+func TestClient_TLS(t *testing.T) {
+	certs := MustGenerateCerts()
+	defer certs.Close()
+
+	config, err := pilosa.NewTLSConfig(certs.CertPath, certs.KeyPath, certs.CAPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler()
+	h.Cluster = NewCluster(1)
+	h.MutualTLS = true
+	s := httptest.NewUnstartedServer(h)
+	s.TLS = config
+	s.StartTLS()
+	defer s.Close()
+	h.Cluster.Nodes[0].Host = s.Listener.Addr().String()
+
+	// Build a client which only verifies the server.
+	clientConfig, err := pilosa.NewClientTLSConfig("", "", certs.CAPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := pilosa.NewClient(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HTTPClient = pilosa.NewTLSHTTPClient(clientConfig)
+
+	if nodes, err := c.FragmentNodes(context.Background(), "i", 0); err != nil {
+		t.Fatal(err)
+	} else if len(nodes) != 1 || nodes[0].Host != s.Listener.Addr().String() {
+		t.Fatalf("unexpected nodes: %+v", nodes)
+	}
+}
+
+// Ensure a TLS config cannot be created without a certificate.
+func TestNewTLSConfig_ErrCertificateRequired(t *testing.T) {
+	if _, err := pilosa.NewTLSConfig("", "", "", false); err == nil || err.Error() != "tls certificate and key required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Certs represents a temporary CA and node certificate on disk.
+type Certs struct {
+	Dir      string
+	CAPath   string
+	CertPath string
+	KeyPath  string
+}
+
+// MustGenerateCerts generates a CA and a node certificate signed by it for
+// 127.0.0.1 which can be used for both serving and client authentication.
+func MustGenerateCerts() *Certs {
+	dir, err := ioutil.TempDir("", "pilosa-tls-")
+	if err != nil {
+		panic(err)
+	}
+	c := &Certs{
+		Dir:      dir,
+		CAPath:   filepath.Join(dir, "ca.crt"),
+		CertPath: filepath.Join(dir, "node.crt"),
+		KeyPath:  filepath.Join(dir, "node.key"),
+	}
+
+	// Generate self-signed CA.
+	caKey := MustGenerateKey()
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pilosa-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		panic(err)
+	}
+	MustWritePEM(c.CAPath, "CERTIFICATE", caDER)
+
+	// Generate node certificate signed by the CA.
+	key := MustGenerateKey()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "pilosa-test-node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caTmpl, &key.PublicKey, caKey)
+	if err != nil {
+		panic(err)
+	}
+	MustWritePEM(c.CertPath, "CERTIFICATE", der)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+	MustWritePEM(c.KeyPath, "EC PRIVATE KEY", keyDER)
+
+	return c
+}
+
+// Close removes the certificate files.
+func (c *Certs) Close() error { return os.RemoveAll(c.Dir) }
+
+// MustGenerateKey generates an ECDSA private key. Panic on error.
+func MustGenerateKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// MustWritePEM writes a PEM encoded block to path. Panic on error.
+func MustWritePEM(path, typ string, der []byte) {
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		panic(err)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"sync"
 
 	"github.com/pilosa/pilosa/internal"
+	"golang.org/x/sync/errgroup"
 )
 
 // View layout modes.
@@ -48,6 +49,15 @@ type View struct {
 
 	cacheSize uint32
 
+	// Number of column IDs in a slice. Set by the index.
+	sliceWidth uint64
+
+	// Storage backend used by fragments. Set by the index.
+	storageBackend string
+
+	// Keeps at most one row set per column. Set by the frame.
+	mutex bool
+
 	// Fragments by slice.
 	cacheType string // passed in by frame
 	fragments map[uint64]*Fragment
@@ -58,6 +68,20 @@ type View struct {
 
 	broadcaster Broadcaster
 	stats       StatsClient
+	durability  Durability
+
+	// Limits concurrent background snapshots. Shared by the holder.
+	snapshotQueue chan struct{}
+
+	// Tracks open fragments for eviction. Shared by the holder.
+	fragmentPool *fragmentPool
+
+	// Row cache limits. The budget is shared by the holder.
+	rowCacheBudget   *bitmapCacheBudget
+	rowCacheMaxBytes int64
+
+	// Number of fragments opened in parallel. Set by the frame.
+	openConcurrency int
 
 	RowAttrStore *AttrStore
 	LogOutput    io.Writer
@@ -75,6 +99,9 @@ func NewView(path, index, frame, name string, cacheSize uint32) *View {
 		cacheType: DefaultCacheType,
 		fragments: make(map[uint64]*Fragment),
 
+		sliceWidth:     SliceWidth,
+		storageBackend: DefaultStorageBackend,
+
 		broadcaster: NopBroadcaster,
 		stats:       NopStatsClient,
 		LogOutput:   ioutil.Discard,
@@ -116,7 +143,7 @@ func (v *View) Open() error {
 	return nil
 }
 
-// openFragments opens and initializes the fragments inside the view.
+// openFragments initializes the fragments inside the view.
 func (v *View) openFragments() error {
 	file, err := os.Open(filepath.Join(v.path, "fragments"))
 	if os.IsNotExist(err) {
@@ -131,6 +158,10 @@ func (v *View) openFragments() error {
 		return err
 	}
 
+	// Initialize fragments in parallel.
+	var mu sync.Mutex
+	var g errgroup.Group
+	sem := newOpenSemaphore(v.openConcurrency)
 	for _, fi := range fis {
 		if fi.IsDir() {
 			continue
@@ -142,17 +173,22 @@ func (v *View) openFragments() error {
 			continue
 		}
 
-		frag := v.newFragment(v.FragmentPath(slice), slice)
-		if err := frag.Open(); err != nil {
-			return fmt.Errorf("open fragment: slice=%s, err=%s", frag.Slice(), err)
-		}
-		frag.RowAttrStore = v.RowAttrStore
-		v.fragments[frag.Slice()] = frag
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-		v.stats.Count("maxSlice", 1)
-	}
+			// Fragments are opened lazily on first access.
+			frag := v.newFragment(v.FragmentPath(slice), slice)
+			frag.RowAttrStore = v.RowAttrStore
+			mu.Lock()
+			v.fragments[frag.Slice()] = frag
+			mu.Unlock()
 
-	return nil
+			v.stats.Count("maxSlice", 1)
+			return nil
+		})
+	}
+	return g.Wait()
 }
 
 // Close closes the view and its fragments.
@@ -257,24 +293,63 @@ func (v *View) newFragment(path string, slice uint64) *Fragment {
 	frag := NewFragment(path, v.index, v.frame, v.name, slice)
 	frag.cacheType = v.cacheType
 	frag.cacheSize = v.cacheSize
+	frag.sliceWidth = v.sliceWidth
 	frag.LogOutput = v.LogOutput
 	frag.stats = v.stats.WithTags(fmt.Sprintf("slice:%d", slice))
+	frag.Durability = v.durability
+	frag.snapshotQueue = v.snapshotQueue
+	frag.pool = v.fragmentPool
+	frag.rowCacheBudget = v.rowCacheBudget
+	frag.RowCacheMaxBytes = v.rowCacheMaxBytes
+	frag.mutex = v.mutex
+	if v.storageBackend == StorageBackendMemory {
+		frag.Backend = NewMemoryBackend()
+	}
 	return frag
 }
 
 // SetBit sets a bit within the view.
 func (v *View) SetBit(rowID, columnID uint64) (changed bool, err error) {
-	slice := columnID / SliceWidth
+	slice := columnID / v.sliceWidth
 	frag, err := v.CreateFragmentIfNotExists(slice)
 	if err != nil {
 		return changed, err
 	}
-	return frag.SetBit(rowID, columnID)
+	if changed, err = frag.SetBit(rowID, columnID); err != nil {
+		return changed, err
+	}
+
+	// Clear the row from the other slices of a mutex inverse view.
+	if v.mutex && IsInverseView(v.name) {
+		if cleared, err := v.clearMutexRow(rowID, slice); err != nil {
+			return changed, err
+		} else if cleared {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// clearMutexRow clears rowID in every fragment of the view except the
+// fragment for slice. Inverse views are transposed so the rows set for a
+// column can be spread across the view's slices.
+func (v *View) clearMutexRow(rowID, slice uint64) (changed bool, err error) {
+	for _, frag := range v.Fragments() {
+		if frag.Slice() == slice {
+			continue
+		}
+		if c, err := frag.SetRow(rowID, NewBitmap()); err != nil {
+			return changed, err
+		} else if c {
+			changed = true
+		}
+	}
+	return changed, nil
 }
 
 // ClearBit clears a bit within the view.
 func (v *View) ClearBit(rowID, columnID uint64) (changed bool, err error) {
-	slice := columnID / SliceWidth
+	slice := columnID / v.sliceWidth
 	frag, err := v.CreateFragmentIfNotExists(slice)
 	if err != nil {
 		return changed, err